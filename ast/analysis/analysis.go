@@ -0,0 +1,224 @@
+// Package analysis computes the classic analyses of a PEG grammar:
+// nullability, FIRST sets and FOLLOW sets, for each rule and for each
+// expression of the grammar.
+//
+// In a PEG, an expression is nullable if it can match without consuming
+// any input. Its FIRST set is the set of terminals that it can consume
+// first, and its FOLLOW set is the set of terminals that can be consumed
+// right after it. Predicates and code blocks never consume input, so they
+// are nullable and contribute nothing to FIRST sets.
+package analysis
+
+import (
+	"github.com/mna/pigeon/ast"
+)
+
+// Analysis is the result of the analysis of a grammar.
+type Analysis struct {
+	rules      map[string]*ast.Rule
+	nullables  map[string]bool
+	first      map[string]*Set
+	follow     map[string]*Set
+	exprFollow map[ast.Expression]*Set
+}
+
+// anyInput is the FOLLOW set of the expressions of predicates, which look
+// at the input without consuming it, so that anything may follow them.
+var anyInput = func() *Set {
+	var s Set
+	s.add(ast.NewAnyMatcher(ast.Pos{}, "."))
+	s.addEOF()
+	return &s
+}()
+
+// New analyzes the grammar. The end of the input follows the first rule of
+// the grammar and the alternate entrypoints, if any.
+func New(g *ast.Grammar, alternateEntrypoints ...string) *Analysis {
+	a := &Analysis{
+		rules:      make(map[string]*ast.Rule, len(g.Rules)),
+		nullables:  ast.NullableRules(g),
+		first:      make(map[string]*Set, len(g.Rules)),
+		follow:     make(map[string]*Set, len(g.Rules)),
+		exprFollow: make(map[ast.Expression]*Set),
+	}
+	for _, r := range g.Rules {
+		if _, ok := a.rules[r.Name.Val]; !ok {
+			a.rules[r.Name.Val] = r
+		}
+		a.first[r.Name.Val] = &Set{}
+		a.follow[r.Name.Val] = &Set{}
+	}
+
+	// FIRST sets of the rules
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			changed = a.first[r.Name.Val].union(a.First(r.Expr)) || changed
+		}
+	}
+
+	// FOLLOW sets of the rules and expressions
+	var entrypoints []string
+	if len(g.Rules) > 0 {
+		entrypoints = append([]string{g.Rules[0].Name.Val}, alternateEntrypoints...)
+	}
+	for _, nm := range entrypoints {
+		if s := a.follow[nm]; s != nil {
+			s.addEOF()
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			changed = a.followExpr(r.Expr, a.follow[r.Name.Val]) || changed
+		}
+	}
+	return a
+}
+
+// RuleNullable returns true if the rule named nm can match without
+// consuming any input.
+func (a *Analysis) RuleNullable(nm string) bool {
+	return a.nullables[nm]
+}
+
+// Nullable returns true if expr can match without consuming any input.
+func (a *Analysis) Nullable(expr ast.Expression) bool {
+	if r, ok := expr.(*ast.Rule); ok {
+		return a.nullables[r.Name.Val]
+	}
+	return ast.Nullable(expr, a.nullables)
+}
+
+// RuleFirst returns the FIRST set of the rule named nm, or nil if there is
+// no such rule.
+func (a *Analysis) RuleFirst(nm string) *Set {
+	return a.first[nm]
+}
+
+// First returns the FIRST set of expr, the terminals it can consume first.
+func (a *Analysis) First(expr ast.Expression) *Set {
+	var s Set
+	a.firstExpr(expr, &s)
+	return &s
+}
+
+func (a *Analysis) firstExpr(expr ast.Expression, s *Set) {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		a.firstExpr(expr.Expr, s)
+	case *ast.AnyMatcher:
+		s.add(expr)
+	case *ast.CharClassMatcher:
+		s.add(expr)
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			a.firstExpr(alt, s)
+		}
+	case *ast.LabeledExpr:
+		a.firstExpr(expr.Expr, s)
+	case *ast.LitMatcher:
+		if expr.Val != "" {
+			s.add(expr)
+		}
+	case *ast.OneOrMoreExpr:
+		a.firstExpr(expr.Expr, s)
+	case *ast.RecoveryExpr:
+		a.firstExpr(expr.Expr, s)
+		a.firstExpr(expr.RecoverExpr, s)
+	case *ast.Rule:
+		s.union(a.first[expr.Name.Val])
+	case *ast.RuleRefExpr:
+		s.union(a.first[expr.Name.Val])
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			a.firstExpr(e, s)
+			if !a.Nullable(e) {
+				break
+			}
+		}
+	case *ast.ZeroOrMoreExpr:
+		a.firstExpr(expr.Expr, s)
+	case *ast.ZeroOrOneExpr:
+		a.firstExpr(expr.Expr, s)
+	}
+}
+
+// RuleFollow returns the FOLLOW set of the rule named nm, or nil if there
+// is no such rule.
+func (a *Analysis) RuleFollow(nm string) *Set {
+	return a.follow[nm]
+}
+
+// Follow returns the FOLLOW set of expr, the terminals that can be
+// consumed right after it. The expression must be part of the analyzed
+// grammar, otherwise nil is returned.
+func (a *Analysis) Follow(expr ast.Expression) *Set {
+	if r, ok := expr.(*ast.Rule); ok {
+		return a.follow[r.Name.Val]
+	}
+	return a.exprFollow[expr]
+}
+
+// followExpr adds follow to the FOLLOW set of expr and propagates it to
+// its sub-expressions and to the rules it references. It returns true if
+// any FOLLOW set changed.
+func (a *Analysis) followExpr(expr ast.Expression, follow *Set) bool {
+	s := a.exprFollow[expr]
+	if s == nil {
+		s = &Set{}
+		a.exprFollow[expr] = s
+	}
+	changed := s.union(follow)
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		changed = a.followExpr(expr.Expr, follow) || changed
+	case *ast.AndExpr:
+		changed = a.followExpr(expr.Expr, anyInput) || changed
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			changed = a.followExpr(alt, follow) || changed
+		}
+	case *ast.LabeledExpr:
+		changed = a.followExpr(expr.Expr, follow) || changed
+	case *ast.NotExpr:
+		changed = a.followExpr(expr.Expr, anyInput) || changed
+	case *ast.OneOrMoreExpr:
+		changed = a.followExpr(expr.Expr, a.repeatFollow(expr.Expr, follow)) || changed
+	case *ast.RecoveryExpr:
+		changed = a.followExpr(expr.Expr, follow) || changed
+		changed = a.followExpr(expr.RecoverExpr, follow) || changed
+	case *ast.RuleRefExpr:
+		if rs := a.follow[expr.Name.Val]; rs != nil {
+			changed = rs.union(follow) || changed
+		}
+	case *ast.SeqExpr:
+		// the FOLLOW set of each expression is the FIRST set of the next
+		// one, plus the FOLLOW set of the next one if it is nullable.
+		next := follow
+		for i := len(expr.Exprs) - 1; i >= 0; i-- {
+			e := expr.Exprs[i]
+			changed = a.followExpr(e, next) || changed
+
+			cur := a.First(e)
+			if a.Nullable(e) {
+				cur.union(next)
+			}
+			next = cur
+		}
+	case *ast.ZeroOrMoreExpr:
+		changed = a.followExpr(expr.Expr, a.repeatFollow(expr.Expr, follow)) || changed
+	case *ast.ZeroOrOneExpr:
+		changed = a.followExpr(expr.Expr, follow) || changed
+	}
+	return changed
+}
+
+// repeatFollow returns the FOLLOW set of the expression of a repetition,
+// which may be followed by itself or by what follows the repetition.
+func (a *Analysis) repeatFollow(expr ast.Expression, follow *Set) *Set {
+	s := a.First(expr)
+	s.union(follow)
+	return s
+}
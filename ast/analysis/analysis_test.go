@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+// testGrammar returns the grammar:
+//
+//	Start = List !.
+//	List = Item ( "," _ Item )* _
+//	Item = Word / Number / "(" List ")" / &"!" Bang
+//	Word = [\pL_] [\pL\pNd_]*
+//	Number = "0x"i [0-9a-f]i+ / [0-9]+
+//	Bang = "!" .
+//	_ = [ \t]*
+func testGrammar() *ast.Grammar {
+	asttest.Reset()
+	return asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("List"), asttest.Not(asttest.Any()))),
+		asttest.Rule("List", asttest.Seq(
+			asttest.Ref("Item"),
+			asttest.Star(asttest.Seq(asttest.Lit(","), asttest.Ref("_"), asttest.Ref("Item"))),
+			asttest.Ref("_"),
+		)),
+		asttest.Rule("Item", asttest.Choice(
+			asttest.Ref("Word"),
+			asttest.Ref("Number"),
+			asttest.Seq(asttest.Lit("("), asttest.Ref("List"), asttest.Lit(")")),
+			asttest.Seq(asttest.And(asttest.Lit("!")), asttest.Ref("Bang")),
+		)),
+		asttest.Rule("Word", asttest.Seq(asttest.Class(`[\pL_]`), asttest.Star(asttest.Class(`[\pL\pNd_]`)))),
+		asttest.Rule("Number", asttest.Choice(
+			asttest.Seq(asttest.LitI("0x"), asttest.Plus(asttest.Class("[0-9a-f]i"))),
+			asttest.Plus(asttest.Class("[0-9]")),
+		)),
+		asttest.Rule("Bang", asttest.Seq(asttest.Lit("!"), asttest.Any())),
+		asttest.Rule("_", asttest.Star(asttest.Class(`[ \t]`))),
+	)
+}
+
+func TestRules(t *testing.T) {
+	a := New(testGrammar())
+
+	cases := []struct {
+		rule     string
+		nullable bool
+		first    string
+		follow   string
+	}{
+		{"Start", false, `{"(", [\pL_], "0x"i, [0-9], "!"}`, `{EOF}`},
+		{"List", false, `{"(", [\pL_], "0x"i, [0-9], "!"}`, `{")", EOF}`},
+		{"Item", false, `{"(", [\pL_], "0x"i, [0-9], "!"}`, `{",", [ \t], ")", EOF}`},
+		{"Word", false, `{[\pL_]}`, `{",", [ \t], ")", EOF}`},
+		{"Number", false, `{"0x"i, [0-9]}`, `{",", [ \t], ")", EOF}`},
+		{"Bang", false, `{"!"}`, `{",", [ \t], ")", EOF}`},
+		{"_", true, `{[ \t]}`, `{"(", [\pL_], "0x"i, [0-9], "!", ")", EOF}`},
+	}
+	for _, c := range cases {
+		if got := a.RuleNullable(c.rule); got != c.nullable {
+			t.Errorf("%s: want nullable %t, got %t", c.rule, c.nullable, got)
+		}
+		if got := a.RuleFirst(c.rule).String(); got != c.first {
+			t.Errorf("%s: want FIRST %s, got %s", c.rule, c.first, got)
+		}
+		if got := a.RuleFollow(c.rule).String(); got != c.follow {
+			t.Errorf("%s: want FOLLOW %s, got %s", c.rule, c.follow, got)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	// A = "a" B? "c"
+	// B = x:"b"+ { return x, nil }
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("A", asttest.Seq(asttest.Lit("a"), asttest.Opt(asttest.Ref("B")), asttest.Lit("c"))),
+		asttest.Rule("B", asttest.Action(asttest.Label("x", asttest.Plus(asttest.Lit("b"))), "{ return x, nil }")),
+	)
+	a := New(g, "B")
+
+	seq := g.Rules[0].Expr.(*ast.SeqExpr)
+	opt := seq.Exprs[1]
+	if !a.Nullable(opt) {
+		t.Errorf("want B? to be nullable")
+	}
+	if got, want := a.First(seq).String(), `{"a"}`; got != want {
+		t.Errorf("want FIRST %s, got %s", want, got)
+	}
+	if got, want := a.Follow(opt).String(), `{"c"}`; got != want {
+		t.Errorf("want FOLLOW %s, got %s", want, got)
+	}
+
+	// B is an alternate entrypoint, and "b"+ may be followed by itself
+	plus := g.Rules[1].Expr.(*ast.ActionExpr).Expr.(*ast.LabeledExpr).Expr.(*ast.OneOrMoreExpr)
+	if got, want := a.Follow(plus.Expr).String(), `{"b", "c", EOF}`; got != want {
+		t.Errorf("want FOLLOW %s, got %s", want, got)
+	}
+	if a.Follow(ast.NewLitMatcher(ast.Pos{}, "x")) != nil {
+		t.Errorf("want no FOLLOW set for an expression not in the grammar")
+	}
+}
+
+func TestRuleParams(t *testing.T) {
+	// Start = Sep<Word, ","> !.
+	// Sep<X, S> = X ( S X )*
+	// Word = [a-z]+
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("Sep", asttest.Ref("Word"), asttest.Lit(",")), asttest.Not(asttest.Any()))),
+		asttest.ParamRule("Sep", asttest.Seq(
+			asttest.Ref("X"),
+			asttest.Star(asttest.Seq(asttest.Ref("S"), asttest.Ref("X"))),
+		), "X", "S"),
+		asttest.Rule("Word", asttest.Plus(asttest.Class("[a-z]"))),
+	)
+	if err := ast.ExpandRuleParams(g); err != nil {
+		t.Fatal(err)
+	}
+	a := New(g)
+
+	if got, want := a.RuleFirst("Start").String(), `{[a-z]}`; got != want {
+		t.Errorf("want FIRST %s, got %s", want, got)
+	}
+	if got, want := a.RuleFollow("Word").String(), `{",", EOF}`; got != want {
+		t.Errorf("want FOLLOW %s, got %s", want, got)
+	}
+}
+
+func TestSetContainsRune(t *testing.T) {
+	a := New(testGrammar())
+	first := a.RuleFirst("Item")
+
+	for _, rn := range "aZ_é0(!" {
+		if !first.ContainsRune(rn) {
+			t.Errorf("want %q in %s", rn, first)
+		}
+	}
+	for _, rn := range "), " {
+		if first.ContainsRune(rn) {
+			t.Errorf("want %q not in %s", rn, first)
+		}
+	}
+	if !a.RuleFirst("Number").ContainsRune('0') {
+		t.Errorf("want '0' in FIRST of Number")
+	}
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// Set is a set of terminals, as found in FIRST and FOLLOW sets. The
+// terminals are the matchers of the grammar: a literal matcher stands for
+// its value as a literal prefix, a character class matcher for the runes it
+// matches, including its Unicode classes, and the any matcher for any rune.
+// The set may also contain the end of the input, in FOLLOW sets only.
+//
+// The zero value is an empty set ready to use.
+type Set struct {
+	terms []ast.Expression
+	keys  map[string]bool
+	eof   bool
+}
+
+// Terminals returns the terminals of the set, in the order they were
+// added. Each terminal is either a *ast.LitMatcher, a *ast.CharClassMatcher
+// or a *ast.AnyMatcher.
+func (s *Set) Terminals() []ast.Expression {
+	return s.terms
+}
+
+// EOF returns true if the set contains the end of the input.
+func (s *Set) EOF() bool {
+	return s.eof
+}
+
+// Empty returns true if the set has no terminal and does not contain the
+// end of the input.
+func (s *Set) Empty() bool {
+	return len(s.terms) == 0 && !s.eof
+}
+
+// ContainsRune returns true if a terminal of the set can start with rn.
+func (s *Set) ContainsRune(rn rune) bool {
+	for _, term := range s.terms {
+		switch term := term.(type) {
+		case *ast.AnyMatcher:
+			return true
+		case *ast.CharClassMatcher:
+			if term.MatchesRune(rn) {
+				return true
+			}
+		case *ast.LitMatcher:
+			first, _ := utf8.DecodeRuneInString(term.Val)
+			if first == rn || (term.IgnoreCase && unicode.ToLower(first) == unicode.ToLower(rn)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the textual representation of the set, e.g.
+// {"a", [0-9], ., EOF}.
+func (s *Set) String() string {
+	var buf bytes.Buffer
+	buf.WriteRune('{')
+	for i, term := range s.terms {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(termKey(term))
+	}
+	if s.eof {
+		if len(s.terms) > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("EOF")
+	}
+	buf.WriteRune('}')
+	return buf.String()
+}
+
+// add adds term to the set, it returns true if the set changed.
+func (s *Set) add(term ast.Expression) bool {
+	key := termKey(term)
+	if s.keys[key] {
+		return false
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]bool)
+	}
+	s.keys[key] = true
+	s.terms = append(s.terms, term)
+	return true
+}
+
+// addEOF adds the end of the input to the set, it returns true if the set
+// changed.
+func (s *Set) addEOF() bool {
+	if s.eof {
+		return false
+	}
+	s.eof = true
+	return true
+}
+
+// union adds the terminals of other to the set, it returns true if the set
+// changed.
+func (s *Set) union(other *Set) bool {
+	if other == nil {
+		return false
+	}
+	changed := false
+	for _, term := range other.terms {
+		changed = s.add(term) || changed
+	}
+	if other.eof {
+		changed = s.addEOF() || changed
+	}
+	return changed
+}
+
+// termKey returns the key identifying a terminal in a set, which is also
+// its textual representation.
+func termKey(term ast.Expression) string {
+	switch term := term.(type) {
+	case *ast.AnyMatcher:
+		return "."
+	case *ast.CharClassMatcher:
+		return term.Val
+	case *ast.LitMatcher:
+		key := strconv.Quote(term.Val)
+		if term.IgnoreCase {
+			key = strconv.Quote(strings.ToLower(term.Val)) + "i"
+		}
+		return key
+	}
+	return ""
+}
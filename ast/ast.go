@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Pos represents a position in a source file.
@@ -30,9 +31,15 @@ func (p Pos) String() string {
 
 // Grammar is the top-level node of the AST for the PEG grammar.
 type Grammar struct {
-	p     Pos
-	Init  *CodeBlock
-	Rules []*Rule
+	p          Pos
+	Init       *CodeBlock
+	Directives []*Directive
+	Rules      []*Rule
+
+	// ImportedInits are the initializers of the grammars imported by the
+	// grammar, in import order. They are merged with Init in the generated
+	// code.
+	ImportedInits []*CodeBlock
 }
 
 // NewGrammar creates a new grammar at the specified position.
@@ -56,13 +63,45 @@ func (g *Grammar) String() string {
 	return buf.String()
 }
 
-// Rule represents a rule in the PEG grammar. It has a name, an optional
-// display name to be used in error messages, and an expression.
+// Directive represents a directive in the PEG grammar, e.g.
+// @option receiver-name self. It has a name and a list of arguments, the
+// arguments written as string literals being unquoted.
+type Directive struct {
+	p    Pos
+	Name *Identifier
+	Args []string
+}
+
+// NewDirective creates a directive at the specified position and with the
+// specified name as identifier.
+func NewDirective(p Pos, name *Identifier) *Directive {
+	return &Directive{p: p, Name: name}
+}
+
+// Pos returns the starting position of the node.
+func (d *Directive) Pos() Pos { return d.p }
+
+// String returns the textual representation of a node.
+func (d *Directive) String() string {
+	return fmt.Sprintf("%s: %T{Name: %v, Args: %q}", d.p, d, d.Name, d.Args)
+}
+
+// Rule represents a rule in the PEG grammar. It has a name, optional
+// parameters, an optional display name to be used in error messages, an
+// optional Go type for the value it returns, and an expression.
 type Rule struct {
 	p           Pos
 	Name        *Identifier
+	Params      []*Identifier
 	DisplayName *StringLit
+	Type        string
 	Expr        Expression
+
+	// LeftRecursive and Leader are set by MarkLeftRecursion. LeftRecursive
+	// is true if the rule is part of a left-recursive cycle, Leader is true
+	// if the rule is the one that grows the result of its cycles.
+	LeftRecursive bool
+	Leader        bool
 }
 
 // NewRule creates a rule with at the specified position and with the
@@ -76,6 +115,10 @@ func (r *Rule) Pos() Pos { return r.p }
 
 // String returns the textual representation of a node.
 func (r *Rule) String() string {
+	if len(r.Params) > 0 {
+		return fmt.Sprintf("%s: %T{Name: %v, Params: %v, DisplayName: %v, Expr: %v}",
+			r.p, r, r.Name, r.Params, r.DisplayName, r.Expr)
+	}
 	return fmt.Sprintf("%s: %T{Name: %v, DisplayName: %v, Expr: %v}",
 		r.p, r, r.Name, r.DisplayName, r.Expr)
 }
@@ -338,10 +381,13 @@ func (o *OneOrMoreExpr) String() string {
 	return fmt.Sprintf("%s: %T{Expr: %v}", o.p, o, o.Expr)
 }
 
-// RuleRefExpr is an expression that references a rule by name.
+// RuleRefExpr is an expression that references a rule by name. The
+// references to parameterized rules have arguments, until the rules are
+// expanded by ExpandRuleParams.
 type RuleRefExpr struct {
 	p    Pos
 	Name *Identifier
+	Args []Expression
 }
 
 // NewRuleRefExpr creates a new rule reference expression at the specified
@@ -355,6 +401,9 @@ func (r *RuleRefExpr) Pos() Pos { return r.p }
 
 // String returns the textual representation of a node.
 func (r *RuleRefExpr) String() string {
+	if len(r.Args) > 0 {
+		return fmt.Sprintf("%s: %T{Name: %v, Args: %v}", r.p, r, r.Name, r.Args)
+	}
 	return fmt.Sprintf("%s: %T{Name: %v}", r.p, r, r.Name)
 }
 
@@ -580,6 +629,50 @@ func (c *CharClassMatcher) String() string {
 		c.p, c, c.Val, c.IgnoreCase, c.Inverted)
 }
 
+// MatchesRune returns true if the character class matches rn, the same way
+// the generated parser would.
+func (c *CharClassMatcher) MatchesRune(rn rune) bool {
+	if c.IgnoreCase {
+		rn = unicode.ToLower(rn)
+	}
+
+	matched := false
+	for _, ch := range c.Chars {
+		if c.IgnoreCase {
+			ch = unicode.ToLower(ch)
+		}
+		matched = matched || ch == rn
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		lo, hi := c.Ranges[i], c.Ranges[i+1]
+		if c.IgnoreCase {
+			lo, hi = unicode.ToLower(lo), unicode.ToLower(hi)
+		}
+		matched = matched || (rn >= lo && rn <= hi)
+	}
+	for _, cl := range c.UnicodeClasses {
+		rt := UnicodeClass(cl)
+		matched = matched || (rt != nil && unicode.Is(rt, rn))
+	}
+	return matched != c.Inverted
+}
+
+// UnicodeClass returns the range table of the Unicode class, which is
+// either a category, a property or a script, the same way the generated
+// parser would. It returns nil if there is no such class.
+func UnicodeClass(class string) *unicode.RangeTable {
+	if rt, ok := unicode.Categories[class]; ok {
+		return rt
+	}
+	if rt, ok := unicode.Properties[class]; ok {
+		return rt
+	}
+	if rt, ok := unicode.Scripts[class]; ok {
+		return rt
+	}
+	return nil
+}
+
 // AnyMatcher is a matcher that matches any character except end-of-file.
 type AnyMatcher struct {
 	posValue
@@ -0,0 +1,72 @@
+package ast
+
+// CheckDuplicates reports every rule of the grammar that is defined more
+// than once, and every label that is defined more than once in the same
+// scope. The generated parser would otherwise silently keep the last
+// definition of the rule or the value of the last labeled expression.
+//
+// The labels of a rule's expression are in the same scope unless they are
+// separated by a choice, a labeled expression, a predicate, a recovery or
+// a repetition, all of which start a new scope for their sub-expressions.
+// The recover expression of a recovery has its own scope, as it is parsed
+// where the failure label is thrown.
+//
+// It returns nil if no duplicate is found, otherwise the returned error is
+// of type ValidationErrors, each error being reported at the position of
+// the duplicate definition.
+func CheckDuplicates(g *Grammar) error {
+	var errs ValidationErrors
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		if prev, ok := rules[r.Name.Val]; ok {
+			errs.add(CodeDuplicateRule, r.Pos(), "rule %s already defined at %s", r.Name.Val, prev.Pos())
+		} else {
+			rules[r.Name.Val] = r
+		}
+		checkDuplicateLabels(r.Expr, make(map[string]*LabeledExpr), &errs)
+	}
+	return errs.err()
+}
+
+// checkDuplicateLabels adds an error to errs for each label of expr that is
+// already defined in labels, the labels of the current scope.
+func checkDuplicateLabels(expr Expression, labels map[string]*LabeledExpr, errs *ValidationErrors) {
+	newScope := func(expr Expression) {
+		checkDuplicateLabels(expr, make(map[string]*LabeledExpr), errs)
+	}
+
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		checkDuplicateLabels(expr.Expr, labels, errs)
+	case *AndExpr:
+		newScope(expr.Expr)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			newScope(alt)
+		}
+	case *LabeledExpr:
+		if expr.Label != nil && expr.Label.Val != "" {
+			if prev, ok := labels[expr.Label.Val]; ok {
+				errs.add(CodeDuplicateLabel, expr.Pos(), "label %s already defined at %s", expr.Label.Val, prev.Pos())
+			} else {
+				labels[expr.Label.Val] = expr
+			}
+		}
+		newScope(expr.Expr)
+	case *NotExpr:
+		newScope(expr.Expr)
+	case *OneOrMoreExpr:
+		newScope(expr.Expr)
+	case *RecoveryExpr:
+		newScope(expr.Expr)
+		newScope(expr.RecoverExpr)
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			checkDuplicateLabels(e, labels, errs)
+		}
+	case *ZeroOrMoreExpr:
+		newScope(expr.Expr)
+	case *ZeroOrOneExpr:
+		newScope(expr.Expr)
+	}
+}
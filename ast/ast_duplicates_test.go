@@ -0,0 +1,88 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestCheckDuplicates(t *testing.T) {
+	cases := []struct {
+		g    func() *ast.Grammar
+		want []string
+	}{
+		{
+			// same labels in different scopes
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(
+						asttest.Seq(asttest.Label("a", asttest.Lit("a")), asttest.Label("b", asttest.Lit("b"))),
+						asttest.Seq(asttest.Label("a", asttest.Lit("a")), asttest.Star(asttest.Label("a", asttest.Lit("a")))),
+					)),
+					asttest.Rule("B", asttest.Label("a", asttest.Label("a", asttest.Lit("a")))),
+					asttest.Rule("C", asttest.Seq(
+						asttest.Label("a", asttest.Lit("a")),
+						asttest.Recover(asttest.Label("a", asttest.Lit("b")), asttest.Label("a", asttest.Lit("c")), "l"),
+					)),
+				)
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Lit("a")),
+					asttest.Rule("B", asttest.Lit("b")),
+					asttest.Rule("A", asttest.Lit("c")),
+					asttest.Rule("A", asttest.Lit("d")),
+				)
+			},
+			want: []string{
+				"1:6 (5): rule A already defined at 1:2 (1)",
+				"1:8 (7): rule A already defined at 1:2 (1)",
+			},
+		},
+		{
+			// nested sequences share the scope of the enclosing sequence
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(
+						asttest.Label("a", asttest.Lit("a")),
+						asttest.Seq(asttest.Lit("x"), asttest.Label("a", asttest.Lit("b"))),
+						asttest.Label("a", asttest.Lit("c")),
+					)),
+				)
+			},
+			want: []string{
+				"1:5 (4): label a already defined at 1:2 (1)",
+				"1:8 (7): label a already defined at 1:2 (1)",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckDuplicates(tc.g())
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
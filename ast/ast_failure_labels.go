@@ -0,0 +1,154 @@
+package ast
+
+// CheckFailureLabels reports every throw expression whose label cannot be
+// recovered by any recovery expression that may be active when it is
+// evaluated, and every label of a recovery expression that no throw
+// expression can raise while it is active. Recovery expressions are active
+// for their own expression, for the rules it references and, transitively,
+// for the rules those reference, so the analysis follows rule references
+// from the first rule of the grammar and from the alternate entrypoints.
+// Rules that cannot be reached from those are not analyzed.
+//
+// It returns nil if no such throw or recovery expression is found,
+// otherwise the returned error is of type ValidationErrors.
+func CheckFailureLabels(g *Grammar, alternateEntrypoints ...string) error {
+	if len(g.Rules) == 0 {
+		return nil
+	}
+
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+	}
+
+	// the labels that may be recovered when each rule is invoked, only
+	// the rules that are invoked have an entry.
+	active := make(map[string]map[FailureLabel]bool)
+	for _, nm := range append([]string{g.Rules[0].Name.Val}, alternateEntrypoints...) {
+		if rules[nm] != nil {
+			active[nm] = make(map[FailureLabel]bool)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			labels, ok := active[r.Name.Val]
+			if !ok {
+				continue
+			}
+			walkRecoveryScopes(r.Expr, labels, func(expr Expression, labels map[FailureLabel]bool) {
+				ref, ok := expr.(*RuleRefExpr)
+				if !ok || rules[ref.Name.Val] == nil {
+					return
+				}
+				m := active[ref.Name.Val]
+				if m == nil {
+					m = make(map[FailureLabel]bool)
+					active[ref.Name.Val] = m
+					changed = true
+				}
+				for l := range labels {
+					if !m[l] {
+						m[l] = true
+						changed = true
+					}
+				}
+			})
+		}
+	}
+
+	// the labels that may be thrown by each rule, including by the rules
+	// it references.
+	thrown := make(map[string]map[FailureLabel]bool, len(g.Rules))
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			m := thrown[r.Name.Val]
+			if m == nil {
+				m = make(map[FailureLabel]bool)
+				thrown[r.Name.Val] = m
+			}
+			for l := range thrownLabels(r.Expr, thrown) {
+				if !m[l] {
+					m[l] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	var errs ValidationErrors
+	for _, r := range g.Rules {
+		labels, ok := active[r.Name.Val]
+		if !ok {
+			continue
+		}
+		walkRecoveryScopes(r.Expr, labels, func(expr Expression, labels map[FailureLabel]bool) {
+			switch expr := expr.(type) {
+			case *ThrowExpr:
+				if !labels[FailureLabel(expr.Label)] {
+					errs.add(CodeUnrecoveredLabel, expr.Pos(), "failure label %s is thrown but never recovered", expr.Label)
+				}
+			case *RecoveryExpr:
+				raised := thrownLabels(expr.Expr, thrown)
+				for _, l := range expr.Labels {
+					if !raised[l] {
+						errs.add(CodeUnthrownLabel, expr.Pos(), "failure label %s is recovered but never thrown", l)
+					}
+				}
+			}
+		})
+	}
+	return errs.err()
+}
+
+// thrownLabels returns the labels that may be thrown by expr, given the
+// labels thrown by each rule.
+func thrownLabels(expr Expression, thrown map[string]map[FailureLabel]bool) map[FailureLabel]bool {
+	labels := make(map[FailureLabel]bool)
+	Inspect(expr, func(expr Expression) bool {
+		switch expr := expr.(type) {
+		case *ThrowExpr:
+			labels[FailureLabel(expr.Label)] = true
+		case *RuleRefExpr:
+			for l := range thrown[expr.Name.Val] {
+				labels[l] = true
+			}
+		}
+		return true
+	})
+	return labels
+}
+
+// walkRecoveryScopes calls fn for each expression of expr with the labels
+// that may be recovered at that point, starting with labels. The labels of
+// a recovery expression are added for both its expression and its recovery
+// expression, as it is still active when its recovery expression runs.
+func walkRecoveryScopes(expr Expression, labels map[FailureLabel]bool, fn func(Expression, map[FailureLabel]bool)) {
+	Walk(&recoveryScope{labels: labels, fn: fn}, expr)
+}
+
+type recoveryScope struct {
+	labels map[FailureLabel]bool
+	fn     func(Expression, map[FailureLabel]bool)
+}
+
+func (s *recoveryScope) Visit(expr Expression) Visitor {
+	if expr == nil {
+		return nil
+	}
+	s.fn(expr, s.labels)
+
+	rec, ok := expr.(*RecoveryExpr)
+	if !ok {
+		return s
+	}
+	labels := make(map[FailureLabel]bool, len(s.labels)+len(rec.Labels))
+	for l := range s.labels {
+		labels[l] = true
+	}
+	for _, l := range rec.Labels {
+		labels[l] = true
+	}
+	return &recoveryScope{labels: labels, fn: s.fn}
+}
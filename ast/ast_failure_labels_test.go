@@ -0,0 +1,84 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestCheckFailureLabels(t *testing.T) {
+	cases := []struct {
+		g           func() *ast.Grammar
+		entrypoints []string
+		want        []string
+	}{
+		{
+			// throws recovered through rule references
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Recover(asttest.Ref("B"), asttest.Lit("x"), "e1", "e2")),
+					asttest.Rule("B", asttest.Choice(asttest.Lit("b"), asttest.Throw("e1"), asttest.Ref("C"))),
+					asttest.Rule("C", asttest.Throw("e2")),
+				)
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Recover(asttest.Ref("B"), asttest.Ref("R"), "e1", "e2"), asttest.Ref("C"))),
+					asttest.Rule("B", asttest.Throw("e1")),
+					asttest.Rule("C", asttest.Throw("e1")),
+					asttest.Rule("R", asttest.Throw("e3")),
+				)
+			},
+			want: []string{
+				"1:3 (2): failure label e2 is recovered but never thrown",
+				"1:9 (8): failure label e1 is thrown but never recovered",
+				"1:11 (10): failure label e3 is thrown but never recovered",
+			},
+		},
+		{
+			// a throw is reported only if no path from the entrypoints
+			// recovers it
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Recover(asttest.Ref("B"), asttest.Lit("x"), "e1")),
+					asttest.Rule("B", asttest.Throw("e1")),
+					asttest.Rule("C", asttest.Ref("B")),
+					asttest.Rule("D", asttest.Throw("e1")),
+				)
+			},
+			entrypoints: []string{"C", "D"},
+			want: []string{
+				"1:9 (8): failure label e1 is thrown but never recovered",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckFailureLabels(tc.g(), tc.entrypoints...)
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
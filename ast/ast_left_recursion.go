@@ -0,0 +1,312 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// CheckLeftRecursion reports every left-recursive cycle of rules in the
+// grammar. A rule is left-recursive if it can reference itself, directly
+// or through other rules, without consuming any input first, e.g. because
+// it is the first expression of a sequence or because all preceding
+// expressions can match the empty input. A parser generated for such a
+// grammar would recurse infinitely.
+//
+// Each cycle is reported once, starting at the first rule of the cycle in
+// grammar order, with the chain of rule references that form the cycle.
+// It returns nil if no cycle is found, otherwise the returned error is of
+// type ValidationErrors.
+func CheckLeftRecursion(g *Grammar) error {
+	var errs ValidationErrors
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+	}
+
+	for _, cycle := range leftRecursiveCycles(g) {
+		start := rules[cycle[len(cycle)-1].Name.Val]
+
+		var buf bytes.Buffer
+		buf.WriteString(start.Name.Val)
+		for _, ref := range cycle {
+			fmt.Fprintf(&buf, " -> %s (%s)", ref.Name.Val, ref.Pos())
+		}
+		errs.add(CodeLeftRecursion, start.Pos(), "rule %s is left-recursive: %s", start.Name.Val, buf.String())
+	}
+	return errs.err()
+}
+
+// MarkLeftRecursion sets the LeftRecursive and Leader fields of the rules
+// of the grammar, so that a parser supporting left recursion can be
+// generated. Rules that reference each other without consuming any input
+// form a group of left-recursive rules, and one rule of the group, the
+// leader, is responsible for growing the result of the whole group. The
+// leader is the first rule in grammar order that is part of every
+// left-recursive cycle of the group.
+//
+// It returns an error of type ValidationErrors if a group of rules has no
+// such rule, as left recursion cannot be supported for that group.
+func MarkLeftRecursion(g *Grammar) error {
+	graph := leftCallGraph(g)
+	sccs := stronglyConnectedRules(g, graph)
+
+	// the cycles of each group, indexed by the name of the first rule
+	// of the group in grammar order.
+	groups := make(map[string][][]*RuleRefExpr)
+	for _, cycle := range leftRecursiveCycles(g) {
+		start := cycle[len(cycle)-1].Name.Val
+		group := sccs[start][0].Name.Val
+		groups[group] = append(groups[group], cycle)
+	}
+
+	var errs ValidationErrors
+	for _, r := range g.Rules {
+		r.LeftRecursive = false
+		r.Leader = false
+	}
+	for _, r := range g.Rules {
+		cycles := groups[r.Name.Val]
+		if len(cycles) == 0 {
+			continue
+		}
+
+		var leader *Rule
+		var names []string
+		for _, member := range sccs[r.Name.Val] {
+			member.LeftRecursive = true
+			names = append(names, member.Name.Val)
+			if leader == nil && inAllCycles(member.Name.Val, cycles) {
+				leader = member
+			}
+		}
+		if leader == nil {
+			errs.add(CodeLeftRecursionLeader, r.Pos(), "left-recursive rules %s have no rule that is part of every cycle",
+				strings.Join(names, ", "))
+			continue
+		}
+		leader.Leader = true
+	}
+	return errs.err()
+}
+
+// inAllCycles returns true if the rule named nm is part of all cycles.
+func inAllCycles(nm string, cycles [][]*RuleRefExpr) bool {
+	for _, cycle := range cycles {
+		found := false
+		for _, ref := range cycle {
+			if ref.Name.Val == nm {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// stronglyConnectedRules computes the strongly connected components of the
+// left call graph. It returns, for each rule name, the rules of its
+// component in grammar order.
+func stronglyConnectedRules(g *Grammar, graph map[string][]*RuleRefExpr) map[string][]*Rule {
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		if _, ok := rules[r.Name.Val]; !ok {
+			rules[r.Name.Val] = r
+		}
+	}
+
+	// Tarjan's algorithm
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		sccs    = make(map[string][]*Rule)
+		visit   func(nm string)
+	)
+	visit = func(nm string) {
+		index[nm] = len(index)
+		lowlink[nm] = index[nm]
+		stack = append(stack, nm)
+		onStack[nm] = true
+
+		for _, ref := range graph[nm] {
+			next := ref.Name.Val
+			if _, ok := index[next]; !ok {
+				visit(next)
+				if lowlink[next] < lowlink[nm] {
+					lowlink[nm] = lowlink[next]
+				}
+			} else if onStack[next] && index[next] < lowlink[nm] {
+				lowlink[nm] = index[next]
+			}
+		}
+
+		if lowlink[nm] == index[nm] {
+			var members []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				members = append(members, top)
+				if top == nm {
+					break
+				}
+			}
+
+			var scc []*Rule
+			for _, r := range g.Rules {
+				for _, m := range members {
+					if r.Name.Val == m && rules[m] == r {
+						scc = append(scc, r)
+					}
+				}
+			}
+			for _, m := range members {
+				sccs[m] = scc
+			}
+		}
+	}
+
+	for _, r := range g.Rules {
+		if _, ok := index[r.Name.Val]; !ok {
+			visit(r.Name.Val)
+		}
+	}
+	return sccs
+}
+
+// leftCallGraph returns, for each rule of the grammar, the list of rule
+// references that may be invoked at the position where the rule started,
+// i.e. without consuming any input. Only the first reference to a given
+// rule is kept, and only references to rules defined in the grammar are
+// returned.
+func leftCallGraph(g *Grammar) map[string][]*RuleRefExpr {
+	nullables := NullableRules(g)
+	defined := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		defined[r.Name.Val] = true
+	}
+
+	graph := make(map[string][]*RuleRefExpr, len(g.Rules))
+	for _, r := range g.Rules {
+		seen := make(map[string]bool)
+		var refs []*RuleRefExpr
+		leftRefs(r.Expr, nullables, func(ref *RuleRefExpr) {
+			if defined[ref.Name.Val] && !seen[ref.Name.Val] {
+				seen[ref.Name.Val] = true
+				refs = append(refs, ref)
+			}
+		})
+		graph[r.Name.Val] = refs
+	}
+	return graph
+}
+
+// leftRefs calls fn for each rule reference that may be reached in expr
+// without consuming any input.
+func leftRefs(expr Expression, nullables map[string]bool, fn func(*RuleRefExpr)) {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *AndExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			leftRefs(alt, nullables, fn)
+		}
+	case *LabeledExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *NotExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *OneOrMoreExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *RecoveryExpr:
+		// the recovery expression may run at the start position if the
+		// throw happens before any input is consumed.
+		leftRefs(expr.Expr, nullables, fn)
+		leftRefs(expr.RecoverExpr, nullables, fn)
+	case *RuleRefExpr:
+		fn(expr)
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			leftRefs(e, nullables, fn)
+			if !Nullable(e, nullables) {
+				break
+			}
+		}
+	case *ZeroOrMoreExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	case *ZeroOrOneExpr:
+		leftRefs(expr.Expr, nullables, fn)
+	}
+}
+
+// leftRecursiveCycles returns all elementary cycles of the left call graph
+// of the grammar. Each cycle is returned as the list of rule references
+// that form it, the last reference being to the rule where the cycle
+// starts, which is the first rule of the cycle in grammar order.
+func leftRecursiveCycles(g *Grammar) [][]*RuleRefExpr {
+	graph := leftCallGraph(g)
+	index := make(map[string]int, len(g.Rules))
+	for i, r := range g.Rules {
+		if _, ok := index[r.Name.Val]; !ok {
+			index[r.Name.Val] = i
+		}
+	}
+
+	var cycles [][]*RuleRefExpr
+	for i, r := range g.Rules {
+		start := r.Name.Val
+		if index[start] != i {
+			// duplicate rule name, already processed
+			continue
+		}
+
+		// only rules that come after start in the grammar and that can
+		// get back to start are part of the cycles starting at start.
+		reach := map[string]bool{start: true}
+		for changed := true; changed; {
+			changed = false
+			for nm, refs := range graph {
+				if reach[nm] || index[nm] < i {
+					continue
+				}
+				for _, ref := range refs {
+					if reach[ref.Name.Val] {
+						reach[nm] = true
+						changed = true
+						break
+					}
+				}
+			}
+		}
+
+		var path []*RuleRefExpr
+		onPath := make(map[string]bool)
+		var visit func(nm string)
+		visit = func(nm string) {
+			onPath[nm] = true
+			for _, ref := range graph[nm] {
+				next := ref.Name.Val
+				switch {
+				case next == start:
+					cycle := make([]*RuleRefExpr, len(path)+1)
+					copy(cycle, path)
+					cycle[len(path)] = ref
+					cycles = append(cycles, cycle)
+				case reach[next] && !onPath[next] && index[next] > i:
+					path = append(path, ref)
+					visit(next)
+					path = path[:len(path)-1]
+				}
+			}
+			onPath[nm] = false
+		}
+		visit(start)
+	}
+	return cycles
+}
@@ -0,0 +1,206 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestCheckLeftRecursion(t *testing.T) {
+	cases := []struct {
+		g    func() *ast.Grammar
+		want []string
+	}{
+		{
+			// not left-recursive, input is consumed before the recursion
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Seq(asttest.Lit("a"), asttest.Ref("A")), asttest.Lit("b"))),
+				)
+			},
+		},
+		{
+			// direct left recursion
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("Expr", asttest.Choice(asttest.Seq(asttest.Ref("Expr"), asttest.Lit("+"), asttest.Ref("Term")), asttest.Ref("Term"))),
+					asttest.Rule("Term", asttest.Lit("1")),
+				)
+			},
+			want: []string{
+				"1:7 (6): rule Expr is left-recursive: Expr -> Expr (1:1 (0))",
+			},
+		},
+		{
+			// indirect left recursion
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Ref("B"), asttest.Lit("a"))),
+					asttest.Rule("B", asttest.Choice(asttest.Seq(asttest.Ref("A"), asttest.Lit("b")), asttest.Lit("c"))),
+				)
+			},
+			want: []string{
+				"1:4 (3): rule A is left-recursive: A -> B (1:1 (0)) -> A (1:5 (4))",
+			},
+		},
+		{
+			// left recursion through nullable prefixes
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Opt(asttest.Lit("a")), asttest.Ref("B"))),
+					asttest.Rule("B", asttest.Seq(asttest.Ref("C"), asttest.Ref("A"))),
+					asttest.Rule("C", asttest.Choice(asttest.Lit("c"), asttest.Lit(""))),
+				)
+			},
+			want: []string{
+				"1:5 (4): rule A is left-recursive: A -> B (1:3 (2)) -> A (1:7 (6))",
+			},
+		},
+		{
+			// multiple cycles sharing rules
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Ref("B"), asttest.Ref("C"))),
+					asttest.Rule("B", asttest.Choice(asttest.Ref("A"), asttest.Ref("B"))),
+					asttest.Rule("C", asttest.Seq(asttest.Ref("A"), asttest.Lit("c"))),
+				)
+			},
+			want: []string{
+				"1:4 (3): rule A is left-recursive: A -> B (1:1 (0)) -> A (1:5 (4))",
+				"1:4 (3): rule A is left-recursive: A -> C (1:2 (1)) -> A (1:9 (8))",
+				"1:8 (7): rule B is left-recursive: B -> B (1:6 (5))",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckLeftRecursion(tc.g())
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
+
+func TestMarkLeftRecursion(t *testing.T) {
+	cases := []struct {
+		g         func() *ast.Grammar
+		recursive []string
+		leaders   []string
+		err       string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Seq(asttest.Lit("a"), asttest.Ref("A")), asttest.Lit("b"))),
+				)
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("Expr", asttest.Choice(asttest.Seq(asttest.Ref("Expr"), asttest.Lit("+"), asttest.Ref("Term")), asttest.Ref("Term"))),
+					asttest.Rule("Term", asttest.Choice(asttest.Seq(asttest.Ref("Term"), asttest.Lit("*"), asttest.Ref("Num")), asttest.Ref("Num"))),
+					asttest.Rule("Num", asttest.Lit("1")),
+				)
+			},
+			recursive: []string{"Expr", "Term"},
+			leaders:   []string{"Expr", "Term"},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("S", asttest.Ref("A")),
+					asttest.Rule("A", asttest.Choice(asttest.Seq(asttest.Ref("B"), asttest.Lit("a")), asttest.Lit("x"))),
+					asttest.Rule("B", asttest.Choice(asttest.Seq(asttest.Ref("A"), asttest.Lit("b")), asttest.Seq(asttest.Ref("B"), asttest.Lit("c")))),
+				)
+			},
+			recursive: []string{"A", "B"},
+			leaders:   []string{"B"},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Ref("B"), asttest.Ref("C"), asttest.Lit("a"))),
+					asttest.Rule("B", asttest.Seq(asttest.Ref("A"), asttest.Lit("b"))),
+					asttest.Rule("C", asttest.Seq(asttest.Ref("A"), asttest.Lit("c"))),
+				)
+			},
+			recursive: []string{"A", "B", "C"},
+			leaders:   []string{"A"},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Ref("B"), asttest.Lit("a"))),
+					asttest.Rule("B", asttest.Choice(asttest.Ref("A"), asttest.Ref("C"))),
+					asttest.Rule("C", asttest.Choice(asttest.Seq(asttest.Ref("B"), asttest.Lit("c")), asttest.Ref("A"))),
+				)
+			},
+			recursive: []string{"A", "B", "C"},
+			leaders:   []string{"B"},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Ref("B"), asttest.Ref("C"))),
+					asttest.Rule("B", asttest.Choice(asttest.Ref("A"), asttest.Ref("C"))),
+					asttest.Rule("C", asttest.Choice(asttest.Ref("A"), asttest.Ref("B"))),
+				)
+			},
+			recursive: []string{"A", "B", "C"},
+			err:       "1:4 (3): left-recursive rules A, B, C have no rule that is part of every cycle",
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		g := tc.g()
+		err := ast.MarkLeftRecursion(g)
+		if tc.err == "" && err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%d: want error %q, got %v", i, tc.err, err)
+			}
+		}
+
+		var recursive, leaders []string
+		for _, r := range g.Rules {
+			if r.LeftRecursive {
+				recursive = append(recursive, r.Name.Val)
+			}
+			if r.Leader {
+				leaders = append(leaders, r.Name.Val)
+			}
+		}
+		if !reflect.DeepEqual(recursive, tc.recursive) {
+			t.Errorf("%d: want left-recursive rules %v, got %v", i, tc.recursive, recursive)
+		}
+		if !reflect.DeepEqual(leaders, tc.leaders) {
+			t.Errorf("%d: want leaders %v, got %v", i, tc.leaders, leaders)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ast
+
+// NullableRules computes the set of rules of the grammar that can match
+// without consuming any input. The set is computed as a fixed point, as
+// a rule may be nullable because of another rule defined later in the
+// grammar.
+func NullableRules(g *Grammar) map[string]bool {
+	nullables := make(map[string]bool, len(g.Rules))
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if nullables[r.Name.Val] {
+				continue
+			}
+			if Nullable(r.Expr, nullables) {
+				nullables[r.Name.Val] = true
+				changed = true
+			}
+		}
+	}
+	return nullables
+}
+
+// Nullable returns true if expr can match without consuming any input,
+// given the set of nullable rules as returned by NullableRules. Predicates
+// and code expressions are considered nullable, while a throw expression
+// is considered a failure, as it only succeeds by way of a recovery
+// expression.
+func Nullable(expr Expression, rules map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return Nullable(expr.Expr, rules)
+	case *AndCodeExpr, *AndExpr, *NotCodeExpr, *NotExpr, *StateCodeExpr,
+		*ZeroOrMoreExpr, *ZeroOrOneExpr:
+		return true
+	case *AnyMatcher, *CharClassMatcher, *ThrowExpr:
+		return false
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if Nullable(alt, rules) {
+				return true
+			}
+		}
+		return false
+	case *LabeledExpr:
+		return Nullable(expr.Expr, rules)
+	case *LitMatcher:
+		return expr.Val == ""
+	case *OneOrMoreExpr:
+		return Nullable(expr.Expr, rules)
+	case *RecoveryExpr:
+		return Nullable(expr.Expr, rules) || Nullable(expr.RecoverExpr, rules)
+	case *RuleRefExpr:
+		return rules[expr.Name.Val]
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			if !Nullable(e, rules) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// CheckRepetitions reports every zero-or-more and one-or-more expression
+// of the grammar whose expression can match without consuming any input,
+// e.g. ("a"?)*. Such a repetition would never end if the generated parser
+// did not stop it when it makes no progress. It returns nil if no such
+// repetition is found, otherwise the returned error is of type
+// ValidationErrors.
+func CheckRepetitions(g *Grammar) error {
+	nullables := NullableRules(g)
+
+	var errs ValidationErrors
+	Inspect(g, func(expr Expression) bool {
+		switch expr := expr.(type) {
+		case *ZeroOrMoreExpr:
+			if Nullable(expr.Expr, nullables) {
+				errs.add(CodeEmptyRepetition, expr.Pos(), "zero-or-more expression can match empty input")
+			}
+		case *OneOrMoreExpr:
+			if Nullable(expr.Expr, nullables) {
+				errs.add(CodeEmptyRepetition, expr.Pos(), "one-or-more expression can match empty input")
+			}
+		}
+		return true
+	})
+	return errs.err()
+}
@@ -0,0 +1,82 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestNullableRules(t *testing.T) {
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("A", asttest.Seq(asttest.Ref("B"), asttest.Ref("C"))),
+		asttest.Rule("B", asttest.Choice(asttest.Lit("b"), asttest.Ref("C"))),
+		asttest.Rule("C", asttest.Star(asttest.Lit("c"))),
+		asttest.Rule("D", asttest.Seq(asttest.Ref("C"), ast.NewAnyMatcher(asttest.Pos(), "."))),
+		asttest.Rule("E", asttest.Plus(asttest.Ref("A"))),
+	)
+
+	got := ast.NullableRules(g)
+	want := map[string]bool{"A": true, "B": true, "C": true, "E": true}
+	for _, r := range g.Rules {
+		if got[r.Name.Val] != want[r.Name.Val] {
+			t.Errorf("%s: want nullable %t, got %t", r.Name.Val, want[r.Name.Val], got[r.Name.Val])
+		}
+	}
+}
+
+func TestCheckRepetitions(t *testing.T) {
+	cases := []struct {
+		g    func() *ast.Grammar
+		want []string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Star(asttest.Lit("a")), asttest.Plus(asttest.Ref("B")))),
+					asttest.Rule("B", asttest.Lit("b")),
+				)
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Star(asttest.Opt(asttest.Lit("a")))),
+					asttest.Rule("B", asttest.Plus(asttest.Choice(asttest.Lit("b"), asttest.Ref("C")))),
+					asttest.Rule("C", asttest.Star(asttest.Lit("c"))),
+				)
+			},
+			want: []string{
+				"1:3 (2): zero-or-more expression can match empty input",
+				"1:8 (7): one-or-more expression can match empty input",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckRepetitions(tc.g())
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
@@ -23,10 +23,8 @@ func newGrammarOptimizer(protectedRules []string) *grammarOptimizer {
 	}
 
 	r := grammarOptimizer{
-		protectedRules:  pr,
-		rules:           make(map[string]*Rule),
-		ruleUsesRules:   make(map[string]map[string]struct{}),
-		ruleUsedByRules: make(map[string]map[string]struct{}),
+		protectedRules: pr,
+		rules:          make(map[string]*Rule),
 	}
 	r.visitor = r.init
 	return &r
@@ -44,14 +42,15 @@ func (r *grammarOptimizer) Visit(expr Expression) Visitor {
 // maps rules, ruleUsesRules and ruleUsedByRules.
 func (r *grammarOptimizer) init(expr Expression) Visitor {
 	switch expr := expr.(type) {
+	case *Grammar:
+		// Fill ruleUsesRules and ruleUsedByRules for the whole grammar
+		refs := NewRuleReferences(expr)
+		r.ruleUsesRules = refs.Uses
+		r.ruleUsedByRules = refs.UsedBy
 	case *Rule:
 		// Keep track of current rule, which is processed
 		r.rule = expr.Name.Val
 		r.rules[expr.Name.Val] = expr
-	case *RuleRefExpr:
-		// Fill ruleUsesRules and ruleUsedByRules for every RuleRefExpr
-		set(r.ruleUsesRules, r.rule, expr.Name.Val)
-		set(r.ruleUsedByRules, expr.Name.Val, r.rule)
 	}
 	return r
 }
@@ -214,7 +213,11 @@ func (r *grammarOptimizer) optimizeRules(exprs []Expression) []Expression {
 func (r *grammarOptimizer) optimizeRule(expr Expression) Expression {
 	// Optimize RuleRefExpr
 	if ruleRef, ok := expr.(*RuleRefExpr); ok {
-		if _, ok := r.ruleUsesRules[ruleRef.Name.Val]; !ok {
+		// References to undefined rules are reported by Validate, leave
+		// them untouched. Typed rules are not inlined, as labels bound to
+		// them get typed arguments in the generated code.
+		rule, defined := r.rules[ruleRef.Name.Val]
+		if _, ok := r.ruleUsesRules[ruleRef.Name.Val]; !ok && defined && rule.Type == "" {
 			r.optimized = true
 			delete(r.ruleUsedByRules[ruleRef.Name.Val], r.rule)
 			if len(r.ruleUsedByRules[ruleRef.Name.Val]) == 0 {
@@ -224,8 +227,7 @@ func (r *grammarOptimizer) optimizeRule(expr Expression) Expression {
 			if len(r.ruleUsesRules[r.rule]) == 0 {
 				delete(r.ruleUsesRules, r.rule)
 			}
-			// TODO: Check if reference exists, otherwise raise an error, which reference is missing!
-			return cloneExpr(r.rules[ruleRef.Name.Val].Expr)
+			return cloneExpr(rule.Expr)
 		}
 	}
 
@@ -434,6 +436,8 @@ func escapeRune(r rune) string {
 // * resolve nested sequences expression
 // * resolve sequence expressions with only one element
 // * combine character class matcher and literal matcher, where possible
+//
+// The grammar must not define a rule more than once, see CheckDuplicates.
 func Optimize(g *Grammar, alternateEntrypoints ...string) {
 	entrypoints := alternateEntrypoints
 	if len(g.Rules) > 0 {
@@ -336,6 +336,71 @@ var cases = []struct {
 			},
 		},
 	},
+	// Case 2, typed rules are not inlined
+	{
+		in: &Grammar{
+			Rules: []*Rule{
+				{
+					Name: &Identifier{
+						posValue: posValue{
+							Val: "x",
+						},
+					},
+					Expr: &RuleRefExpr{
+						Name: &Identifier{
+							posValue: posValue{
+								Val: "y",
+							},
+						},
+					},
+				},
+				{
+					Name: &Identifier{
+						posValue: posValue{
+							Val: "y",
+						},
+					},
+					Type: "string",
+					Expr: &LitMatcher{
+						posValue: posValue{
+							Val: "y",
+						},
+					},
+				},
+			},
+		},
+		out: &Grammar{
+			Rules: []*Rule{
+				{
+					Name: &Identifier{
+						posValue: posValue{
+							Val: "x",
+						},
+					},
+					Expr: &RuleRefExpr{
+						Name: &Identifier{
+							posValue: posValue{
+								Val: "y",
+							},
+						},
+					},
+				},
+				{
+					Name: &Identifier{
+						posValue: posValue{
+							Val: "y",
+						},
+					},
+					Type: "string",
+					Expr: &LitMatcher{
+						posValue: posValue{
+							Val: "y",
+						},
+					},
+				},
+			},
+		},
+	},
 }
 
 func TestOptimize(t *testing.T) {
@@ -0,0 +1,328 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxRuleParamsDepth is the maximum depth of nested instantiations of the
+// parameterized rules, so that a rule that instantiates itself with
+// different arguments does not expand forever.
+const maxRuleParamsDepth = 100
+
+// ExpandRuleParams replaces the parameterized rules of the grammar, e.g.
+//
+//	List<Item, Sep> = first:Item rest:( Sep Item )*
+//
+// by an instance of the rule for each distinct list of arguments it is
+// referenced with, e.g. List<Expr, ",">. An instance is a copy of the
+// rule where the references to the parameters are replaced by the
+// arguments, named after the rule and the number of the instance, e.g.
+// List_1, and the references to the parameterized rule are replaced by
+// references to the instance. The instances are added after the other
+// rules, in the order they are first referenced, and keep the positions
+// of the parameterized rule, so that the code blocks of each instance are
+// generated as distinct functions that refer to the parameterized rule.
+// The parameterized rules are removed.
+//
+// A parameterized rule must be referenced, and can be neither the first
+// rule of the grammar nor one of the alternate entrypoints, as it cannot
+// be parsed without arguments. As a rule type must be separated from the
+// rule name by a space, e.g. Start <int>, the errors for a rule with a
+// single parameter hint at the type the parameter may have been meant as.
+//
+// It returns nil if all references are valid, otherwise the returned error
+// is of type ValidationErrors.
+func ExpandRuleParams(g *Grammar, entrypoints ...string) error {
+	e := &paramsExpander{
+		params:    make(map[string]*Rule),
+		used:      make(map[string]bool),
+		names:     make(map[string]bool),
+		instances: make(map[string]*Rule),
+		count:     make(map[string]int),
+	}
+
+	var rules []*Rule
+	for _, r := range g.Rules {
+		e.names[r.Name.Val] = true
+		if len(r.Params) == 0 {
+			rules = append(rules, r)
+			continue
+		}
+		if _, ok := e.params[r.Name.Val]; !ok {
+			e.params[r.Name.Val] = r
+		}
+		seen := make(map[string]bool, len(r.Params))
+		for _, p := range r.Params {
+			if seen[p.Val] {
+				e.errs.add(CodeInvalidRuleParams, p.Pos(), "parameter %s already defined in rule %s", p.Val, r.Name.Val)
+			}
+			seen[p.Val] = true
+		}
+	}
+	if len(e.params) == 0 {
+		return e.errs.err()
+	}
+
+	for _, r := range rules {
+		r.Expr = e.expand(r.Expr, nil, 0)
+	}
+
+	isEntrypoint := make(map[string]bool, len(entrypoints))
+	for _, nm := range entrypoints {
+		isEntrypoint[nm] = true
+	}
+	for i, r := range g.Rules {
+		if len(r.Params) == 0 || e.params[r.Name.Val] != r {
+			continue
+		}
+		switch {
+		case i == 0:
+			e.errs.add(CodeInvalidRuleParams, r.Pos(), "the first rule %s cannot have parameters%s", r.Name.Val, ruleTypeHint(r))
+		case isEntrypoint[r.Name.Val]:
+			e.errs.add(CodeInvalidRuleParams, r.Pos(), "the alternate entrypoint %s cannot have parameters%s", r.Name.Val, ruleTypeHint(r))
+		case !e.used[r.Name.Val]:
+			e.errs.add(CodeInvalidRuleParams, r.Pos(), "rule %s has parameters but is never used%s", r.Name.Val, ruleTypeHint(r))
+		}
+	}
+	g.Rules = append(rules, e.added...)
+	return e.errs.err()
+}
+
+// ruleTypeHint returns a hint for the errors of the parameterized rule r,
+// which may have been meant as a typed rule if it has a single parameter,
+// e.g. Start<int> instead of Start <int>.
+func ruleTypeHint(r *Rule) string {
+	if len(r.Params) != 1 {
+		return ""
+	}
+	return " (for a rule of type " + r.Params[0].Val + ", write " + r.Name.Val + " <" + r.Params[0].Val + ">)"
+}
+
+// paramsExpander holds the state of ExpandRuleParams.
+type paramsExpander struct {
+	errs ValidationErrors
+
+	// params are the parameterized rules by name, used are those that are
+	// referenced, and names are the names of all rules.
+	params map[string]*Rule
+	used   map[string]bool
+	names  map[string]bool
+
+	// instances are the instances of the parameterized rules by key, see
+	// instanceKey, in the order they are added, and count is the number of
+	// instances of each rule.
+	instances map[string]*Rule
+	added     []*Rule
+	count     map[string]int
+}
+
+// expand returns a copy of expr where the references to the parameters in
+// args are replaced by a copy of their argument, and the references to the
+// parameterized rules by references to their instances. The arguments in
+// args are already expanded. Depth is the number of instances being
+// expanded.
+func (e *paramsExpander) expand(expr Expression, args map[string]Expression, depth int) Expression {
+	expandAll := func(exprs []Expression) []Expression {
+		out := make([]Expression, len(exprs))
+		for i, expr := range exprs {
+			out[i] = e.expand(expr, args, depth)
+		}
+		return out
+	}
+
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *AndCodeExpr:
+		c := *expr
+		return &c
+	case *AndExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *AnyMatcher:
+		c := *expr
+		return &c
+	case *CharClassMatcher:
+		c := *expr
+		return &c
+	case *ChoiceExpr:
+		c := *expr
+		c.Alternatives = expandAll(expr.Alternatives)
+		return &c
+	case *LabeledExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *LitMatcher:
+		c := *expr
+		return &c
+	case *NotCodeExpr:
+		c := *expr
+		return &c
+	case *NotExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *OneOrMoreExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *RecoveryExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		c.RecoverExpr = e.expand(expr.RecoverExpr, args, depth)
+		return &c
+	case *RuleRefExpr:
+		return e.expandRef(expr, args, depth)
+	case *SeqExpr:
+		c := *expr
+		c.Exprs = expandAll(expr.Exprs)
+		return &c
+	case *StateCodeExpr:
+		c := *expr
+		return &c
+	case *ThrowExpr:
+		c := *expr
+		return &c
+	case *ZeroOrMoreExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	case *ZeroOrOneExpr:
+		c := *expr
+		c.Expr = e.expand(expr.Expr, args, depth)
+		return &c
+	}
+	return expr
+}
+
+// expandRef returns the expansion of the reference ref, see expand.
+func (e *paramsExpander) expandRef(ref *RuleRefExpr, args map[string]Expression, depth int) Expression {
+	nm := ref.Name.Val
+	if arg, ok := args[nm]; ok && len(ref.Args) == 0 {
+		return e.expand(arg, nil, depth)
+	}
+
+	r, ok := e.params[nm]
+	if !ok {
+		if len(ref.Args) > 0 && e.names[nm] {
+			e.errs.add(CodeInvalidRuleParams, ref.Pos(), "rule %s has no parameters", nm)
+		}
+		// references to undefined rules are reported by Validate
+		c := *ref
+		c.Args = nil
+		return &c
+	}
+	e.used[nm] = true
+	if len(ref.Args) != len(r.Params) {
+		var hint string
+		if len(ref.Args) == 0 {
+			hint = ruleTypeHint(r)
+		}
+		e.errs.add(CodeInvalidRuleParams, ref.Pos(), "rule %s expects %d arguments, got %d%s", nm, len(r.Params), len(ref.Args), hint)
+		return &RuleRefExpr{p: ref.p, Name: ref.Name}
+	}
+
+	instArgs := make(map[string]Expression, len(r.Params))
+	keys := make([]string, len(r.Params))
+	for i, p := range r.Params {
+		arg := e.expand(ref.Args[i], args, depth)
+		instArgs[p.Val] = arg
+		keys[i] = instanceKey(arg)
+	}
+	key := nm + "<" + strings.Join(keys, ", ") + ">"
+
+	inst, ok := e.instances[key]
+	if !ok {
+		if depth >= maxRuleParamsDepth {
+			e.errs.add(CodeInvalidRuleParams, ref.Pos(), "instantiation of rule %s is nested too deeply", nm)
+			return &RuleRefExpr{p: ref.p, Name: ref.Name}
+		}
+
+		// the instance is added before its expression is expanded, so that
+		// recursive references use it.
+		inst = &Rule{p: r.p, DisplayName: r.DisplayName, Type: r.Type}
+		inst.Name = NewIdentifier(r.Name.p, e.instanceName(nm))
+		e.instances[key] = inst
+		e.added = append(e.added, inst)
+		inst.Expr = e.expand(r.Expr, instArgs, depth+1)
+	}
+	return &RuleRefExpr{p: ref.p, Name: NewIdentifier(ref.Name.p, inst.Name.Val)}
+}
+
+// instanceName returns the name of the next instance of the rule nm, which
+// is not the name of another rule.
+func (e *paramsExpander) instanceName(nm string) string {
+	for {
+		e.count[nm]++
+		inst := nm + "_" + strconv.Itoa(e.count[nm])
+		if !e.names[inst] {
+			e.names[inst] = true
+			return inst
+		}
+	}
+}
+
+// instanceKey returns the textual representation of the expanded argument
+// expr, without positions, so that the references with the same arguments
+// share the same instance.
+func instanceKey(expr Expression) string {
+	keys := func(exprs []Expression, sep string) string {
+		s := make([]string, len(exprs))
+		for i, expr := range exprs {
+			s[i] = instanceKey(expr)
+		}
+		return "(" + strings.Join(s, sep) + ")"
+	}
+
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return "(" + instanceKey(expr.Expr) + " " + expr.Code.Val + ")"
+	case *AndCodeExpr:
+		return "&" + expr.Code.Val
+	case *AndExpr:
+		return "&" + instanceKey(expr.Expr)
+	case *AnyMatcher:
+		return "."
+	case *CharClassMatcher:
+		return expr.Val
+	case *ChoiceExpr:
+		return keys(expr.Alternatives, " / ")
+	case *LabeledExpr:
+		return expr.Label.Val + ":" + instanceKey(expr.Expr)
+	case *LitMatcher:
+		if expr.IgnoreCase {
+			return strconv.Quote(expr.Val) + "i"
+		}
+		return strconv.Quote(expr.Val)
+	case *NotCodeExpr:
+		return "!" + expr.Code.Val
+	case *NotExpr:
+		return "!" + instanceKey(expr.Expr)
+	case *OneOrMoreExpr:
+		return instanceKey(expr.Expr) + "+"
+	case *RecoveryExpr:
+		labels := make([]string, len(expr.Labels))
+		for i, l := range expr.Labels {
+			labels[i] = string(l)
+		}
+		return "(" + instanceKey(expr.Expr) + " //{" + strings.Join(labels, ", ") + "} " + instanceKey(expr.RecoverExpr) + ")"
+	case *RuleRefExpr:
+		return expr.Name.Val
+	case *SeqExpr:
+		return keys(expr.Exprs, " ")
+	case *StateCodeExpr:
+		return "#" + expr.Code.Val
+	case *ThrowExpr:
+		return "%{" + expr.Label + "}"
+	case *ZeroOrMoreExpr:
+		return instanceKey(expr.Expr) + "*"
+	case *ZeroOrOneExpr:
+		return instanceKey(expr.Expr) + "?"
+	}
+	return ""
+}
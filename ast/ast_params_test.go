@@ -0,0 +1,135 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestExpandRuleParams(t *testing.T) {
+	cases := []struct {
+		g           func() *ast.Grammar
+		entrypoints []string
+		want        []string
+		errs        []string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(asttest.Rule("A", asttest.Ref("B")), asttest.Rule("B", asttest.Lit("b")))
+			},
+			want: []string{`A = B`, `B = "b"`},
+		},
+		{
+			// same arguments share the same instance
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(
+						asttest.Ref("List", asttest.Ref("B"), asttest.Lit(",")),
+						asttest.Ref("List", asttest.Ref("B"), asttest.Lit(";")),
+						asttest.Ref("List", asttest.Ref("B"), asttest.Lit(",")),
+					)),
+					asttest.ParamRule("List", asttest.Seq(asttest.Ref("Item"), asttest.Star(asttest.Seq(asttest.Ref("Sep"), asttest.Ref("Item")))), "Item", "Sep"),
+					asttest.Rule("B", asttest.Lit("b")),
+				)
+			},
+			want: []string{
+				`A = (List_1 List_2 List_1)`,
+				`B = "b"`,
+				`List_1 = (B ("," B)*)`,
+				`List_2 = (B (";" B)*)`,
+			},
+		},
+		{
+			// nested and recursive instances, names do not clash with rules
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Ref("Group", asttest.Ref("Group", asttest.Lit("a")))),
+					asttest.ParamRule("Group", asttest.Choice(asttest.Seq(asttest.Lit("("), asttest.Ref("Group", asttest.Ref("X")), asttest.Lit(")")), asttest.Ref("X")), "X"),
+					asttest.Rule("Group_1", asttest.Lit("b")),
+				)
+			},
+			want: []string{
+				`A = Group_3`,
+				`Group_1 = "b"`,
+				`Group_2 = (("(" Group_2 ")") / "a")`,
+				`Group_3 = (("(" Group_3 ")") / Group_2)`,
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Ref("B", asttest.Lit("a")), asttest.Ref("P"), asttest.Ref("P", asttest.Lit("a")))),
+					asttest.Rule("B", asttest.Lit("b")),
+					asttest.ParamRule("P", asttest.Ref("X"), "X", "X"),
+				)
+			},
+			errs: []string{
+				"1:13 (12): parameter X already defined in rule P",
+				"1:2 (1): rule B has no parameters",
+				"1:3 (2): rule P expects 2 arguments, got 0",
+				"1:5 (4): rule P expects 2 arguments, got 1",
+			},
+		},
+		{
+			// a rule type written without a space is a parameter
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.ParamRule("Start", asttest.Lit("s"), "int"),
+					asttest.Rule("A", asttest.Ref("N")),
+					asttest.ParamRule("N", asttest.Lit("1"), "int"),
+					asttest.ParamRule("E", asttest.Ref("X"), "X"),
+					asttest.ParamRule("U", asttest.Ref("X"), "X", "Y"),
+				)
+			},
+			entrypoints: []string{"E"},
+			errs: []string{
+				"1:4 (3): rule N expects 1 arguments, got 0 (for a rule of type int, write N <int>)",
+				"1:2 (1): the first rule Start cannot have parameters (for a rule of type int, write Start <int>)",
+				"1:10 (9): the alternate entrypoint E cannot have parameters (for a rule of type X, write E <X>)",
+				"1:13 (12): rule U has parameters but is never used",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		g := tc.g()
+		err := ast.ExpandRuleParams(g, tc.entrypoints...)
+		if len(tc.errs) > 0 {
+			errs, ok := err.(ast.ValidationErrors)
+			if !ok {
+				t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+				continue
+			}
+			if len(errs) != len(tc.errs) {
+				t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.errs), len(errs), errs)
+				continue
+			}
+			for j, e := range errs {
+				if e.Error() != tc.errs[j] {
+					t.Errorf("%d: error %d: want %q, got %q", i, j, tc.errs[j], e.Error())
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+
+		if len(g.Rules) != len(tc.want) {
+			t.Errorf("%d: want %d rules, got %d", i, len(tc.want), len(g.Rules))
+			continue
+		}
+		for j, r := range g.Rules {
+			got := r.Name.Val + " = " + ast.InstanceKey(r.Expr)
+			if len(r.Params) > 0 {
+				t.Errorf("%d: rule %s has parameters", i, r.Name.Val)
+			}
+			if got != tc.want[j] {
+				t.Errorf("%d: rule %d: want %q, got %q", i, j, tc.want[j], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ast
+
+// RuleReferences records the references between the rules of a grammar.
+type RuleReferences struct {
+	// Uses maps the name of a rule to the names of the rules it
+	// references.
+	Uses map[string]map[string]struct{}
+	// UsedBy maps the name of a rule to the names of the rules that
+	// reference it.
+	UsedBy map[string]map[string]struct{}
+}
+
+// NewRuleReferences collects the references between the rules of the
+// grammar. Rules that reference no other rule, or that are referenced by
+// no other rule, have no entry in Uses or UsedBy, respectively.
+func NewRuleReferences(g *Grammar) *RuleReferences {
+	refs := &RuleReferences{
+		Uses:   make(map[string]map[string]struct{}),
+		UsedBy: make(map[string]map[string]struct{}),
+	}
+	for _, r := range g.Rules {
+		nm := r.Name.Val
+		Inspect(r, func(expr Expression) bool {
+			if ref, ok := expr.(*RuleRefExpr); ok {
+				set(refs.Uses, nm, ref.Name.Val)
+				set(refs.UsedBy, ref.Name.Val, nm)
+			}
+			return true
+		})
+	}
+	return refs
+}
+
+// Reachable returns the set of rule names that can be reached from
+// the entrypoints, including the entrypoints themselves.
+func (r *RuleReferences) Reachable(entrypoints ...string) map[string]bool {
+	reached := make(map[string]bool)
+	stack := append([]string(nil), entrypoints...)
+	for len(stack) > 0 {
+		nm := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if reached[nm] {
+			continue
+		}
+		reached[nm] = true
+		for used := range r.Uses[nm] {
+			if !reached[used] {
+				stack = append(stack, used)
+			}
+		}
+	}
+	return reached
+}
+
+// UnreachableRules returns the rules of the grammar that cannot be reached
+// from the first rule of the grammar or from any of the alternate
+// entrypoints, in grammar order.
+func UnreachableRules(g *Grammar, alternateEntrypoints ...string) []*Rule {
+	if len(g.Rules) == 0 {
+		return nil
+	}
+
+	entrypoints := append([]string{g.Rules[0].Name.Val}, alternateEntrypoints...)
+	reached := NewRuleReferences(g).Reachable(entrypoints...)
+
+	var rules []*Rule
+	for _, r := range g.Rules {
+		if !reached[r.Name.Val] {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// CheckUnreachableRules reports every rule of the grammar that cannot be
+// reached from the first rule of the grammar or from any of the alternate
+// entrypoints. A rule that is not referenced by any other rule is reported
+// as unused, otherwise it is reported as unreachable, as it is only
+// referenced by rules that are themselves unreachable. It returns nil if
+// all rules are reachable, otherwise the returned error is of type
+// ValidationErrors.
+func CheckUnreachableRules(g *Grammar, alternateEntrypoints ...string) error {
+	refs := NewRuleReferences(g)
+
+	var errs ValidationErrors
+	for _, r := range UnreachableRules(g, alternateEntrypoints...) {
+		nm := r.Name.Val
+		usedBy := refs.UsedBy[nm]
+		if _, self := usedBy[nm]; len(usedBy) == 0 || (self && len(usedBy) == 1) {
+			errs.add(CodeUnusedRule, r.Pos(), "unused rule: %s", nm)
+			continue
+		}
+		errs.add(CodeUnreachableRule, r.Pos(), "unreachable rule: %s", nm)
+	}
+	return errs.err()
+}
@@ -0,0 +1,115 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestRuleReferences(t *testing.T) {
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("A", asttest.Seq(asttest.Ref("B"), asttest.Ref("A"))),
+		asttest.Rule("B", asttest.Choice(asttest.Ref("C"), asttest.Lit("b"))),
+		asttest.Rule("C", asttest.Lit("c")),
+	)
+
+	refs := ast.NewRuleReferences(g)
+	wantUses := map[string]map[string]struct{}{
+		"A": {"A": {}, "B": {}},
+		"B": {"C": {}},
+	}
+	wantUsedBy := map[string]map[string]struct{}{
+		"A": {"A": {}},
+		"B": {"A": {}},
+		"C": {"B": {}},
+	}
+	if !reflect.DeepEqual(refs.Uses, wantUses) {
+		t.Errorf("want uses %v, got %v", wantUses, refs.Uses)
+	}
+	if !reflect.DeepEqual(refs.UsedBy, wantUsedBy) {
+		t.Errorf("want used by %v, got %v", wantUsedBy, refs.UsedBy)
+	}
+
+	want := map[string]bool{"B": true, "C": true}
+	if got := refs.Reachable("B"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want reachable %v, got %v", want, got)
+	}
+}
+
+func TestCheckUnreachableRules(t *testing.T) {
+	cases := []struct {
+		g           func() *ast.Grammar
+		entrypoints []string
+		want        []string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Ref("B"), asttest.Ref("C"))),
+					asttest.Rule("B", asttest.Lit("b")),
+					asttest.Rule("C", asttest.Ref("B")),
+				)
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Ref("B")),
+					asttest.Rule("B", asttest.Lit("b")),
+					asttest.Rule("C", asttest.Seq(asttest.Ref("D"), asttest.Ref("C"))),
+					asttest.Rule("D", asttest.Ref("E")),
+					asttest.Rule("E", asttest.Lit("e")),
+				)
+			},
+			want: []string{
+				"1:8 (7): unused rule: C",
+				"1:10 (9): unreachable rule: D",
+				"1:12 (11): unreachable rule: E",
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Ref("B")),
+					asttest.Rule("B", asttest.Lit("b")),
+					asttest.Rule("C", asttest.Ref("D")),
+					asttest.Rule("D", asttest.Lit("d")),
+					asttest.Rule("E", asttest.Lit("e")),
+				)
+			},
+			entrypoints: []string{"C"},
+			want: []string{
+				"1:10 (9): unused rule: E",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckUnreachableRules(tc.g(), tc.entrypoints...)
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckShadowedAlternatives reports every alternative of a choice
+// expression that can never match because an earlier alternative always
+// matches when it would. The detected cases are:
+//
+//   - an earlier alternative always succeeds, e.g. "a"? / "b"
+//   - an earlier literal is a prefix of the alternative's leading literal,
+//     e.g. "<" / "<="
+//   - an earlier character class matches the first character of the
+//     alternative's leading literal, e.g. [<>] / "<="
+//
+// It returns nil if no shadowed alternative is found, otherwise the
+// returned error is of type ValidationErrors, each error being reported at
+// the position of the shadowed alternative.
+func CheckShadowedAlternatives(g *Grammar) error {
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+	}
+
+	var errs ValidationErrors
+	Inspect(g, func(expr Expression) bool {
+		ch, ok := expr.(*ChoiceExpr)
+		if !ok {
+			return true
+		}
+
+	alternatives:
+		for j, alt := range ch.Alternatives {
+			for _, prev := range ch.Alternatives[:j] {
+				if alwaysSucceeds(prev, rules, nil) {
+					errs.add(CodeShadowedAlternative, alt.Pos(), "alternative never matches, alternative at %s always succeeds", prev.Pos())
+					continue alternatives
+				}
+				if shadows(prev, alt, rules) {
+					errs.add(CodeShadowedAlternative, alt.Pos(), "alternative never matches, alternative at %s matches first", prev.Pos())
+					continue alternatives
+				}
+			}
+		}
+		return true
+	})
+	return errs.err()
+}
+
+// shadows returns true if prev always matches where alt matches.
+func shadows(prev, alt Expression, rules map[string]*Rule) bool {
+	lit := leadingLit(alt, rules, nil)
+	if lit == nil {
+		return false
+	}
+
+	prev, _ = unwrap(prev, rules, nil)
+	switch prev := prev.(type) {
+	case *LitMatcher:
+		if prev.IgnoreCase {
+			return strings.HasPrefix(strings.ToLower(lit.Val), strings.ToLower(prev.Val))
+		}
+		return !lit.IgnoreCase && strings.HasPrefix(lit.Val, prev.Val)
+
+	case *CharClassMatcher:
+		rn, _ := utf8.DecodeRuneInString(lit.Val)
+		if lit.IgnoreCase {
+			return prev.MatchesRune(unicode.ToLower(rn)) && prev.MatchesRune(unicode.ToUpper(rn))
+		}
+		return prev.MatchesRune(rn)
+	}
+	return false
+}
+
+// unwrap returns the expression that determines what expr matches,
+// skipping actions, labels and references to rules. The rules in visited
+// are those followed on the path to expr, they are not followed again,
+// which prevents infinite recursion on rules that reference each other.
+// It returns the rules followed on the path to the returned expression,
+// visited is not modified so that it can be shared by siblings.
+func unwrap(expr Expression, rules map[string]*Rule, visited map[string]bool) (Expression, map[string]bool) {
+	switch e := expr.(type) {
+	case *ActionExpr:
+		return unwrap(e.Expr, rules, visited)
+	case *LabeledExpr:
+		return unwrap(e.Expr, rules, visited)
+	case *RuleRefExpr:
+		rule := rules[e.Name.Val]
+		if rule == nil || visited[e.Name.Val] {
+			return expr, visited
+		}
+		path := make(map[string]bool, len(visited)+1)
+		for nm := range visited {
+			path[nm] = true
+		}
+		path[e.Name.Val] = true
+		return unwrap(rule.Expr, rules, path)
+	}
+	return expr, visited
+}
+
+// leadingLit returns the non-empty literal that expr must match first, or
+// nil if there is no such literal.
+func leadingLit(expr Expression, rules map[string]*Rule, visited map[string]bool) *LitMatcher {
+	expr, visited = unwrap(expr, rules, visited)
+	switch e := expr.(type) {
+	case *LitMatcher:
+		if e.Val != "" {
+			return e
+		}
+	case *SeqExpr:
+		if len(e.Exprs) > 0 {
+			return leadingLit(e.Exprs[0], rules, visited)
+		}
+	}
+	return nil
+}
+
+// alwaysSucceeds returns true if expr matches any input, possibly without
+// consuming it.
+func alwaysSucceeds(expr Expression, rules map[string]*Rule, visited map[string]bool) bool {
+	expr, visited = unwrap(expr, rules, visited)
+	switch e := expr.(type) {
+	case *ChoiceExpr:
+		for _, alt := range e.Alternatives {
+			if alwaysSucceeds(alt, rules, visited) {
+				return true
+			}
+		}
+	case *LitMatcher:
+		return e.Val == ""
+	case *SeqExpr:
+		for _, sub := range e.Exprs {
+			if !alwaysSucceeds(sub, rules, visited) {
+				return false
+			}
+		}
+		return true
+	case *ZeroOrMoreExpr, *ZeroOrOneExpr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestCheckShadowedAlternatives(t *testing.T) {
+	cases := []struct {
+		g    func() *ast.Grammar
+		want []string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Lit("<="), asttest.Lit("<"), ast.NewCharClassMatcher(asttest.Pos(), "[a-z]"), asttest.Lit("Z"))),
+				)
+			},
+		},
+		{
+			// literal versus literal
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Lit("<"), asttest.Lit("<="), asttest.Seq(asttest.Lit("<<"), asttest.Ref("B")), asttest.Ref("B"))),
+					asttest.Rule("B", asttest.Label("b", asttest.Lit("<>"))),
+				)
+			},
+			want: []string{
+				"1:2 (1): alternative never matches, alternative at 1:1 (0) matches first",
+				"1:5 (4): alternative never matches, alternative at 1:1 (0) matches first",
+				"1:6 (5): alternative never matches, alternative at 1:1 (0) matches first",
+			},
+		},
+		{
+			// case-insensitive literals
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Lit("a"), asttest.LitI("ab"), asttest.LitI("b"), asttest.Lit("Bc"), asttest.LitI("BCD"))),
+				)
+			},
+			want: []string{
+				"1:4 (3): alternative never matches, alternative at 1:3 (2) matches first",
+				"1:5 (4): alternative never matches, alternative at 1:3 (2) matches first",
+			},
+		},
+		{
+			// literal versus character class
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(
+						ast.NewCharClassMatcher(asttest.Pos(), "[a-z]"),
+						asttest.Lit("abc"),
+						asttest.Lit("Abc"),
+						ast.NewCharClassMatcher(asttest.Pos(), "[^0-9]i"),
+						asttest.LitI("Abc"),
+						asttest.Lit("1"),
+					)),
+				)
+			},
+			want: []string{
+				"1:2 (1): alternative never matches, alternative at 1:1 (0) matches first",
+				"1:5 (4): alternative never matches, alternative at 1:4 (3) matches first",
+			},
+		},
+		{
+			// always succeeding alternatives
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Lit("a"), asttest.Ref("B"), asttest.Lit("c"))),
+					asttest.Rule("B", asttest.Seq(asttest.Opt(asttest.Lit("b")), asttest.Star(asttest.Lit("c")))),
+				)
+			},
+			want: []string{
+				"1:3 (2): alternative never matches, alternative at 1:2 (1) always succeeds",
+			},
+		},
+		{
+			// the same rule referenced by siblings
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Choice(asttest.Seq(asttest.Ref("W"), asttest.Ref("W")), asttest.Lit("b"))),
+					asttest.Rule("W", asttest.Opt(asttest.Lit("x"))),
+				)
+			},
+			want: []string{
+				"1:4 (3): alternative never matches, alternative at 1:3 (2) always succeeds",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.CheckShadowedAlternatives(tc.g())
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Codes identifying the kind of a ValidationError. They are stable and
+// can be used by tools to recognize specific errors.
+const (
+	CodeDuplicateLabel      = "duplicate-label"
+	CodeDuplicateRule       = "duplicate-rule"
+	CodeEmptyRepetition     = "empty-repetition"
+	CodeInvalidDirective    = "invalid-directive"
+	CodeInvalidRuleParams   = "invalid-rule-params"
+	CodeLeftRecursion       = "left-recursion"
+	CodeLeftRecursionLeader = "left-recursion-leader"
+	CodeShadowedAlternative = "shadowed-alternative"
+	CodeUndefinedRule       = "undefined-rule"
+	CodeUnreachableRule     = "unreachable-rule"
+	CodeUnrecoveredLabel    = "unrecovered-label"
+	CodeUnthrownLabel       = "unthrown-label"
+	CodeUnusedRule          = "unused-rule"
+)
+
+// ValidationError is an error found while validating a grammar. It records
+// the position of the node that caused the error and the code identifying
+// the kind of error.
+type ValidationError struct {
+	Pos  Pos
+	Code string
+	Msg  string
+}
+
+// Error returns the error message, prefixed with the position.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ValidationErrors is a list of validation errors. It implements the
+// error interface.
+type ValidationErrors []*ValidationError
+
+// Error returns the error messages, one per line.
+func (e ValidationErrors) Error() string {
+	var buf bytes.Buffer
+	for i, err := range e {
+		if i > 0 {
+			buf.WriteRune('\n')
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// err returns nil if the list is empty, the list itself otherwise.
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationErrors) add(code string, p Pos, format string, args ...interface{}) {
+	*e = append(*e, &ValidationError{Pos: p, Code: code, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the grammar for errors that would otherwise only be
+// detected when the generated parser runs. It reports every reference
+// to an undefined rule. It returns nil if no error is found, otherwise
+// the returned error is of type ValidationErrors and lists all errors
+// in the order of the grammar.
+func Validate(g *Grammar) error {
+	rules := make(map[string]struct{}, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = struct{}{}
+	}
+
+	var errs ValidationErrors
+	Inspect(g, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok {
+			if _, ok := rules[ref.Name.Val]; !ok {
+				errs.add(CodeUndefinedRule, ref.Pos(), "undefined rule: %s", ref.Name.Val)
+			}
+		}
+		return true
+	})
+	return errs.err()
+}
@@ -0,0 +1,72 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+)
+
+func TestValidateUndefinedRules(t *testing.T) {
+	cases := []struct {
+		g    func() *ast.Grammar
+		want []string
+	}{
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(asttest.Rule("A", asttest.Lit("a")))
+			},
+		},
+		{
+			g: func() *ast.Grammar {
+				return asttest.Grammar(
+					asttest.Rule("A", asttest.Seq(asttest.Ref("B"), asttest.Ref("C"))),
+					asttest.Rule("B", asttest.Lit("b")),
+				)
+			},
+			want: []string{"1:2 (1): undefined rule: C"},
+		},
+		{
+			g: func() *ast.Grammar {
+				rec := ast.NewRecoveryExpr(asttest.Pos())
+				rec.Expr = asttest.Ref("X")
+				rec.RecoverExpr = asttest.Choice(asttest.Ref("A"), asttest.Ref("Y"))
+				return asttest.Grammar(
+					asttest.Rule("A", rec),
+					asttest.Rule("B", asttest.Ref("X")),
+				)
+			},
+			want: []string{
+				"1:2 (1): undefined rule: X",
+				"1:4 (3): undefined rule: Y",
+				"1:7 (6): undefined rule: X",
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		asttest.Reset()
+		err := ast.Validate(tc.g())
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ast.ValidationErrors)
+		if !ok {
+			t.Errorf("%d: want error of type %T, got %T", i, errs, err)
+			continue
+		}
+		if len(errs) != len(tc.want) {
+			t.Errorf("%d: want %d errors, got %d: %v", i, len(tc.want), len(errs), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != tc.want[j] {
+				t.Errorf("%d: error %d: want %q, got %q", i, j, tc.want[j], e.Error())
+			}
+		}
+	}
+}
@@ -50,16 +50,23 @@ func Walk(v Visitor, expr Expression) {
 		Walk(v, expr.Expr)
 	case *OneOrMoreExpr:
 		Walk(v, expr.Expr)
+	case *RecoveryExpr:
+		Walk(v, expr.Expr)
+		Walk(v, expr.RecoverExpr)
 	case *Rule:
 		Walk(v, expr.Expr)
 	case *RuleRefExpr:
-		// Nothing to do
+		for _, e := range expr.Args {
+			Walk(v, e)
+		}
 	case *SeqExpr:
 		for _, e := range expr.Exprs {
 			Walk(v, e)
 		}
 	case *StateCodeExpr:
 		// Nothing to do
+	case *ThrowExpr:
+		// Nothing to do
 	case *ZeroOrMoreExpr:
 		Walk(v, expr.Expr)
 	case *ZeroOrOneExpr:
@@ -0,0 +1,4 @@
+package ast
+
+// InstanceKey is exported for the tests of the ast_test package.
+var InstanceKey = instanceKey
@@ -3334,6 +3334,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
@@ -3344,6 +3345,14 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 			}
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -3434,12 +3443,21 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -0,0 +1,35 @@
+// Command runtime_generator generates the source code of the runtime
+// package imported by the parsers generated in the shared runtime mode,
+// based on the static code of the builder.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mna/pigeon/builder"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--" {
+		os.Args = os.Args[2:]
+	} else {
+		os.Args = os.Args[1:]
+	}
+	if len(os.Args) != 1 {
+		fmt.Fprintln(os.Stderr, "USAGE: runtime_generator OUTPUT")
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := builder.BuildRuntime(&buf); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := ioutil.WriteFile(os.Args[0], buf.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(3)
+	}
+}
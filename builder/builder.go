@@ -4,6 +4,7 @@ package builder
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -18,17 +19,21 @@ import (
 
 const codeGeneratedComment = "// Code generated by pigeon; DO NOT EDIT.\n\n"
 
+// generatorOptionsComment starts the comment of the header of the
+// generated code that records the options of the generator.
+const generatorOptionsComment = "// pigeon options:"
+
 // generated function templates
 var (
-	onFuncTemplate = `func (%s *current) %s(%s) (interface{}, error) {
+	onFuncTemplate = `func (%s *current) %s(%s) (%s, error) {
 %s
 }
 `
-	onPredFuncTemplate = `func (%s *current) %s(%s) (bool, error) {
+	onPredFuncTemplate = `func (%s *current) %s(%s) (%s, error) {
 %s
 }
 `
-	onStateFuncTemplate = `func (%s *current) %s(%s) (error) {
+	onStateFuncTemplate = `func (%s *current) %s(%s) (%s) {
 %s
 }
 `
@@ -49,6 +54,36 @@ var (
     _ = stack
     return p.cur.%[1]s(%s)
 }
+`
+	callFuncRuntimeTemplate = `func call%s(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).%[1]s(%s)
+}
+`
+	callPredFuncRuntimeTemplate = `func call%s(p *pigeonrt.Parser) (bool, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).%[1]s(%s)
+}
+`
+	callStateFuncRuntimeTemplate = `func call%s(p *pigeonrt.Parser) error {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).%[1]s(%s)
+}
+`
+	typedParseTemplate = `// ParseTyped parses the data from b using filename as information in the
+// error messages, like Parse, and returns the result with the type declared
+// by the first rule of the grammar, %[1]s.
+func ParseTyped(filename string, b []byte, opts ...Option) (%[1]s, error) {
+	v, err := Parse(filename, b, opts...)
+	t, ok := v.(%[1]s)
+	if err == nil && !ok {
+		err = fmt.Errorf("%%s: result is %%T, not %[1]s", filename, v)
+	}
+	return t, err
+}
 `
 )
 
@@ -101,12 +136,160 @@ func BasicLatinLookupTable(basicLatinLookupTable bool) Option {
 	}
 }
 
+// SupportLeftRecursion returns an option that specifies the
+// supportLeftRecursion option. If supportLeftRecursion is true, the rules
+// of the grammar may be left-recursive, and the generated parser grows
+// the result of left-recursive rules until the longest match is found.
+func SupportLeftRecursion(support bool) Option {
+	return func(b *builder) Option {
+		prev := b.supportLeftRecursion
+		b.supportLeftRecursion = support
+		return SupportLeftRecursion(prev)
+	}
+}
+
+// InferLabelTypes returns an option that specifies the inferLabelTypes
+// option. If inferLabelTypes is true, the labels whose value has a type
+// known statically are passed to the code blocks with that type instead of
+// interface{}: []byte for terminals and []interface{} for sequences and
+// repetitions.
+func InferLabelTypes(infer bool) Option {
+	return func(b *builder) Option {
+		prev := b.inferLabelTypes
+		b.inferLabelTypes = infer
+		return InferLabelTypes(prev)
+	}
+}
+
+// NativeFunctions returns an option that specifies the nativeFunctions
+// option. If nativeFunctions is true, each rule of the grammar is compiled
+// to a single method of the parser instead of being interpreted at runtime
+// from a table of expressions. The expressions of the rule are inlined in
+// its method as straight-line code: the values of the terminals and of the
+// sequences are typed Go variables, the labels are local variables of the
+// method and the code blocks are called directly with them. The results
+// and errors are the same as those of the interpreted parser.
+func NativeFunctions(native bool) Option {
+	return func(b *builder) Option {
+		prev := b.nativeFunctions
+		b.nativeFunctions = native
+		return NativeFunctions(prev)
+	}
+}
+
+// VirtualMachine returns an option that specifies the virtualMachine
+// option. If virtualMachine is true, the grammar is compiled to a program
+// run by a virtual machine with an explicit stack instead of recursive
+// calls, so that deeply nested input does not overflow the goroutine stack.
+func VirtualMachine(vm bool) Option {
+	return func(b *builder) Option {
+		prev := b.vm
+		b.vm = vm
+		return VirtualMachine(prev)
+	}
+}
+
+// SharedRuntime returns an option that specifies the sharedRuntime option.
+// If sharedRuntime is true, the generated parser contains only the grammar
+// and the code blocks, and imports the runtime package of pigeon for the
+// parser, its options and errors instead of embedding a copy of them.
+func SharedRuntime(sharedRuntime bool) Option {
+	return func(b *builder) Option {
+		prev := b.sharedRuntime
+		b.sharedRuntime = sharedRuntime
+		return SharedRuntime(prev)
+	}
+}
+
+// LineDirectives returns an option that specifies the names of the grammar
+// and generated files used in //line directives. If grammar is not empty,
+// each code block of the grammar, including the initializer, is preceded
+// by a //line directive that maps it to its position in the grammar file
+// and followed by one that maps the code back to the generated file named
+// output, so that the Go tools report the errors, stack traces and coverage
+// of the code blocks against the grammar. The names are relative to the
+// directory of the generated file.
+func LineDirectives(grammar, output string) Option {
+	return func(b *builder) Option {
+		prevGrammar, prevOutput := b.lineGrammar, b.lineOutput
+		b.lineGrammar, b.lineOutput = grammar, output
+		return LineDirectives(prevGrammar, prevOutput)
+	}
+}
+
+// GeneratorOptions returns an option that specifies the command-line
+// options of pigeon used to generate the parser. They are recorded in the
+// header of the generated code, so that the parser can be generated again
+// with the same options, see RecordedOptions.
+func GeneratorOptions(opts []string) Option {
+	return func(b *builder) Option {
+		prev := b.generatorOpts
+		b.generatorOpts = opts
+		return GeneratorOptions(prev)
+	}
+}
+
+// IdentifierPrefix returns an option that specifies the prefix of the
+// package-level identifiers of the generated parser, so that several
+// parsers can be generated in the same package. The identifiers keep their
+// exported or unexported status, e.g. with the prefix "calc", Parse becomes
+// CalcParse and parser becomes calcParser. The identifiers declared in the
+// initializer of the grammar are not prefixed.
+func IdentifierPrefix(prefix string) Option {
+	return func(b *builder) Option {
+		prev := b.prefix
+		b.prefix = prefix
+		return IdentifierPrefix(prev)
+	}
+}
+
 // BuildParser builds the PEG parser using the provider grammar. The code is
-// written to the specified w.
+// written to the specified w. The options set by @option directives in the
+// grammar are applied before opts, so that opts take precedence, and an
+// unknown or invalid directive is an error, see OptionDirectives. A rule or
+// a label defined more than once is an error, see ast.CheckDuplicates.
 func BuildParser(w io.Writer, g *ast.Grammar, opts ...Option) error {
+	if err := ast.CheckDuplicates(g); err != nil {
+		return err
+	}
 	b := &builder{w: w, recvName: "c"}
+	dirOpts, err := directiveOptions(g)
+	if err != nil {
+		return err
+	}
+	b.setOptions(dirOpts)
 	b.setOptions(opts)
-	return b.buildParser(g)
+	if b.prefix != "" {
+		if err := validPrefix(b.prefix); err != nil {
+			return err
+		}
+	}
+	if b.fuzz.w != nil {
+		if err := b.buildFuzzTest(g); err != nil {
+			return err
+		}
+	}
+	if b.prefix == "" && b.lineGrammar == "" {
+		return b.buildParser(g)
+	}
+
+	var buf bytes.Buffer
+	b.w = &buf
+	if err := b.buildParser(g); err != nil {
+		return err
+	}
+	src := buf.Bytes()
+	if b.prefix != "" {
+		init, _ := b.initCode(g)
+		if src, err = prefixIdentifiers(src, init, b.prefix); err != nil {
+			return err
+		}
+	}
+	if b.lineGrammar != "" {
+		src = RestoreLineDirectives(src, b.lineOutput)
+	}
+	_, err = w.Write(src)
+	return err
 }
 
 type builder struct {
@@ -115,18 +298,49 @@ type builder struct {
 
 	// options
 	recvName              string
+	prefix                string
 	optimize              bool
 	basicLatinLookupTable bool
 	globalState           bool
 	nolint                bool
-
-	ruleName  string
-	exprIndex int
-	argsStack [][]string
+	supportLeftRecursion  bool
+	inferLabelTypes       bool
+	nativeFunctions       bool
+	vm                    bool
+	sharedRuntime         bool
+	lineGrammar           string
+	lineOutput            string
+	grammarFilename       string
+	generatorOpts         []string
+	fuzz                  fuzzTest
+	haveLeftRecursion     bool
+
+	ruleName      string
+	exprIndex     int
+	argsStack     [][]funcArg
+	ruleTypes     map[string]string
+	resultActions map[*ast.ActionExpr]bool
+
+	// native functions mode
+	ruleIndex      map[string]int
+	nativeClasses  []string
+	nativeVarIx    int
+	nativeVarTypes map[string]string
+	nativeScopes   []map[string]nativeVar
+
+	// virtual machine mode, the instructions of the program
+	prog []string
 
 	rangeTable bool
 }
 
+// funcArg is an argument of a generated code block function, i.e. a label
+// in scope of the code block. Its type is empty for untyped labels.
+type funcArg struct {
+	name string
+	typ  string
+}
+
 func (b *builder) setOptions(opts []Option) {
 	for _, opt := range opts {
 		opt(b)
@@ -134,25 +348,86 @@ func (b *builder) setOptions(opts []Option) {
 }
 
 func (b *builder) buildParser(g *ast.Grammar) error {
-	b.writeInit(g.Init)
-	b.writeGrammar(g)
+	if b.nativeFunctions && b.vm {
+		return errors.New("the native functions and virtual machine modes are mutually exclusive")
+	}
+	if b.sharedRuntime && (b.nativeFunctions || b.vm || b.optimize || b.basicLatinLookupTable) {
+		return errors.New("the shared runtime mode cannot be combined with the native functions, virtual machine or optimized parser modes")
+	}
+	b.grammarFilename = g.Pos().Filename
+	if b.supportLeftRecursion {
+		if err := ast.MarkLeftRecursion(g); err != nil {
+			return err
+		}
+		for _, rule := range g.Rules {
+			b.haveLeftRecursion = b.haveLeftRecursion || rule.Leader
+		}
+	}
+
+	b.ruleTypes = make(map[string]string, len(g.Rules))
+	for _, rule := range g.Rules {
+		if _, ok := b.ruleTypes[rule.Name.Val]; !ok {
+			b.ruleTypes[rule.Name.Val] = rule.Type
+		}
+	}
+
+	b.writeInit(g)
+	switch {
+	case b.sharedRuntime:
+		b.writeRuntimeGrammar(g)
+	case b.nativeFunctions:
+		b.writeNativeGrammar(g)
+	case b.vm:
+		b.writeVMGrammar(g)
+	default:
+		b.writeGrammar(g)
+	}
 
 	for _, rule := range g.Rules {
 		b.writeRuleCode(rule)
 	}
 	b.writeStaticCode()
+	if len(g.Rules) > 0 && g.Rules[0].Type != "" {
+		b.writelnf(typedParseTemplate, g.Rules[0].Type)
+	}
 
 	return b.err
 }
 
-func (b *builder) writeInit(init *ast.CodeBlock) {
-	if init == nil {
+func (b *builder) writeInit(g *ast.Grammar) {
+	val, ok := b.initCode(g)
+	if !ok {
 		return
 	}
+	if b.sharedRuntime {
+		val = addRuntimeImport(val)
+	}
+	b.writelnf("%s%s", b.header(), val)
+}
+
+// header returns the comments at the start of the generated code.
+func (b *builder) header() string {
+	if len(b.generatorOpts) == 0 {
+		return codeGeneratedComment
+	}
+	return strings.TrimSuffix(codeGeneratedComment, "\n") +
+		generatorOptionsComment + " " + strings.Join(b.generatorOpts, " ") + "\n\n"
+}
 
-	// remove opening and closing braces
-	val := codeGeneratedComment + init.Val[1:len(init.Val)-1]
-	b.writelnf("%s", val)
+// RecordedOptions returns the command-line options recorded in the header
+// of the generated code src by the GeneratorOptions option, or nil if
+// there are none.
+func RecordedOptions(src []byte) []string {
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		if strings.HasPrefix(line, generatorOptionsComment) {
+			return strings.Fields(line[len(generatorOptionsComment):])
+		}
+	}
+	return nil
 }
 
 func (b *builder) writeGrammar(g *ast.Grammar) {
@@ -182,6 +457,9 @@ func (b *builder) writeRule(r *ast.Rule) {
 	}
 	pos := r.Pos()
 	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+	if b.haveLeftRecursion && r.Leader {
+		b.writelnf("\tleader: true,")
+	}
 	b.writef("\texpr: ")
 	b.writeExpr(r.Expr)
 	b.writelnf("},")
@@ -581,11 +859,33 @@ func (b *builder) writeRuleCode(rule *ast.Rule) {
 	// keep trace of the current rule, as the code blocks are created
 	// in functions named "on<RuleName><#ExprIndex>".
 	b.ruleName = rule.Name.Val
+	b.resultActions = make(map[*ast.ActionExpr]bool)
+	if rule.Type != "" {
+		b.markResultActions(rule.Expr)
+	}
 	b.pushArgsSet()
 	b.writeExprCode(rule.Expr)
 	b.popArgsSet()
 }
 
+// markResultActions marks the action expressions whose value is the value
+// returned by the rule, so that their code block returns the rule's type.
+func (b *builder) markResultActions(expr ast.Expression) {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		b.resultActions[expr] = true
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			b.markResultActions(alt)
+		}
+	case *ast.LabeledExpr:
+		b.markResultActions(expr.Expr)
+	case *ast.RecoveryExpr:
+		b.markResultActions(expr.Expr)
+		b.markResultActions(expr.RecoverExpr)
+	}
+}
+
 func (b *builder) pushArgsSet() {
 	b.argsStack = append(b.argsStack, nil)
 }
@@ -594,12 +894,35 @@ func (b *builder) popArgsSet() {
 	b.argsStack = b.argsStack[:len(b.argsStack)-1]
 }
 
-func (b *builder) addArg(arg *ast.Identifier) {
+func (b *builder) addArg(arg *ast.Identifier, typ string) {
 	if arg == nil {
 		return
 	}
 	ix := len(b.argsStack) - 1
-	b.argsStack[ix] = append(b.argsStack[ix], arg.Val)
+	b.argsStack[ix] = append(b.argsStack[ix], funcArg{name: arg.Val, typ: typ})
+}
+
+// labelType returns the type of the value of a label bound to expr, or an
+// empty string if the label is untyped. Labels bound to typed rules are
+// always typed, other labels only if the inferLabelTypes option is set.
+// Labels bound to predicates are untyped: the value of a predicate is
+// always nil, which cannot be asserted to a concrete type.
+func (b *builder) labelType(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.RuleRefExpr:
+		return b.ruleTypes[expr.Name.Val]
+	case *ast.AndExpr, *ast.NotExpr, *ast.AndCodeExpr, *ast.NotCodeExpr:
+		return ""
+	case *ast.AnyMatcher, *ast.CharClassMatcher, *ast.LitMatcher:
+		if b.inferLabelTypes {
+			return "[]byte"
+		}
+	case *ast.OneOrMoreExpr, *ast.SeqExpr, *ast.ZeroOrMoreExpr:
+		if b.inferLabelTypes {
+			return "[]interface{}"
+		}
+	}
+	return ""
 }
 
 func (b *builder) writeExprCode(expr ast.Expression) {
@@ -612,7 +935,7 @@ func (b *builder) writeExprCode(expr ast.Expression) {
 		b.writeAndCodeExprCode(expr)
 
 	case *ast.LabeledExpr:
-		b.addArg(expr.Label)
+		b.addArg(expr.Label, b.labelType(expr.Expr))
 		b.pushArgsSet()
 		b.writeExprCode(expr.Expr)
 		b.popArgsSet()
@@ -645,6 +968,10 @@ func (b *builder) writeExprCode(expr ast.Expression) {
 	case *ast.RecoveryExpr:
 		b.pushArgsSet()
 		b.writeExprCode(expr.Expr)
+		b.popArgsSet()
+		// the recover expression is parsed where the failure label is
+		// thrown, so the labels of the expression are not in its scope.
+		b.pushArgsSet()
 		b.writeExprCode(expr.RecoverExpr)
 		b.popArgsSet()
 
@@ -673,7 +1000,11 @@ func (b *builder) writeActionExprCode(act *ast.ActionExpr) {
 		return
 	}
 	if act.FuncIx > 0 {
-		b.writeFunc(act.FuncIx, act.Code, callFuncTemplate, onFuncTemplate)
+		retType := "interface{}"
+		if b.resultActions[act] {
+			retType = b.ruleTypes[b.ruleName]
+		}
+		b.writeFunc(act.FuncIx, act.Code, callFuncTemplate, onFuncTemplate, retType)
 		act.FuncIx = 0 // already rendered, prevent duplicates
 	}
 }
@@ -683,7 +1014,7 @@ func (b *builder) writeAndCodeExprCode(and *ast.AndCodeExpr) {
 		return
 	}
 	if and.FuncIx > 0 {
-		b.writeFunc(and.FuncIx, and.Code, callPredFuncTemplate, onPredFuncTemplate)
+		b.writeFunc(and.FuncIx, and.Code, callPredFuncTemplate, onPredFuncTemplate, "bool")
 		and.FuncIx = 0 // already rendered, prevent duplicates
 	}
 }
@@ -693,7 +1024,7 @@ func (b *builder) writeNotCodeExprCode(not *ast.NotCodeExpr) {
 		return
 	}
 	if not.FuncIx > 0 {
-		b.writeFunc(not.FuncIx, not.Code, callPredFuncTemplate, onPredFuncTemplate)
+		b.writeFunc(not.FuncIx, not.Code, callPredFuncTemplate, onPredFuncTemplate, "bool")
 		not.FuncIx = 0 // already rendered, prevent duplicates
 	}
 }
@@ -703,64 +1034,110 @@ func (b *builder) writeStateCodeExprCode(state *ast.StateCodeExpr) {
 		return
 	}
 	if state.FuncIx > 0 {
-		b.writeFunc(state.FuncIx, state.Code, callStateFuncTemplate, onStateFuncTemplate)
+		b.writeFunc(state.FuncIx, state.Code, callStateFuncTemplate, onStateFuncTemplate, "error")
 		state.FuncIx = 0 // already rendered, prevent duplicates
 	}
 }
 
-func (b *builder) writeFunc(funcIx int, code *ast.CodeBlock, callTpl, funcTpl string) {
+func (b *builder) writeFunc(funcIx int, code *ast.CodeBlock, callTpl, funcTpl, retType string) {
 	if code == nil {
 		return
 	}
 	val := strings.TrimSpace(code.Val)[1 : len(code.Val)-1]
+	pos := code.Pos()
+	pos.Col++
 	if len(val) > 0 && val[0] == '\n' {
 		val = val[1:]
+		pos.Line++
+		pos.Col = 1
 	}
 	if len(val) > 0 && val[len(val)-1] == '\n' {
 		val = val[:len(val)-1]
 	}
+	if b.lineGrammar != "" {
+		val = b.codeLineDirectives(val, pos)
+	}
+	var fnArgs []funcArg
+	if ix := len(b.argsStack) - 1; ix >= 0 {
+		fnArgs = b.argsStack[ix]
+	}
+
+	// consecutive arguments of the same type share the type, untyped
+	// arguments are interface{}.
 	var args bytes.Buffer
-	ix := len(b.argsStack) - 1
-	if ix >= 0 {
-		for i, arg := range b.argsStack[ix] {
-			if i > 0 {
-				args.WriteString(", ")
+	for i, arg := range fnArgs {
+		if i > 0 {
+			args.WriteString(", ")
+		}
+		args.WriteString(arg.name)
+		if i == len(fnArgs)-1 || fnArgs[i+1].typ != arg.typ {
+			typ := arg.typ
+			if typ == "" {
+				typ = "interface{}"
 			}
-			args.WriteString(arg)
+			args.WriteString(" " + typ)
 		}
 	}
-	if args.Len() > 0 {
-		args.WriteString(" interface{}")
-	}
 
 	fnNm := b.funcName(funcIx)
-	b.writelnf(funcTpl, b.recvName, fnNm, args.String(), val)
+	if b.sharedRuntime {
+		callTpl = runtimeCallTemplates[callTpl]
+	}
+	b.writelnf(funcTpl, b.recvName, fnNm, args.String(), retType, val)
 
+	// the native functions call the code blocks directly with the labels
+	if b.nativeFunctions {
+		return
+	}
 	args.Reset()
-	if ix >= 0 {
-		for i, arg := range b.argsStack[ix] {
-			if i > 0 {
-				args.WriteString(", ")
-			}
-			args.WriteString(fmt.Sprintf(`stack[%q]`, arg))
+	for i, arg := range fnArgs {
+		if i > 0 {
+			args.WriteString(", ")
+		}
+		if arg.typ != "" {
+			args.WriteString(fmt.Sprintf(`stack[%q].(%s)`, arg.name, arg.typ))
+		} else {
+			args.WriteString(fmt.Sprintf(`stack[%q]`, arg.name))
 		}
 	}
 	b.writelnf(callTpl, fnNm, args.String())
 }
 
 func (b *builder) writeStaticCode() {
-	buffer := bytes.NewBufferString("")
-	params := struct {
-		Optimize              bool
-		BasicLatinLookupTable bool
-		GlobalState           bool
-		Nolint                bool
-	}{
-		Optimize:              b.optimize,
-		BasicLatinLookupTable: b.basicLatinLookupTable,
-		GlobalState:           b.globalState,
-		Nolint:                b.nolint,
+	if b.sharedRuntime {
+		b.writeln(runtimeCode)
+	} else {
+		b.writeln(renderStaticCode(staticCodeParams{
+			Optimize:              b.optimize,
+			BasicLatinLookupTable: b.basicLatinLookupTable,
+			GlobalState:           b.globalState,
+			Nolint:                b.nolint,
+			LeftRecursion:         b.haveLeftRecursion,
+			Native:                b.nativeFunctions,
+			VM:                    b.vm,
+		}))
 	}
+	if b.rangeTable {
+		b.writeln(rangeTable0)
+	}
+}
+
+// staticCodeParams are the parameters of the static code template.
+type staticCodeParams struct {
+	Optimize              bool
+	BasicLatinLookupTable bool
+	GlobalState           bool
+	Nolint                bool
+	LeftRecursion         bool
+	Native                bool
+	VM                    bool
+	Runtime               bool
+}
+
+// renderStaticCode returns the static code for params, without the
+// template comments.
+func renderStaticCode(params staticCodeParams) string {
+	buffer := bytes.NewBufferString("")
 	t := template.Must(template.New("static_code").Parse(staticCode))
 
 	err := t.Execute(buffer, params)
@@ -784,11 +1161,7 @@ func (b *builder) writeStaticCode() {
 			}
 		}
 	}
-
-	b.writeln(buffer.String())
-	if b.rangeTable {
-		b.writeln(rangeTable0)
-	}
+	return buffer.String()
 }
 
 func (b *builder) funcName(ix int) string {
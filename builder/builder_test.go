@@ -1,10 +1,14 @@
 package builder
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/mna/pigeon/ast"
 	"github.com/mna/pigeon/bootstrap"
 )
 
@@ -25,7 +29,7 @@ multiplicative = left:primary op:"*" space right:multiplicative { fmt.Println(le
 primary = integer / "(" space additive:additive ")" space { fmt.Println(additive) }
 integer "integer" = digits:[0123456789]+ space { fmt.Println(digits) }
 space = ' '*
-eof = !. { fmt.Println("eof") }
+eof = end:!. { fmt.Println(end) }
 `
 
 func TestBuildParser(t *testing.T) {
@@ -38,3 +42,376 @@ func TestBuildParser(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestBuildParserDuplicates(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar+"space = '\\t'*\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "19:1 (546): rule space already defined at 17:1 (499)"
+	if err := BuildParser(ioutil.Discard, g); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func TestBuildParserInferLabelTypes(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, InferLabelTypes(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"(left interface{}, op []byte, right interface{}) (interface{}, error)",
+		"(digits []interface{}) (interface{}, error)",
+		`stack["op"].([]byte)`,
+		`stack["digits"].([]interface{})`,
+		// the value of a predicate is always nil
+		"(end interface{}) (interface{}, error)",
+		`stack["end"])`,
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+}
+
+func TestBuildParserNativeFunctions(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, NativeFunctions(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"g.rules[0].fn = (*parser).parsestart1",
+		"func (p *parser) parseadditive1() (interface{}, bool) {",
+		"left_1, ok = p.parseRule(g.rules[2])",
+		"actVal, err := p.cur.onadditive2(left_1, right_2)",
+		"ok = p.pt.rn == '+'",
+		"&& (cur == '0' || cur == '1'",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+	for _, nw := range []string{"expr: &", "callonadditive2"} {
+		if strings.Contains(buf.String(), nw) {
+			t.Errorf("want generated code not to contain %q", nw)
+		}
+	}
+}
+
+func TestBuildParserVirtualMachine(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, VirtualMachine(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"prog: []instr{",
+		"{op: opSeq, pos: position{line: 10, col: 9, offset: 96}, args: []int{1, 2}},",
+		"{op: opAction, pos: position{line: 11, col: 12, offset: 120}, args: []int{5}, val: (*parser).callonadditive2},",
+		"ix: 0,",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+
+	if err := BuildParser(ioutil.Discard, g, VirtualMachine(true), NativeFunctions(true)); err == nil {
+		t.Errorf("want error for native functions and virtual machine modes, got none")
+	}
+}
+
+func TestBuildParserIdentifierPrefix(t *testing.T) {
+	// the generated code must be valid Go to be renamed
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(strings.Replace(grammar, "{", "{\npackage calc\n", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, IdentifierPrefix("calc")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"func CalcParse(filename string, b []byte, opts ...CalcOption) (interface{}, error) {",
+		"func (c *calcCurrent) onadditive2(",
+		"return calcNewParser(filename, b, opts...).parse(calcG)",
+		"var test = \"some string\"",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+
+	if err := BuildParser(ioutil.Discard, g, IdentifierPrefix("1x")); err == nil {
+		t.Errorf("want error for invalid identifier prefix, got none")
+	}
+}
+
+func TestBuildParserLineDirectives(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(strings.Replace(grammar, "{", "{\npackage calc\n", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, LineDirectives("calc.peg", "calc.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"package calc\n//line calc.peg:4:1\n\nvar test",
+		"{\n//line calc.peg:14:1\n\tfmt.Println(left, right)\n//line calc.go:",
+		"{\n//line calc.peg:15:25\n fmt.Println(mul) \n//line calc.go:",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+	for i, line := range strings.Split(buf.String(), "\n") {
+		if want := fmt.Sprintf("//line calc.go:%d", i+2); strings.HasPrefix(line, "//line calc.go:") && line != want {
+			t.Errorf("line %d: want %q, got %q", i+1, want, line)
+		}
+	}
+}
+
+func TestBuildParserGeneratorOptions(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	opts := []string{"-nolint", "-receiver-name=self"}
+	if err := BuildParser(&buf, g, GeneratorOptions(opts)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := codeGeneratedComment[:len(codeGeneratedComment)-1] + "// pigeon options: -nolint -receiver-name=self\n\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("want generated code to start with %q", want)
+	}
+	if got := RecordedOptions(buf.Bytes()); !reflect.DeepEqual(got, opts) {
+		t.Errorf("want recorded options %q, got %q", opts, got)
+	}
+}
+
+func TestBuildParserSharedRuntime(t *testing.T) {
+	// the import of the runtime package is added after the package clause
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(strings.Replace(grammar, "{", "{\npackage calc\n", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, SharedRuntime(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"package calc\n\nimport pigeonrt \"github.com/mna/pigeon/runtime\"\n",
+		"var g = &pigeonrt.Grammar{",
+		"Run: callonadditive2,",
+		"func callonadditive2(p *pigeonrt.Parser) (interface{}, error) {",
+		"return pigeonrt.Parse(g, filename, b, opts...)",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+	if strings.Contains(buf.String(), "func newParser(") {
+		t.Errorf("want generated code without the static code")
+	}
+
+	if err := BuildParser(ioutil.Discard, g, SharedRuntime(true), VirtualMachine(true)); err == nil {
+		t.Errorf("want error for shared runtime and virtual machine modes, got none")
+	}
+}
+
+func TestBuildParserFuzzTest(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(strings.Replace(grammar, "{", "{\npackage calc\n", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			want: []string{
+				"package calc\n",
+				"const fuzzCorpus = \"testdata\"\n",
+				"const fuzzMaxExpressions = 1000\n",
+				"func FuzzParse(f *testing.F) {",
+				"func FuzzParsePrimary(f *testing.F) {",
+				"fuzzParse(t, b, Entrypoint(\"primary\"))",
+				"_, err := Parse(\"fuzz\", b, opts...)",
+				"list, ok := err.(errList)",
+				"if pe, ok := err.(*parserError); !ok || pe.pos.line == 0 {",
+			},
+		},
+		{
+			opts: []Option{IdentifierPrefix("calc")},
+			want: []string{
+				"func FuzzCalcParse(f *testing.F) {",
+				"func FuzzCalcParsePrimary(f *testing.F) {",
+				"calcFuzzParse(t, b, CalcEntrypoint(\"primary\"))",
+				"opts = append(opts, CalcRecover(false), CalcMaxExpressions(calcFuzzMaxExpressions))",
+				"if pe, ok := err.(*calcParserError); !ok || pe.pos.line == 0 {",
+			},
+		},
+		{
+			opts: []Option{SharedRuntime(true)},
+			want: []string{
+				"pigeonrt \"github.com/mna/pigeon/runtime\"",
+				"list, ok := err.(pigeonrt.ErrList)",
+				"if pe, ok := err.(*pigeonrt.ParserError); !ok || pe.Pos.Line == 0 {",
+			},
+		},
+	}
+	for i, c := range cases {
+		var buf bytes.Buffer
+		opts := append(c.opts, FuzzTest(&buf, "testdata", 1000, "primary"))
+		if err := BuildParser(ioutil.Discard, g, opts...); err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		for _, w := range c.want {
+			if !strings.Contains(buf.String(), w) {
+				t.Errorf("%d: want generated fuzz test to contain %q", i, w)
+			}
+		}
+	}
+
+	if err := BuildParser(ioutil.Discard, g, FuzzTest(ioutil.Discard, "testdata", 1000, "missing")); err == nil {
+		t.Errorf("want error for unknown entrypoint, got none")
+	}
+}
+
+func TestBuildRuntime(t *testing.T) {
+	var buf bytes.Buffer
+	if err := BuildRuntime(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile("../runtime/generated_runtime.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("want runtime package up to date with the static code, run go generate in the runtime directory")
+	}
+}
+
+func TestBuildParserDirectives(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := ast.NewDirective(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "option"))
+	d.Args = []string{"receiver-name", "self"}
+	g.Directives = append(g.Directives, d)
+
+	// the options passed to BuildParser take precedence over the directives
+	cases := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "func (self *current) "},
+		{[]Option{ReceiverName("x")}, "func (x *current) "},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := BuildParser(&buf, g, c.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), c.want) {
+			t.Errorf("want generated code to contain %q", c.want)
+		}
+	}
+
+	// the same directives as the pigeon command are supported, the unknown
+	// ones are errors
+	errCases := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"nolint", "maybe"}, `0:0 (0): invalid value "maybe" for option nolint`},
+		{[]string{"nolint", "true", "false"}, `0:0 (0): invalid value "true,false" for option nolint`},
+		{[]string{"receiver-name", "a", "b"}, `0:0 (0): invalid value "a,b" for option receiver-name`},
+		{[]string{"o", "out.go"}, "0:0 (0): unknown option o"},
+		{[]string{"line-directives"}, "0:0 (0): option line-directives requires a grammar file"},
+		{[]string{"warnings-as-errors"}, ""},
+	}
+	for _, c := range errCases {
+		d.Args = c.args
+		err := BuildParser(ioutil.Discard, g)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", c.args, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: want error %q, got %v", c.args, c.err, err)
+		}
+	}
+	d.Name.Val = "foo"
+	if err := BuildParser(ioutil.Discard, g); err == nil || err.Error() != "0:0 (0): unknown directive @foo" {
+		t.Errorf("want error for unknown directive, got %v", err)
+	}
+}
+
+func TestBuildParserDirectivesModes(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("grammar/calc.peg", strings.NewReader(strings.Replace(grammar, "{", "{\npackage calc\n", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, nm := range []string{"shared-runtime", "line-directives"} {
+		d := ast.NewDirective(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "option"))
+		d.Args = []string{nm}
+		g.Directives = append(g.Directives, d)
+	}
+
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"return pigeonrt.Parse(g, filename, b, opts...)",
+		"//line calc.peg:",
+		"//line calc.go:",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("want generated code to contain %q", w)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// optionKind is the kind of value of an option set by an @option
+// directive.
+type optionKind int
+
+const (
+	boolOption   optionKind = iota // no value or a boolean
+	stringOption                   // a single value
+	listOption                     // one or more values
+)
+
+// directiveOption describes an option that can be set by an @option
+// directive. Its name is that of the flag of the pigeon command.
+type directiveOption struct {
+	kind optionKind
+
+	// option returns the builder option for the value of the directive,
+	// it is nil for the options that do not affect the generated code,
+	// which are applied by the pigeon command.
+	option func(g *ast.Grammar, val string) (Option, error)
+}
+
+// optionDirectives are the options that can be set by @option directives.
+var optionDirectives = map[string]directiveOption{
+	"alternate-entrypoints":  {kind: listOption},
+	"identifier-prefix":      {kind: stringOption, option: stringDirective(IdentifierPrefix)},
+	"infer-label-types":      {kind: boolOption, option: boolDirective(InferLabelTypes)},
+	"line-directives":        {kind: boolOption, option: lineDirectivesDirective},
+	"native-functions":       {kind: boolOption, option: boolDirective(NativeFunctions)},
+	"nolint":                 {kind: boolOption, option: boolDirective(Nolint)},
+	"optimize-basic-latin":   {kind: boolOption, option: boolDirective(BasicLatinLookupTable)},
+	"optimize-grammar":       {kind: boolOption},
+	"optimize-parser":        {kind: boolOption, option: boolDirective(Optimize)},
+	"receiver-name":          {kind: stringOption, option: stringDirective(ReceiverName)},
+	"shared-runtime":         {kind: boolOption, option: boolDirective(SharedRuntime)},
+	"support-left-recursion": {kind: boolOption, option: boolDirective(SupportLeftRecursion)},
+	"vm":                     {kind: boolOption, option: boolDirective(VirtualMachine)},
+	"warnings-as-errors":     {kind: boolOption},
+}
+
+func boolDirective(fn func(bool) Option) func(*ast.Grammar, string) (Option, error) {
+	return func(_ *ast.Grammar, val string) (Option, error) {
+		return fn(val == "true"), nil
+	}
+}
+
+func stringDirective(fn func(string) Option) func(*ast.Grammar, string) (Option, error) {
+	return func(_ *ast.Grammar, val string) (Option, error) {
+		return fn(val), nil
+	}
+}
+
+// lineDirectivesDirective returns the LineDirectives option for the
+// grammar g, assuming that the parser is generated next to the grammar.
+func lineDirectivesDirective(g *ast.Grammar, val string) (Option, error) {
+	if val != "true" {
+		return LineDirectives("", ""), nil
+	}
+	if g.Pos().Filename == "" {
+		return nil, errors.New("option line-directives requires a grammar file")
+	}
+	return LineDirectives(LineDirectiveNames(g.Pos().Filename, "")), nil
+}
+
+// IsOptionDirective returns true if the option nm, named as the flag of
+// the pigeon command, can be set by an @option directive.
+func IsOptionDirective(nm string) bool {
+	_, ok := optionDirectives[nm]
+	return ok
+}
+
+// OptionDirective is an option set by an @option directive of a grammar,
+// e.g. @option receiver-name self.
+type OptionDirective struct {
+	Directive *ast.Directive
+
+	// Name is the name of the option, that of the flag of the pigeon
+	// command.
+	Name string
+
+	// Value is "true" or "false" for the boolean options, which are true
+	// without a value, and the values of the directive joined with commas
+	// for the other options.
+	Value string
+}
+
+// OptionDirectives returns the options set by the @option directives of
+// the grammar g. The @import and @test directives are skipped, any other
+// directive is an error. It returns an error of type ast.ValidationErrors
+// if a directive is unknown or invalid.
+func OptionDirectives(g *ast.Grammar) ([]*OptionDirective, error) {
+	var opts []*OptionDirective
+	var errs ast.ValidationErrors
+	invalid := func(d *ast.Directive, format string, args ...interface{}) {
+		errs = append(errs, &ast.ValidationError{Pos: d.Pos(), Code: ast.CodeInvalidDirective, Msg: fmt.Sprintf(format, args...)})
+	}
+	for _, d := range g.Directives {
+		if d.Name.Val == "import" || d.Name.Val == "test" {
+			// handled by the pigeon command
+			continue
+		}
+		if d.Name.Val != "option" {
+			invalid(d, "unknown directive @%s", d.Name.Val)
+			continue
+		}
+		if len(d.Args) == 0 {
+			invalid(d, "missing option name")
+			continue
+		}
+
+		nm, args := d.Args[0], d.Args[1:]
+		opt, ok := optionDirectives[nm]
+		if !ok {
+			invalid(d, "unknown option %s", nm)
+			continue
+		}
+		val := strings.Join(args, ",")
+		switch {
+		case opt.kind == boolOption && len(args) == 0:
+			val = "true"
+		case opt.kind == boolOption:
+			v, err := strconv.ParseBool(val)
+			if err != nil || len(args) > 1 {
+				invalid(d, "invalid value %q for option %s", val, nm)
+				continue
+			}
+			val = strconv.FormatBool(v)
+		case len(args) == 0:
+			invalid(d, "option %s requires a value", nm)
+			continue
+		case opt.kind == stringOption && len(args) > 1:
+			invalid(d, "invalid value %q for option %s", val, nm)
+			continue
+		}
+		opts = append(opts, &OptionDirective{Directive: d, Name: nm, Value: val})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return opts, nil
+}
+
+// directiveOptions returns the builder options set by the @option
+// directives of the grammar g, the options that do not affect the
+// generated code being skipped.
+func directiveOptions(g *ast.Grammar) ([]Option, error) {
+	dirs, err := OptionDirectives(g)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	var errs ast.ValidationErrors
+	for _, d := range dirs {
+		fn := optionDirectives[d.Name].option
+		if fn == nil {
+			continue
+		}
+		opt, err := fn(g, d.Value)
+		if err != nil {
+			errs = append(errs, &ast.ValidationError{Pos: d.Directive.Pos(), Code: ast.CodeInvalidDirective, Msg: err.Error()})
+			continue
+		}
+		opts = append(opts, opt)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return opts, nil
+}
@@ -0,0 +1,206 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"text/template"
+	"unicode"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// fuzzTest holds the options of the fuzz test generated with the parser.
+type fuzzTest struct {
+	w           io.Writer
+	corpus      string
+	maxExprCnt  uint64
+	entrypoints []string
+}
+
+// FuzzTest returns an option that makes BuildParser also write to w the
+// source code of a test file with a native Go fuzz test for the parser:
+// FuzzParse for the first rule of the grammar, and FuzzParse followed by
+// the rule name for each of the alternate entrypoints. The corpus of the
+// fuzz tests is seeded with the files of the directory corpus, relative to
+// the package of the parser. The inputs are parsed without recovering from
+// panics and with at most maxExprCnt expressions, so that the panics and
+// the inputs that exceed the limit fail the fuzz tests, as do the errors
+// that have no position.
+func FuzzTest(w io.Writer, corpus string, maxExprCnt uint64, entrypoints ...string) Option {
+	return func(b *builder) Option {
+		prev := b.fuzz
+		b.fuzz = fuzzTest{w: w, corpus: corpus, maxExprCnt: maxExprCnt, entrypoints: entrypoints}
+		return FuzzTest(prev.w, prev.corpus, prev.maxExprCnt, prev.entrypoints...)
+	}
+}
+
+var fuzzTestTemplate = template.Must(template.New("fuzz").Parse(codeGeneratedComment + `package {{.Package}}
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+{{- if .SharedRuntime}}
+
+	pigeonrt "github.com/mna/pigeon/runtime"
+{{- end}}
+)
+
+// {{.Corpus}} is the directory of the example inputs that seed the corpus
+// of the fuzz tests.
+const {{.Corpus}} = {{printf "%q" .CorpusDir}}
+
+// {{.MaxExpressions}} is the maximum number of expressions parsed for an
+// input.
+const {{.MaxExpressions}} = {{.MaxExprCnt}}
+{{range .Funcs}}
+// {{.Name}} fuzzes the parser from the {{if .Rule}}rule {{.Rule}}{{else}}first rule of the grammar{{end}}.
+func {{.Name}}(f *testing.F) {
+	{{$.Seed}}(f)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		{{$.Parse}}(t, b{{if .Rule}}, {{$.Names.Entrypoint}}({{printf "%q" .Rule}}){{end}})
+	})
+}
+{{end}}
+// {{.Seed}} adds the empty input and the files of {{.Corpus}} to the
+// corpus of f.
+func {{.Seed}}(f *testing.F) {
+	f.Add([]byte{})
+	fis, err := ioutil.ReadDir({{.Corpus}})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return
+		}
+		f.Fatal(err)
+	}
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join({{.Corpus}}, fi.Name()))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
+
+// {{.Parse}} parses b with the options opts. The parser does not recover
+// from panics, so that they fail the test with their stack trace, as does
+// the panic raised when more than {{.MaxExpressions}} expressions are
+// parsed. The errors must have a position.
+func {{.Parse}}(t *testing.T, b []byte, opts ...{{.Names.Option}}) {
+	opts = append(opts, {{.Names.Recover}}(false), {{.Names.MaxExpressions}}({{.MaxExpressions}}))
+	_, err := {{.Names.Parse}}("fuzz", b, opts...)
+	if err == nil {
+		return
+	}
+	list, ok := err.({{.Names.ErrList}})
+	if !ok {
+		t.Fatalf("%q: want an error of type {{.Names.ErrList}}, got %T: %v", b, err, err)
+	}
+	for _, err := range list {
+		if pe, ok := err.({{.Names.ParserError}}); !ok || pe.{{.Names.Line}} == 0 {
+			t.Fatalf("%q: error without position: %v", b, err)
+		}
+	}
+}
+`))
+
+// fuzzTestData is the data of fuzzTestTemplate.
+type fuzzTestData struct {
+	Package       string
+	SharedRuntime bool
+	CorpusDir     string
+	MaxExprCnt    uint64
+	Funcs         []fuzzFunc
+
+	// names of the identifiers declared by the fuzz test
+	Corpus, MaxExpressions, Seed, Parse string
+
+	// names of the identifiers of the parser used by the fuzz test
+	Names fuzzParserNames
+}
+
+// fuzzFunc is a fuzz test of fuzzTestData, for the entrypoint Rule or the
+// first rule of the grammar if Rule is empty.
+type fuzzFunc struct {
+	Name, Rule string
+}
+
+// fuzzParserNames are the names of the identifiers of the parser used by
+// the fuzz test, which depend on the options of the parser.
+type fuzzParserNames struct {
+	Option, Parse, Recover, MaxExpressions, Entrypoint string
+	ErrList, ParserError, Line                         string
+}
+
+// buildFuzzTest writes the fuzz test of the parser of g to the writer of
+// the FuzzTest option.
+func (b *builder) buildFuzzTest(g *ast.Grammar) error {
+	rules := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = true
+	}
+	init, _ := b.initCode(g)
+	f, err := parser.ParseFile(token.NewFileSet(), "", init, parser.PackageClauseOnly)
+	if err != nil {
+		return errors.New("fuzz test: the initializer of the grammar has no package clause")
+	}
+
+	name := func(nm string) string {
+		if b.prefix == "" {
+			return nm
+		}
+		return prefixedName(b.prefix, nm)
+	}
+	data := fuzzTestData{
+		Package:        f.Name.Name,
+		SharedRuntime:  b.sharedRuntime,
+		CorpusDir:      b.fuzz.corpus,
+		MaxExprCnt:     b.fuzz.maxExprCnt,
+		Funcs:          []fuzzFunc{{Name: "Fuzz" + name("Parse")}},
+		Corpus:         name("fuzzCorpus"),
+		MaxExpressions: name("fuzzMaxExpressions"),
+		Seed:           name("fuzzSeed"),
+		Parse:          name("fuzzParse"),
+		Names: fuzzParserNames{
+			Option:         name("Option"),
+			Parse:          name("Parse"),
+			Recover:        name("Recover"),
+			MaxExpressions: name("MaxExpressions"),
+			Entrypoint:     name("Entrypoint"),
+			ErrList:        name("errList"),
+			ParserError:    "*" + name("parserError"),
+			Line:           "pos.line",
+		},
+	}
+	if b.sharedRuntime {
+		data.Names.ErrList = "pigeonrt.ErrList"
+		data.Names.ParserError = "*pigeonrt.ParserError"
+		data.Names.Line = "Pos.Line"
+	}
+	for _, rule := range b.fuzz.entrypoints {
+		if !rules[rule] {
+			return fmt.Errorf("fuzz test: unknown entrypoint rule %s", rule)
+		}
+		data.Funcs = append(data.Funcs, fuzzFunc{Name: data.Funcs[0].Name + changeFirst(rule, unicode.ToUpper), Rule: rule})
+	}
+
+	var buf bytes.Buffer
+	if err := fuzzTestTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = b.fuzz.w.Write(src)
+	return err
+}
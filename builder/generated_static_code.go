@@ -48,9 +48,11 @@ func Entrypoint(ruleName string) Option {
 	return func(p *parser) Option {
 		oldEntrypoint := p.entrypoint
 		p.entrypoint = ruleName
+		// ==template== {{ if not .Runtime }}
 		if ruleName == "" {
 			p.entrypoint = g.rules[0].name
 		}
+		// {{ end }} ==template==
 		return Entrypoint(oldEntrypoint)
 	}
 }
@@ -167,8 +169,13 @@ func InitState(key string, value interface{}) Option {
 
 // {{ end }} ==template==
 
+// ==template== {{ if .Runtime }}
+// ParseFile parses the file identified by filename with the grammar g.
+func ParseFile(g *grammar, filename string, opts ...Option) (i interface{}, err error) {
+// {{ else }} ==template==
 // ParseFile parses the file identified by filename.
 func ParseFile(filename string, opts ...Option) (i interface{}, err error) { //{{ if .Nolint }} nolint: deadcode {{else}} ==template== {{ end }}
+// {{ end }} ==template==
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -178,9 +185,32 @@ func ParseFile(filename string, opts ...Option) (i interface{}, err error) { //{
 			err = closeErr
 		}
 	}()
+	// ==template== {{ if .Runtime }}
+	return ParseReader(g, filename, f, opts...)
+	// {{ else }} ==template==
 	return ParseReader(filename, f, opts...)
+	// {{ end }} ==template==
+}
+
+// ==template== {{ if .Runtime }}
+// ParseReader parses the data from r with the grammar g using filename as
+// information in the error messages.
+func ParseReader(g *grammar, filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(g, filename, b, opts...)
 }
 
+// Parse parses the data from b with the grammar g using filename as
+// information in the error messages.
+func Parse(g *grammar, filename string, b []byte, opts ...Option) (interface{}, error) {
+	return newParser(filename, b, opts...).parse(g)
+}
+
+// {{ else }} ==template==
 // ParseReader parses the data from r using filename as information in the
 // error messages.
 func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) { //{{ if .Nolint }} nolint: deadcode {{else}} ==template== {{ end }}
@@ -198,6 +228,8 @@ func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
 	return newParser(filename, b, opts...).parse(g)
 }
 
+// {{ end }} ==template==
+
 // position records a position in the text.
 type position struct {
 	line, col, offset int
@@ -244,6 +276,9 @@ type storeDict map[string]interface{}
 type grammar struct {
 	pos   position
 	rules []*rule
+	// ==template== {{ if .VM }}
+	prog []instr
+	// {{ end }} ==template==
 }
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -251,7 +286,16 @@ type rule struct {
 	pos         position
 	name        string
 	displayName string
-	expr        interface{}
+	// ==template== {{ if .LeftRecursion }}
+	leader bool
+	// {{ end }} ==template==
+	expr interface{}
+	// ==template== {{ if .Native }}
+	fn func(*parser) (interface{}, bool)
+	// {{ end }} ==template==
+	// ==template== {{ if .VM }}
+	ix int
+	// {{ end }} ==template==
 }
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -436,8 +480,10 @@ func newParser(filename string, b []byte, opts ...Option) *parser {
 		maxFailPos:      position{col: 1, line: 1},
 		maxFailExpected: make([]string, 0, 20),
 		Stats:           &stats,
+		// ==template== {{ if not .Runtime }}
 		// start rule is rule [0] unless an alternate entrypoint is specified
 		entrypoint: g.rules[0].name,
+		// {{ end }} ==template==
 		// ==template== {{ if or .GlobalState (not .Optimize) }}
 		emptyState: make(storeDict),
 		// {{ end }} ==template==
@@ -506,6 +552,8 @@ type parser struct {
 	debug bool
 
 	memoize bool
+	// {{ end }} ==template==
+	// ==template== {{ if or .LeftRecursion (not .Optimize) }}
 	// memoization table for the packrat algorithm:
 	// map[offset in source] map[expression or rule] {value, match}
 	memo map[int]map[interface{}]resultTuple
@@ -572,6 +620,20 @@ func (p *parser) popV() {
 	p.vstack = p.vstack[:len(p.vstack)-1]
 }
 
+// ==template== {{ if .Runtime }}
+// Current returns the context of the code block run by the parser.
+func (p *parser) Current() *current {
+	return &p.cur
+}
+
+// Labels returns the values of the labels in scope of the code block run by
+// the parser, by label name.
+func (p *parser) Labels() map[string]interface{} {
+	return p.vstack[len(p.vstack)-1]
+}
+
+// {{ end }} ==template==
+
 // push a recovery expression with its labels to the recoveryStack
 func (p *parser) pushRecovery(labels []string, expr interface{}) {
 	if cap(p.recoveryStack) == len(p.recoveryStack) {
@@ -756,7 +818,7 @@ func (p *parser) sliceFrom(start savepoint) []byte {
 	return p.data[start.position.offset:p.pt.position.offset]
 }
 
-// ==template== {{ if not .Optimize }}
+// ==template== {{ if or .LeftRecursion (not .Optimize) }}
 func (p *parser) getMemoized(node interface{}) (resultTuple, bool) {
 	if len(p.memo) == 0 {
 		return resultTuple{}, false
@@ -799,6 +861,12 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 
 	// TODO : not super critical but this could be generated
 	p.buildRulesTable(g)
+	// ==template== {{ if .Runtime }}
+	// start rule is rule [0] unless an alternate entrypoint is specified
+	if p.entrypoint == "" {
+		p.entrypoint = g.rules[0].name
+	}
+	// {{ end }} ==template==
 
 	if p.recover {
 		// panic can be used in action code to stop parsing immediately
@@ -829,7 +897,11 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 	}
 
 	p.read() // advance to first rune
+	// ==template== {{ if .VM }}
+	val, ok = p.runVM(g, startRule)
+	// {{ else }} ==template==
 	val, ok = p.parseRule(startRule)
+	// {{ end }} ==template==
 	if !ok {
 		if len(*p.errs) == 0 {
 			// If parsing fails, but no errors have been recorded, the expected values
@@ -871,6 +943,11 @@ func listJoin(list []string, sep string, lastSep string) string {
 }
 
 func (p *parser) parseRule(rule *rule) (interface{}, bool) {
+	// ==template== {{ if .LeftRecursion }}
+	if rule.leader {
+		return p.parseRuleRecursiveLeader(rule)
+	}
+	// {{ end }} ==template==
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
 		defer p.out(p.in("parseRule " + rule.name))
@@ -887,9 +964,14 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 	start := p.pt
 	// {{ end }} ==template==
 	p.rstack = append(p.rstack, rule)
+	// ==template== {{ if .Native }}
+	// the labels are local variables of the native functions
+	val, ok := rule.fn(p)
+	// {{ else }} ==template==
 	p.pushV()
 	val, ok := p.parseExpr(rule.expr)
 	p.popV()
+	// {{ end }} ==template==
 	p.rstack = p.rstack[:len(p.rstack)-1]
 	// ==template== {{ if not .Optimize }}
 	if ok && p.debug {
@@ -903,6 +985,660 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 	return val, ok
 }
 
+// ==template== {{ if .LeftRecursion }}
+
+// parseRuleRecursiveLeader parses the leader of a group of left-recursive
+// rules by growing a seed. The result of the rule at the current position
+// is first memoized as a failure, then the rule is parsed repeatedly, each
+// time with the previous result memoized for its left-recursive references,
+// until it fails or no longer consumes more input than the previous result.
+func (p *parser) parseRuleRecursiveLeader(rule *rule) (interface{}, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parseRuleRecursiveLeader " + rule.name))
+	}
+
+	// {{ end }} ==template==
+	res, ok := p.getMemoized(rule)
+	if ok {
+		p.restore(res.end)
+		return res.v, res.b
+	}
+
+	start := p.pt
+	last := resultTuple{nil, false, start}
+	errCnt := len(*p.errs)
+	var lastErrs []error
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	state := p.cloneState()
+	lastState := p.cloneState()
+	// {{ end }} ==template==
+	for {
+		// every attempt starts at the same position and with the same
+		// state, only the memoized seed changes.
+		p.setMemoized(start, rule, last)
+		// ==template== {{ if not .Optimize }}
+		if p.memoize {
+			p.forgetMemoized(start)
+		}
+		// {{ end }} ==template==
+		p.restore(start)
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		p.restoreState(state)
+		state = p.cloneState()
+		// {{ end }} ==template==
+		*p.errs = (*p.errs)[:errCnt]
+
+		p.rstack = append(p.rstack, rule)
+		// ==template== {{ if .Native }}
+		val, ok := rule.fn(p)
+		// {{ else }} ==template==
+		p.pushV()
+		val, ok := p.parseExpr(rule.expr)
+		p.popV()
+		// {{ end }} ==template==
+		p.rstack = p.rstack[:len(p.rstack)-1]
+
+		if !ok || (last.b && p.pt.offset <= last.end.offset) {
+			break
+		}
+		last = resultTuple{val, ok, p.pt}
+		lastErrs = append(lastErrs[:0], (*p.errs)[errCnt:]...)
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		lastState = p.cloneState()
+		// {{ end }} ==template==
+	}
+
+	p.restore(last.end)
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	p.restoreState(lastState)
+	// {{ end }} ==template==
+	*p.errs = append((*p.errs)[:errCnt], lastErrs...)
+	// ==template== {{ if not .Optimize }}
+	if p.memoize {
+		p.forgetMemoized(start)
+	}
+	if last.b && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	// {{ end }} ==template==
+	p.setMemoized(start, rule, last)
+	return last.v, last.b
+}
+
+// ==template== {{ if not .Optimize }}
+
+// forgetMemoized removes the results memoized at the position of pt, as
+// they may depend on the seed of a left-recursive rule that is being grown.
+// The seeds memoized for the leaders are kept.
+func (p *parser) forgetMemoized(pt savepoint) {
+	m := p.memo[pt.offset]
+	for node := range m {
+		if r, ok := node.(*rule); ok && r.leader {
+			continue
+		}
+		delete(m, node)
+	}
+}
+
+// {{ end }} ==template==
+
+// {{ end }} ==template==
+
+// ==template== {{ if .VM }}
+
+// opcode is the operation of an instruction of the program run by the
+// virtual machine.
+type opcode uint8
+
+// the opcodes, one per kind of expression.
+const (
+	opAction opcode = iota
+	opAndCode
+	opAnd
+	opAny
+	opCharClass
+	opChoice
+	opLabeled
+	opLit
+	opNotCode
+	opNot
+	opOneOrMore
+	opRecovery
+	opRuleRef
+	opSeq
+	opStateCode
+	opThrow
+	opZeroOrMore
+	opZeroOrOne
+)
+
+// ==template== {{ if not .Optimize }}
+var opNames = [...]string{
+	opAction:     "parseActionExpr",
+	opAndCode:    "parseAndCodeExpr",
+	opAnd:        "parseAndExpr",
+	opChoice:     "parseChoiceExpr",
+	opLabeled:    "parseLabeledExpr",
+	opNotCode:    "parseNotCodeExpr",
+	opNot:        "parseNotExpr",
+	opOneOrMore:  "parseOneOrMoreExpr",
+	opRecovery:   "parseRecoveryExpr",
+	opRuleRef:    "parseRuleRefExpr",
+	opSeq:        "parseSeqExpr",
+	opStateCode:  "parseStateCodeExpr",
+	opThrow:      "parseThrowExpr",
+	opZeroOrMore: "parseZeroOrMoreExpr",
+	opZeroOrOne:  "parseZeroOrOneExpr",
+}
+
+// {{ end }} ==template==
+
+// instr is an instruction of the program run by the virtual machine. Each
+// instruction parses an expression of the grammar, args are the indices of
+// the instructions of its sub-expressions and val is its operand: the
+// matcher of a terminal, the function of a code block, the label(s) or the
+// name of the referenced rule.
+//{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type instr struct {
+	op   opcode
+	pos  position
+	args []int
+	val  interface{}
+}
+
+// vmFrame is the state of an instruction or of a rule being run by the
+// virtual machine.
+//{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type vmFrame struct {
+	ix    int
+	rule  *rule
+	step  int
+	n     int
+	pt    savepoint
+	state storeDict
+	vals  []interface{}
+	// ==template== {{ if not .Optimize }}
+	memoPt savepoint
+	dbg    string
+	// {{ end }} ==template==
+	// ==template== {{ if .LeftRecursion }}
+	last      resultTuple
+	lastErrs  []error
+	lastState storeDict
+	// {{ end }} ==template==
+}
+
+// vm runs the program of the grammar with an explicit stack of frames
+// instead of recursive calls, so that deeply nested input does not exhaust
+// the goroutine stack. The result of the last frame that returned is in
+// val and ok.
+type vm struct {
+	prog  []instr
+	stack []vmFrame
+	val   interface{}
+	ok    bool
+}
+
+func (m *vm) callExpr(ix int) {
+	m.stack = append(m.stack, vmFrame{ix: ix})
+}
+
+func (m *vm) callRule(rule *rule) {
+	m.stack = append(m.stack, vmFrame{rule: rule})
+}
+
+// runVM parses the rule with the virtual machine.
+func (p *parser) runVM(g *grammar, rule *rule) (interface{}, bool) {
+	m := &vm{prog: g.prog}
+	m.callRule(rule)
+	for len(m.stack) > 0 {
+		f := &m.stack[len(m.stack)-1]
+		if f.rule != nil {
+			p.stepRule(m, f)
+			continue
+		}
+		p.stepExpr(m, f)
+	}
+	return m.val, m.ok
+}
+
+// vmReturn pops the frame at the top of the stack, with the result val
+// and ok.
+func (p *parser) vmReturn(m *vm, val interface{}, ok bool) {
+	// ==template== {{ if not .Optimize }}
+	if f := &m.stack[len(m.stack)-1]; f.dbg != "" {
+		p.out(f.dbg)
+	}
+	// {{ end }} ==template==
+	m.stack[len(m.stack)-1] = vmFrame{}
+	m.stack = m.stack[:len(m.stack)-1]
+	m.val, m.ok = val, ok
+}
+
+// stepRule runs the frame f of a rule until it returns or calls the
+// instruction of the rule's expression, the same way as parseRule.
+func (p *parser) stepRule(m *vm, f *vmFrame) {
+	rule := f.rule
+	// ==template== {{ if .LeftRecursion }}
+	if rule.leader {
+		p.stepRuleRecursiveLeader(m, f)
+		return
+	}
+	// {{ end }} ==template==
+	if f.step == 0 {
+		// ==template== {{ if not .Optimize }}
+		if p.debug {
+			f.dbg = p.in("parseRule " + rule.name)
+		}
+
+		if p.memoize {
+			res, ok := p.getMemoized(rule)
+			if ok {
+				p.restore(res.end)
+				p.vmReturn(m, res.v, res.b)
+				return
+			}
+		}
+
+		f.pt = p.pt
+		// {{ end }} ==template==
+		p.rstack = append(p.rstack, rule)
+		p.pushV()
+		f.step = 1
+		m.callExpr(rule.ix)
+		return
+	}
+
+	val, ok := m.val, m.ok
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	// ==template== {{ if not .Optimize }}
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(f.pt)))
+	}
+
+	if p.memoize {
+		p.setMemoized(f.pt, rule, resultTuple{val, ok, p.pt})
+	}
+	// {{ end }} ==template==
+	p.vmReturn(m, val, ok)
+}
+
+// ==template== {{ if .LeftRecursion }}
+
+// stepRuleRecursiveLeader runs the frame f of the leader of a group of
+// left-recursive rules, the same way as parseRuleRecursiveLeader: each step
+// is an attempt to grow the seed.
+func (p *parser) stepRuleRecursiveLeader(m *vm, f *vmFrame) {
+	rule := f.rule
+	if f.step == 0 {
+		// ==template== {{ if not .Optimize }}
+		if p.debug {
+			f.dbg = p.in("parseRuleRecursiveLeader " + rule.name)
+		}
+
+		// {{ end }} ==template==
+		res, ok := p.getMemoized(rule)
+		if ok {
+			p.restore(res.end)
+			p.vmReturn(m, res.v, res.b)
+			return
+		}
+
+		f.pt = p.pt
+		f.last = resultTuple{nil, false, f.pt}
+		f.n = len(*p.errs)
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		f.state = p.cloneState()
+		f.lastState = p.cloneState()
+		// {{ end }} ==template==
+	} else {
+		p.popV()
+		p.rstack = p.rstack[:len(p.rstack)-1]
+
+		if !m.ok || (f.last.b && p.pt.offset <= f.last.end.offset) {
+			p.restore(f.last.end)
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			p.restoreState(f.lastState)
+			// {{ end }} ==template==
+			*p.errs = append((*p.errs)[:f.n], f.lastErrs...)
+			// ==template== {{ if not .Optimize }}
+			if p.memoize {
+				p.forgetMemoized(f.pt)
+			}
+			if f.last.b && p.debug {
+				p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(f.pt)))
+			}
+			// {{ end }} ==template==
+			p.setMemoized(f.pt, rule, f.last)
+			p.vmReturn(m, f.last.v, f.last.b)
+			return
+		}
+		f.last = resultTuple{m.val, m.ok, p.pt}
+		f.lastErrs = append(f.lastErrs[:0], (*p.errs)[f.n:]...)
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		f.lastState = p.cloneState()
+		// {{ end }} ==template==
+	}
+
+	// every attempt starts at the same position and with the same
+	// state, only the memoized seed changes.
+	p.setMemoized(f.pt, rule, f.last)
+	// ==template== {{ if not .Optimize }}
+	if p.memoize {
+		p.forgetMemoized(f.pt)
+	}
+	// {{ end }} ==template==
+	p.restore(f.pt)
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	p.restoreState(f.state)
+	f.state = p.cloneState()
+	// {{ end }} ==template==
+	*p.errs = (*p.errs)[:f.n]
+
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	f.step = 1
+	m.callExpr(rule.ix)
+}
+
+// {{ end }} ==template==
+
+// stepExpr runs the frame f of an instruction until it returns or calls
+// another instruction or rule. Each step mirrors the corresponding parse
+// method of the expression, the result of the called frame being in m.val
+// and m.ok when the frame is resumed.
+//{{ if .Nolint }} nolint: gocyclo {{else}} ==template== {{ end }}
+func (p *parser) stepExpr(m *vm, f *vmFrame) {
+	in := &m.prog[f.ix]
+	if f.step == 0 {
+		// ==template== {{ if not .Optimize }}
+		if p.memoize {
+			res, ok := p.getMemoized(in)
+			if ok {
+				p.restore(res.end)
+				p.vmReturn(m, res.v, res.b)
+				return
+			}
+			f.memoPt = p.pt
+		}
+
+		// {{ end }} ==template==
+		p.ExprCnt++
+		if p.ExprCnt > p.maxExprCnt {
+			panic(errMaxExprCnt)
+		}
+		// ==template== {{ if not .Optimize }}
+		if p.debug && opNames[in.op] != "" {
+			name := opNames[in.op]
+			if in.op == opRuleRef {
+				name += " " + in.val.(string)
+			}
+			f.dbg = p.in(name)
+		}
+		// {{ end }} ==template==
+	}
+
+	var val interface{}
+	var ok bool
+	step := f.step
+	f.step++
+
+	switch in.op {
+	case opAction:
+		if step == 0 {
+			f.pt = p.pt
+			m.callExpr(in.args[0])
+			return
+		}
+		val, ok = m.val, m.ok
+		if ok {
+			p.cur.pos = f.pt.position
+			p.cur.text = p.sliceFrom(f.pt)
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			state := p.cloneState()
+			// {{ end }} ==template==
+			actVal, err := in.val.(func(*parser) (interface{}, error))(p)
+			if err != nil {
+				p.addErrAt(err, f.pt.position, []string{})
+			}
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			p.restoreState(state)
+			// {{ end }} ==template==
+
+			val = actVal
+		}
+		// ==template== {{ if not .Optimize }}
+		if ok && p.debug {
+			p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(f.pt)))
+		}
+		// {{ end }} ==template==
+
+	case opAndCode, opNotCode:
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		state := p.cloneState()
+		// {{ end }} ==template==
+		var err error
+		ok, err = in.val.(func(*parser) (bool, error))(p)
+		if err != nil {
+			p.addErr(err)
+		}
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		p.restoreState(state)
+		// {{ end }} ==template==
+		if in.op == opNotCode {
+			ok = !ok
+		}
+
+	case opAnd, opNot:
+		if step == 0 {
+			f.pt = p.pt
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			f.state = p.cloneState()
+			// {{ end }} ==template==
+			p.pushV()
+			if in.op == opNot {
+				p.maxFailInvertExpected = !p.maxFailInvertExpected
+			}
+			m.callExpr(in.args[0])
+			return
+		}
+		ok = m.ok
+		if in.op == opNot {
+			p.maxFailInvertExpected = !p.maxFailInvertExpected
+			ok = !ok
+		}
+		p.popV()
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		p.restoreState(f.state)
+		// {{ end }} ==template==
+		p.restore(f.pt)
+
+	case opAny:
+		val, ok = p.parseAnyMatcher(in.val.(*anyMatcher))
+
+	case opCharClass:
+		val, ok = p.parseCharClassMatcher(in.val.(*charClassMatcher))
+
+	case opChoice:
+		if step > 0 {
+			p.popV()
+			if m.ok {
+				// ==template== {{ if not .Optimize }}
+				p.incChoiceAltCnt(in.val.(*choiceExpr), step-1)
+				// {{ end }} ==template==
+				val, ok = m.val, m.ok
+				break
+			}
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			p.restoreState(f.state)
+			// {{ end }} ==template==
+		}
+		if step < len(in.args) {
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			f.state = p.cloneState()
+			// {{ end }} ==template==
+			p.pushV()
+			m.callExpr(in.args[step])
+			return
+		}
+		// ==template== {{ if not .Optimize }}
+		p.incChoiceAltCnt(in.val.(*choiceExpr), choiceNoMatch)
+		// {{ end }} ==template==
+
+	case opLabeled:
+		if step == 0 {
+			p.pushV()
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popV()
+		val, ok = m.val, m.ok
+		if label := in.val.(string); ok && label != "" {
+			p.vstack[len(p.vstack)-1][label] = val
+		}
+
+	case opLit:
+		val, ok = p.parseLitMatcher(in.val.(*litMatcher))
+
+	case opOneOrMore, opZeroOrMore:
+		if step > 0 {
+			p.popV()
+			if !m.ok {
+				if len(f.vals) == 0 && in.op == opOneOrMore {
+					// did not match once, no match
+					break
+				}
+				val, ok = f.vals, true
+				break
+			}
+			if p.pt.offset == f.n {
+				// matched without consuming any input, repeating would never
+				// end. Keep the value only if it is the first match.
+				if len(f.vals) == 0 {
+					f.vals = append(f.vals, m.val)
+				}
+				val, ok = f.vals, true
+				break
+			}
+			f.vals = append(f.vals, m.val)
+		}
+		f.n = p.pt.offset
+		p.pushV()
+		m.callExpr(in.args[0])
+		return
+
+	case opRecovery:
+		if step == 0 {
+			p.pushRecovery(in.val.([]string), in.args[1])
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popRecovery()
+		val, ok = m.val, m.ok
+
+	case opRuleRef:
+		if step == 0 {
+			name := in.val.(string)
+			if name == "" {
+				panic(fmt.Sprintf("%s: invalid rule: missing name", in.pos))
+			}
+
+			rule := p.rules[name]
+			if rule == nil {
+				p.addErr(fmt.Errorf("undefined rule: %s", name))
+				break
+			}
+			m.callRule(rule)
+			return
+		}
+		val, ok = m.val, m.ok
+
+	case opSeq:
+		if step == 0 {
+			f.vals = make([]interface{}, 0, len(in.args))
+			f.pt = p.pt
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			f.state = p.cloneState()
+			// {{ end }} ==template==
+		} else {
+			if !m.ok {
+				// ==template== {{ if or .GlobalState (not .Optimize) }}
+				p.restoreState(f.state)
+				// {{ end }} ==template==
+				p.restore(f.pt)
+				break
+			}
+			f.vals = append(f.vals, m.val)
+		}
+		if step < len(in.args) {
+			m.callExpr(in.args[step])
+			return
+		}
+		val, ok = f.vals, true
+
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	case opStateCode:
+		err := in.val.(func(*parser) error)(p)
+		if err != nil {
+			p.addErr(err)
+		}
+		ok = true
+
+	// {{ end }} ==template==
+	case opThrow:
+		if step == 0 {
+			f.n = len(p.recoveryStack)
+		} else if m.ok {
+			val, ok = m.val, m.ok
+			break
+		}
+		label := in.val.(string)
+		for f.n--; f.n >= 0; f.n-- {
+			if recoverExpr, found := p.recoveryStack[f.n][label]; found {
+				m.callExpr(recoverExpr.(int))
+				return
+			}
+		}
+
+	case opZeroOrOne:
+		if step == 0 {
+			p.pushV()
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popV()
+		val, ok = m.val, true
+
+	default:
+		panic(fmt.Sprintf("unknown opcode %d", in.op))
+	}
+
+	// ==template== {{ if not .Optimize }}
+	if p.memoize {
+		p.setMemoized(f.memoPt, in, resultTuple{val, ok, p.pt})
+	}
+	// {{ end }} ==template==
+	p.vmReturn(m, val, ok)
+}
+
+// {{ end }} ==template==
+
+// ==template== {{ if .Native }}
+
+// countExpr counts an expression about to be parsed by a generated
+// function and aborts the parse if the maximum number of expressions
+// is exceeded.
+func (p *parser) countExpr() {
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(errMaxExprCnt)
+	}
+}
+
+// {{ end }} ==template==
+
 //{{ if .Nolint }} nolint: gocyclo {{else}} ==template== {{ end }}
 func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	// ==template== {{ if not .Optimize }}
@@ -1307,6 +2043,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
@@ -1317,6 +2054,14 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 			}
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -1429,12 +2174,21 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
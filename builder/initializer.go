@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// initCode returns the code of the initializer of g, without its braces,
+// merged with the initializers of the grammars imported by g, if any. The
+// package clause and the imports of the first initializer are followed by
+// the imports of the others, then by the other declarations of each
+// initializer, in order. It returns false if there is no initializer.
+func (b *builder) initCode(g *ast.Grammar) (string, bool) {
+	var inits []*ast.CodeBlock
+	if g.Init != nil {
+		inits = append(inits, g.Init)
+	}
+	inits = append(inits, g.ImportedInits...)
+	if len(inits) == 0 {
+		return "", false
+	}
+
+	var head strings.Builder
+	decls := make([]string, len(inits))
+	for i, init := range inits {
+		// remove opening and closing braces
+		val := init.Val[1 : len(init.Val)-1]
+		pkg, imports, rest := splitInit(val)
+		if i == 0 {
+			head.WriteString(pkg)
+		}
+		head.WriteString(imports)
+		if i > 0 && imports != "" && !strings.HasSuffix(imports, "\n") {
+			head.WriteString("\n")
+		}
+
+		decls[i] = rest
+		if b.lineGrammar != "" {
+			pos := init.Pos()
+			pos.Line += strings.Count(pkg+imports, "\n")
+			decls[i] = b.declsLineDirective(rest, pos) + "\n" + rest
+		}
+	}
+
+	code := head.String() + strings.Join(decls, "\n")
+	if b.lineGrammar != "" {
+		code += "\n" + b.restoreLineDirective()
+	}
+	return code, true
+}
+
+// splitInit splits the code of an initializer in its package clause, its
+// import declarations and its other declarations, which start on a new
+// line. The package clause of the initializers of imported grammars is
+// optional. If the code cannot be parsed, it is returned as declarations,
+// as the generated code is invalid anyway.
+func splitInit(init string) (pkg, imports, decls string) {
+	const noPkg = "package p;"
+
+	fset := token.NewFileSet()
+	src, skip := init, 0
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		src, skip = noPkg+init, len(noPkg)
+		if f, err = parser.ParseFile(fset, "", src, parser.ImportsOnly); err != nil {
+			return "", "", init
+		}
+	}
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset - skip
+	}
+
+	pkgEnd := 0
+	if skip == 0 {
+		pkgEnd = offset(f.Name.End())
+	}
+	end := pkgEnd
+	if len(f.Decls) > 0 {
+		end = offset(f.Decls[len(f.Decls)-1].End())
+	}
+	if ix := strings.IndexByte(init[end:], '\n'); ix >= 0 {
+		end += ix + 1
+	} else {
+		end = len(init)
+	}
+	return init[:pkgEnd], init[pkgEnd:end], init[end:]
+}
@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// LineDirectiveNames returns the names of the grammar file and of the
+// generated file in the //line directives, as expected by LineDirectives,
+// relative to the directory of the generated file named output. If output
+// is empty, the parser is assumed to be generated next to the grammar,
+// with the .go extension.
+func LineDirectiveNames(grammar, output string) (string, string) {
+	if output == "" {
+		base := filepath.Base(grammar)
+		return base, strings.TrimSuffix(base, filepath.Ext(base)) + ".go"
+	}
+	dir, err := filepath.Abs(filepath.Dir(output))
+	if err == nil {
+		var abs string
+		if abs, err = filepath.Abs(grammar); err == nil {
+			grammar, err = filepath.Rel(dir, abs)
+		}
+	}
+	if err != nil {
+		grammar = filepath.Base(grammar)
+	}
+	return filepath.ToSlash(grammar), filepath.Base(output)
+}
+
+// lineDirective returns the //line directive that maps the line that
+// follows it to pos in the grammar.
+func (b *builder) lineDirective(pos ast.Pos) string {
+	return fmt.Sprintf("//line %s:%d:%d", b.lineFilename(pos), pos.Line, pos.Col)
+}
+
+// lineFilename returns the name of the grammar file of pos in the //line
+// directives. The files imported by the grammar are named relative to the
+// grammar, as their position has the path of the file relative to the
+// grammar's.
+func (b *builder) lineFilename(pos ast.Pos) string {
+	if pos.Filename == "" || pos.Filename == b.grammarFilename {
+		return b.lineGrammar
+	}
+	rel, err := filepath.Rel(filepath.Dir(b.grammarFilename), pos.Filename)
+	if err != nil {
+		return filepath.ToSlash(pos.Filename)
+	}
+	return path.Join(path.Dir(b.lineGrammar), filepath.ToSlash(rel))
+}
+
+// restoreLineDirective returns the //line directive that maps the code that
+// follows it back to the generated file. Its line is set by
+// RestoreLineDirectives once the code is generated.
+func (b *builder) restoreLineDirective() string {
+	return "//line " + b.lineOutput + ":1"
+}
+
+// codeLineDirectives returns the code val of the code block that starts at
+// pos in the grammar surrounded by //line directives, so that the errors,
+// stack traces and coverage of the code refer to the grammar.
+func (b *builder) codeLineDirectives(val string, pos ast.Pos) string {
+	return b.lineDirective(pos) + "\n" + val + "\n" + b.restoreLineDirective()
+}
+
+// declsLineDirective returns the //line directive that precedes the
+// declarations decls of an initializer, which start at pos in the grammar.
+func (b *builder) declsLineDirective(decls string, pos ast.Pos) string {
+	// the directive is separated from the code by an empty line, so that it
+	// is not part of the doc comment of the first declaration, as it would be
+	// moved to the end of the comment by gofmt.
+	pos.Col = 1
+	if !strings.HasPrefix(decls, "\n") {
+		pos.Line--
+		return b.lineDirective(pos) + "\n"
+	}
+	return b.lineDirective(pos)
+}
+
+// RestoreLineDirectives sets the line of the //line directives of the
+// generated code src that map the code back to the generated file named
+// output to their actual line, so that the code that follows them is
+// mapped to its own position. It must be called again if the lines of src
+// change, e.g. when it is formatted.
+func RestoreLineDirectives(src []byte, output string) []byte {
+	prefix := []byte("//line " + output + ":")
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(line, prefix) {
+			lines[i] = []byte(fmt.Sprintf("%s%d", prefix, i+2))
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
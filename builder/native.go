@@ -0,0 +1,726 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// nativeVar is a local variable of a generated parse method that holds the
+// value of a label.
+type nativeVar struct {
+	name string
+	typ  string
+}
+
+// writeNativeGrammar writes the grammar in the native functions mode: the
+// rules table only holds the rules' metadata, and the expression of each
+// rule is compiled to a parse<RuleName><#ExprIndex> method of the parser.
+// The sub-expressions are inlined as straight-line code in the method of
+// the rule, with their values in typed local variables that are only set
+// if the value is used, e.g. the values of the expression of an action are
+// not. The labels are local variables of the method too, and the code
+// blocks are called directly with them. Only the recover expressions get
+// methods of their own, as they are parsed where the failure label is
+// thrown.
+func (b *builder) writeNativeGrammar(g *ast.Grammar) {
+	b.ruleIndex = make(map[string]int, len(g.Rules))
+	for i, r := range g.Rules {
+		if _, ok := b.ruleIndex[r.Name.Val]; !ok {
+			b.ruleIndex[r.Name.Val] = i
+		}
+	}
+	// the state code blocks must be known before any function is written,
+	// as the state is cloned and restored inline.
+	ast.Inspect(g, func(expr ast.Expression) bool {
+		if _, ok := expr.(*ast.StateCodeExpr); ok {
+			b.globalState = true
+		}
+		return true
+	})
+
+	b.writelnf("var g = &grammar {")
+	b.writelnf("\trules: []*rule{")
+	for _, r := range g.Rules {
+		b.writelnf("{")
+		b.writelnf("\tname: %q,", r.Name.Val)
+		if r.DisplayName != nil && r.DisplayName.Val != "" {
+			b.writelnf("\tdisplayName: %q,", r.DisplayName.Val)
+		}
+		pos := r.Pos()
+		b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+		if b.haveLeftRecursion && r.Leader {
+			b.writelnf("\tleader: true,")
+		}
+		b.writelnf("},")
+	}
+	b.writelnf("\t},")
+	b.writelnf("}")
+
+	// the functions are set in init to prevent an initialization cycle, as
+	// they refer to g.
+	fns := make([]string, len(g.Rules))
+	for i, r := range g.Rules {
+		b.exprIndex = 0
+		b.nativeVarIx = 0
+		b.nativeVarTypes = map[string]string{"val": "interface{}"}
+		b.ruleName = r.Name.Val
+		fns[i] = b.writeNativeFunc(r.Expr)
+	}
+	b.writelnf("func init() {")
+	for i, fn := range fns {
+		b.writelnf("\tg.rules[%d].fn = (*parser).%s", i, fn)
+	}
+	b.writelnf("}")
+
+	if len(b.nativeClasses) > 0 {
+		b.rangeTable = true
+		b.writelnf("var rangeTables = []*unicode.RangeTable{")
+		for _, cl := range b.nativeClasses {
+			b.writelnf("\trangeTable(%q),", cl)
+		}
+		b.writelnf("}")
+	}
+}
+
+// writeNativeFunc writes the parse method of expr, the expression of a rule
+// or of a recovery, and returns its name.
+func (b *builder) writeNativeFunc(expr ast.Expression) string {
+	var w bytes.Buffer
+
+	name := b.nativeFuncName(b.exprIndex + 1)
+	if !b.optimize {
+		emitf(&w, "if p.debug {")
+		emitf(&w, "\tdefer p.out(p.in(%q))", name)
+		emitf(&w, "}")
+	}
+	emitf(&w, "var val interface{}")
+	emitf(&w, "var ok bool")
+	b.pushNativeScope(&w, expr)
+	b.writeNativeExpr(&w, expr, "val = %s")
+	b.popNativeScope()
+	emitf(&w, "return val, ok")
+
+	b.writeln("func (p *parser) " + name + "() (interface{}, bool) {\n" + w.String() + "}\n")
+	return name
+}
+
+// writeNativeExpr writes to w the code that parses expr and sets the ok
+// variable of the method to true if it matches. If it matches and set is
+// not empty, the value of expr is then set with the statement set, a
+// format string with a single verb for the value. Otherwise the value is
+// not computed at all.
+func (b *builder) writeNativeExpr(w *bytes.Buffer, expr ast.Expression, set string) {
+	b.exprIndex++
+	emitf(w, "p.countExpr()")
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		b.writeNativeActionExpr(w, expr, set)
+	case *ast.AndCodeExpr:
+		b.writeNativeCodeExpr(w, &expr.FuncIx, false, set)
+	case *ast.AndExpr:
+		b.writeNativeAndExpr(w, expr.Expr, false, set)
+	case *ast.AnyMatcher:
+		emitf(w, "if p.pt.rn == utf8.RuneError && p.pt.w == 0 {")
+		emitf(w, "\tp.failAt(false, p.pt.position, \".\")")
+		emitf(w, "\tok = false")
+		emitf(w, "} else {")
+		emitf(w, "\tstart := p.pt")
+		emitf(w, "\tp.read()")
+		emitf(w, "\tp.failAt(true, start.position, \".\")")
+		emitf(w, "\tok = true")
+		emitSet(w, set, "p.sliceFrom(start)")
+		emitf(w, "}")
+	case *ast.CharClassMatcher:
+		b.writeNativeCharClassMatcher(w, expr, set)
+	case *ast.ChoiceExpr:
+		b.writeNativeChoiceExpr(w, expr, set)
+	case *ast.LabeledExpr:
+		b.writeNativeLabeledExpr(w, expr, set)
+	case *ast.LitMatcher:
+		b.writeNativeLitMatcher(w, expr, set)
+	case *ast.NotCodeExpr:
+		b.writeNativeCodeExpr(w, &expr.FuncIx, true, set)
+	case *ast.NotExpr:
+		b.writeNativeAndExpr(w, expr.Expr, true, set)
+	case *ast.OneOrMoreExpr:
+		b.writeNativeRepeatedExpr(w, expr.Expr, true, set)
+	case *ast.RecoveryExpr:
+		b.writeNativeRecoveryExpr(w, expr, set)
+	case *ast.RuleRefExpr:
+		ix, found := b.ruleIndex[expr.Name.Val]
+		if !found {
+			emitf(w, "p.addErr(errors.New(%q))", "undefined rule: "+expr.Name.Val)
+			emitf(w, "ok = false")
+			return
+		}
+		if set == "" {
+			emitf(w, "_, ok = p.parseRule(g.rules[%d])", ix)
+			return
+		}
+		if v := strings.TrimSuffix(set, " = %s"); b.nativeVarTypes[v] == "interface{}" {
+			// the variable is only read if the rule matches
+			emitf(w, "%s, ok = p.parseRule(g.rules[%d])", v, ix)
+			return
+		}
+		v := b.nativeVar(w, "v", "interface{}")
+		emitf(w, "%s, ok = p.parseRule(g.rules[%d])", v, ix)
+		emitf(w, "if ok {")
+		emitSet(w, set, v)
+		emitf(w, "}")
+	case *ast.SeqExpr:
+		b.writeNativeSeqExpr(w, expr, set)
+	case *ast.StateCodeExpr:
+		if expr.FuncIx == 0 {
+			expr.FuncIx = b.exprIndex
+		}
+		emitf(w, "if err := p.cur.%s(%s); err != nil {", b.funcName(expr.FuncIx), b.nativeArgs())
+		emitf(w, "\tp.addErr(err)")
+		emitf(w, "}")
+		emitf(w, "ok = true")
+		emitSet(w, set, "nil")
+	case *ast.ThrowExpr:
+		emitf(w, "ok = false")
+		emitf(w, "for i := len(p.recoveryStack) - 1; i >= 0; i-- {")
+		emitf(w, "\tif recoverExpr, found := p.recoveryStack[i][%q]; found {", expr.Label)
+		if set == "" {
+			emitf(w, "\t\tif _, ok = recoverExpr.(func(*parser) (interface{}, bool))(p); ok {")
+		} else {
+			emitf(w, "\t\tvar recoverVal interface{}")
+			emitf(w, "\t\tif recoverVal, ok = recoverExpr.(func(*parser) (interface{}, bool))(p); ok {")
+			emitSet(w, set, "recoverVal")
+		}
+		emitf(w, "\t\t\tbreak")
+		emitf(w, "\t\t}")
+		emitf(w, "\t}")
+		emitf(w, "}")
+	case *ast.ZeroOrMoreExpr:
+		b.writeNativeRepeatedExpr(w, expr.Expr, false, set)
+	case *ast.ZeroOrOneExpr:
+		// the value is nil if expr does not match
+		emitf(w, "{")
+		var v string
+		if set != "" {
+			v = b.nativeVar(w, "v", "interface{}")
+		}
+		b.pushNativeScope(w, expr.Expr)
+		b.writeNativeExpr(w, expr.Expr, assignTo(v))
+		b.popNativeScope()
+		emitf(w, "ok = true")
+		emitSet(w, set, v)
+		emitf(w, "}")
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+}
+
+func (b *builder) writeNativeActionExpr(w *bytes.Buffer, act *ast.ActionExpr, set string) {
+	if act.FuncIx == 0 {
+		act.FuncIx = b.exprIndex
+	}
+
+	// the value of the expression is replaced by the value of the code
+	// block, only its labels are used.
+	emitf(w, "{")
+	emitf(w, "start := p.pt")
+	b.writeNativeExpr(w, act.Expr, "")
+	emitf(w, "if ok {")
+	emitf(w, "\tp.cur.pos = start.position")
+	emitf(w, "\tp.cur.text = p.sliceFrom(start)")
+	b.emitCloneState(w)
+	actVal := "actVal"
+	if set == "" {
+		actVal = "_"
+	}
+	emitf(w, "\t%s, err := p.cur.%s(%s)", actVal, b.funcName(act.FuncIx), b.nativeArgs())
+	emitf(w, "\tif err != nil {")
+	emitf(w, "\t\tp.addErrAt(err, start.position, []string{})")
+	emitf(w, "\t}")
+	b.emitRestoreState(w)
+	emitSet(w, set, "actVal")
+	emitf(w, "}")
+	if !b.optimize {
+		emitf(w, "if ok && p.debug {")
+		emitf(w, "\tp.print(strings.Repeat(\" \", p.depth)+\"MATCH\", string(p.sliceFrom(start)))")
+		emitf(w, "}")
+	}
+	emitf(w, "}")
+}
+
+// writeNativeCodeExpr writes the code of an and-code or, if not is true, of
+// a not-code expression.
+func (b *builder) writeNativeCodeExpr(w *bytes.Buffer, funcIx *int, not bool, set string) {
+	if *funcIx == 0 {
+		*funcIx = b.exprIndex
+	}
+	emitf(w, "{")
+	b.emitCloneState(w)
+	emitf(w, "pred, err := p.cur.%s(%s)", b.funcName(*funcIx), b.nativeArgs())
+	emitf(w, "if err != nil {")
+	emitf(w, "\tp.addErr(err)")
+	emitf(w, "}")
+	b.emitRestoreState(w)
+	if not {
+		emitf(w, "ok = !pred")
+	} else {
+		emitf(w, "ok = pred")
+	}
+	emitSetIfOk(w, set, "nil")
+	emitf(w, "}")
+}
+
+// writeNativeAndExpr writes the code of an and or, if not is true, of a not
+// expression.
+func (b *builder) writeNativeAndExpr(w *bytes.Buffer, expr ast.Expression, not bool, set string) {
+	emitf(w, "{")
+	emitf(w, "pt := p.pt")
+	b.emitCloneState(w)
+	if not {
+		emitf(w, "p.maxFailInvertExpected = !p.maxFailInvertExpected")
+	}
+	b.pushNativeScope(w, expr)
+	b.writeNativeExpr(w, expr, "")
+	b.popNativeScope()
+	if not {
+		emitf(w, "p.maxFailInvertExpected = !p.maxFailInvertExpected")
+	}
+	b.emitRestoreState(w)
+	emitf(w, "p.restore(pt)")
+	if not {
+		emitf(w, "ok = !ok")
+	}
+	emitSetIfOk(w, set, "nil")
+	emitf(w, "}")
+}
+
+func (b *builder) writeNativeChoiceExpr(w *bytes.Buffer, ch *ast.ChoiceExpr, set string) {
+	if len(ch.Alternatives) == 0 {
+		emitf(w, "ok = false")
+		return
+	}
+
+	emitf(w, "{")
+	if !b.optimize {
+		pos := ch.Pos()
+		emitf(w, "ch := &choiceExpr{pos: position{line: %d, col: %d, offset: %d}}", pos.Line, pos.Col, pos.Off)
+	}
+	for i, alt := range ch.Alternatives {
+		if i == 0 {
+			emitf(w, "{")
+		} else {
+			emitf(w, "if !ok {")
+		}
+		b.emitCloneState(w)
+		b.pushNativeScope(w, alt)
+		b.writeNativeExpr(w, alt, set)
+		b.popNativeScope()
+		if !b.optimize {
+			emitf(w, "if ok {")
+			emitf(w, "\tp.incChoiceAltCnt(ch, %d)", i)
+			emitf(w, "}")
+		}
+		if b.globalState || !b.optimize {
+			emitf(w, "if !ok {")
+			b.emitRestoreState(w)
+			emitf(w, "}")
+		}
+		emitf(w, "}")
+	}
+	if !b.optimize {
+		emitf(w, "if !ok {")
+		emitf(w, "\tp.incChoiceAltCnt(ch, choiceNoMatch)")
+		emitf(w, "}")
+	}
+	emitf(w, "}")
+}
+
+func (b *builder) writeNativeLabeledExpr(w *bytes.Buffer, lab *ast.LabeledExpr, set string) {
+	// the label is only set if a code block of its scope uses it.
+	var label nativeVar
+	if lab.Label != nil && lab.Label.Val != "" {
+		b.addArg(lab.Label, b.labelType(lab.Expr))
+		label = b.nativeScopes[len(b.nativeScopes)-1][lab.Label.Val]
+	}
+
+	emitf(w, "{")
+	b.pushNativeScope(w, lab.Expr)
+	if label.name == "" {
+		b.writeNativeExpr(w, lab.Expr, set)
+	} else {
+		b.writeNativeExpr(w, lab.Expr, assignTo(label.name))
+		emitSetIfOk(w, set, label.name)
+	}
+	b.popNativeScope()
+	emitf(w, "}")
+}
+
+// writeNativeRepeatedExpr writes the code of a one-or-more or, if oneOrMore
+// is false, of a zero-or-more expression.
+func (b *builder) writeNativeRepeatedExpr(w *bytes.Buffer, expr ast.Expression, oneOrMore bool, set string) {
+	emitf(w, "{")
+	var vals string
+	if set != "" {
+		vals = b.nativeVar(w, "v", "[]interface{}")
+	} else if oneOrMore {
+		emitf(w, "matched := false")
+	}
+	emitf(w, "for {")
+	emitf(w, "start := p.pt.offset")
+	b.pushNativeScope(w, expr)
+	var v string
+	if set != "" {
+		v = b.nativeVar(w, "v", b.nativeType(expr))
+	}
+	b.writeNativeExpr(w, expr, assignTo(v))
+	b.popNativeScope()
+	emitf(w, "if !ok {")
+	emitf(w, "\tbreak")
+	emitf(w, "}")
+	switch {
+	case set != "":
+		// an expression that matches without consuming input only gets a
+		// value if it is the first match.
+		emitf(w, "if p.pt.offset == start {")
+		emitf(w, "\tif len(%s) == 0 {", vals)
+		emitf(w, "\t\t%[1]s = append(%[1]s, %[2]s)", vals, v)
+		emitf(w, "\t}")
+		emitf(w, "\tbreak")
+		emitf(w, "}")
+		emitf(w, "%[1]s = append(%[1]s, %[2]s)", vals, v)
+	case oneOrMore:
+		emitf(w, "matched = true")
+		fallthrough
+	default:
+		emitf(w, "if p.pt.offset == start {")
+		emitf(w, "\tbreak")
+		emitf(w, "}")
+	}
+	emitf(w, "}")
+	switch {
+	case !oneOrMore:
+		emitf(w, "ok = true")
+	case set != "":
+		emitf(w, "ok = len(%s) > 0", vals)
+	default:
+		emitf(w, "ok = matched")
+	}
+	emitSetIfOk(w, set, vals)
+	emitf(w, "}")
+}
+
+func (b *builder) writeNativeRecoveryExpr(w *bytes.Buffer, recover *ast.RecoveryExpr, set string) {
+	var labels []string
+	for _, label := range recover.Labels {
+		labels = append(labels, strconv.Quote(string(label)))
+	}
+	var ew bytes.Buffer
+	emitf(&ew, "{")
+	b.pushNativeScope(&ew, recover.Expr)
+	b.writeNativeExpr(&ew, recover.Expr, set)
+	b.popNativeScope()
+	emitf(&ew, "}")
+	recoverFn := b.writeNativeFunc(recover.RecoverExpr)
+	emitf(w, "p.pushRecovery([]string{%s}, (*parser).%s)", strings.Join(labels, ", "), recoverFn)
+	w.Write(ew.Bytes())
+	emitf(w, "p.popRecovery()")
+}
+
+func (b *builder) writeNativeSeqExpr(w *bytes.Buffer, seq *ast.SeqExpr, set string) {
+	if len(seq.Exprs) == 0 {
+		emitf(w, "ok = true")
+		emitSet(w, set, "[]interface{}{}")
+		return
+	}
+
+	// the values of the expressions are only collected in a slice once the
+	// sequence matches.
+	emitf(w, "{")
+	emitf(w, "pt := p.pt")
+	b.emitCloneState(w)
+	var vals []string
+	if set != "" {
+		for _, expr := range seq.Exprs {
+			vals = append(vals, b.nativeVar(w, "v", b.nativeType(expr)))
+		}
+	}
+	for i, expr := range seq.Exprs {
+		if i > 0 {
+			emitf(w, "if ok {")
+		}
+		var v string
+		if set != "" {
+			v = vals[i]
+		}
+		b.writeNativeExpr(w, expr, assignTo(v))
+		if i > 0 {
+			emitf(w, "}")
+		}
+	}
+	if set != "" {
+		emitf(w, "if ok {")
+		emitSet(w, set, "[]interface{}{"+strings.Join(vals, ", ")+"}")
+		emitf(w, "} else {")
+	} else {
+		emitf(w, "if !ok {")
+	}
+	b.emitRestoreState(w)
+	emitf(w, "\tp.restore(pt)")
+	emitf(w, "}")
+	emitf(w, "}")
+}
+
+func (b *builder) writeNativeLitMatcher(w *bytes.Buffer, lit *ast.LitMatcher, set string) {
+	s := lit.Val
+	ignoreCase := ""
+	if lit.IgnoreCase {
+		s = strings.ToLower(s)
+		ignoreCase = "i"
+	}
+	want := strconv.Quote(strconv.Quote(s) + ignoreCase)
+	cur := "p.pt.rn"
+	if lit.IgnoreCase {
+		cur = "unicode.ToLower(p.pt.rn)"
+	}
+
+	emitf(w, "{")
+	emitf(w, "\tstart := p.pt")
+	if s == "" {
+		emitf(w, "\tok = true")
+	}
+	for i, rn := range s {
+		if i == 0 {
+			emitf(w, "\tok = %s == %q", cur, rn)
+			continue
+		}
+		emitf(w, "\tif ok {")
+		emitf(w, "\t\tp.read()")
+		emitf(w, "\t\tok = %s == %q", cur, rn)
+		emitf(w, "\t}")
+	}
+	emitf(w, "\tif ok {")
+	if s != "" {
+		emitf(w, "\t\tp.read()")
+	}
+	emitf(w, "\t\tp.failAt(true, start.position, %s)", want)
+	emitSet(w, set, "p.sliceFrom(start)")
+	emitf(w, "\t} else {")
+	emitf(w, "\t\tp.failAt(false, start.position, %s)", want)
+	emitf(w, "\t\tp.restore(start)")
+	emitf(w, "\t}")
+	emitf(w, "}")
+}
+
+func (b *builder) writeNativeCharClassMatcher(w *bytes.Buffer, ch *ast.CharClassMatcher, set string) {
+	lower := func(rn rune) rune {
+		if ch.IgnoreCase {
+			return unicode.ToLower(rn)
+		}
+		return rn
+	}
+
+	var conds []string
+	for _, rn := range ch.Chars {
+		conds = append(conds, fmt.Sprintf("cur == %q", lower(rn)))
+	}
+	for i := 0; i+1 < len(ch.Ranges); i += 2 {
+		conds = append(conds, fmt.Sprintf("(cur >= %q && cur <= %q)", lower(ch.Ranges[i]), lower(ch.Ranges[i+1])))
+	}
+	for _, cl := range ch.UnicodeClasses {
+		conds = append(conds, fmt.Sprintf("unicode.Is(rangeTables[%d], cur)", b.nativeClassIndex(cl)))
+	}
+	cond := "false"
+	if len(conds) > 0 {
+		cond = "(" + strings.Join(conds, " || ") + ")"
+	}
+	if ch.Inverted {
+		cond = "!" + cond
+	}
+	chVal := strconv.Quote(ch.Val)
+
+	emitf(w, "{")
+	emitf(w, "\tstart := p.pt")
+	if len(conds) > 0 {
+		if ch.IgnoreCase {
+			emitf(w, "\tcur := unicode.ToLower(p.pt.rn)")
+		} else {
+			emitf(w, "\tcur := p.pt.rn")
+		}
+	}
+	// can't match EOF, see utf8.DecodeRune
+	emitf(w, "\tok = !(p.pt.rn == utf8.RuneError && p.pt.w == 0) && %s", cond)
+	emitf(w, "\tif ok {")
+	emitf(w, "\t\tp.read()")
+	emitf(w, "\t\tp.failAt(true, start.position, %s)", chVal)
+	emitSet(w, set, "p.sliceFrom(start)")
+	emitf(w, "\t} else {")
+	emitf(w, "\t\tp.failAt(false, start.position, %s)", chVal)
+	emitf(w, "\t}")
+	emitf(w, "}")
+}
+
+// nativeType returns the Go type of the local variables that hold the value
+// of expr.
+func (b *builder) nativeType(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.AnyMatcher, *ast.CharClassMatcher, *ast.LitMatcher:
+		return "[]byte"
+	case *ast.OneOrMoreExpr, *ast.SeqExpr, *ast.ZeroOrMoreExpr:
+		return "[]interface{}"
+	case *ast.LabeledExpr:
+		return b.nativeType(expr.Expr)
+	case *ast.RecoveryExpr:
+		return b.nativeType(expr.Expr)
+	case *ast.ChoiceExpr:
+		// the value of a choice is the value of the alternative that
+		// matches, it is typed only if all the alternatives are.
+		typ := ""
+		for i, alt := range expr.Alternatives {
+			altTyp := b.nativeType(alt)
+			if i > 0 && altTyp != typ {
+				return "interface{}"
+			}
+			typ = altTyp
+		}
+		if typ != "" {
+			return typ
+		}
+	}
+	return "interface{}"
+}
+
+// pushNativeScope starts the scope of the labels of expr, and writes the
+// declaration of the variables of the labels used by the code blocks of
+// that scope.
+func (b *builder) pushNativeScope(w *bytes.Buffer, expr ast.Expression) {
+	b.pushArgsSet()
+	vars := make(map[string]nativeVar)
+	for _, lab := range usedLabels(expr) {
+		typ := b.nativeType(lab.Expr)
+		vars[lab.Label.Val] = nativeVar{name: b.nativeVar(w, lab.Label.Val, typ), typ: typ}
+	}
+	b.nativeScopes = append(b.nativeScopes, vars)
+}
+
+func (b *builder) popNativeScope() {
+	b.popArgsSet()
+	b.nativeScopes = b.nativeScopes[:len(b.nativeScopes)-1]
+}
+
+// usedLabels returns the labeled expressions of the scope of expr whose
+// label is passed to a code block, i.e. that are followed by a code block
+// in that scope. The scopes are the same as those of writeExprCode.
+func usedLabels(expr ast.Expression) []*ast.LabeledExpr {
+	var labels []*ast.LabeledExpr
+	used := 0
+	var walk func(expr ast.Expression)
+	walk = func(expr ast.Expression) {
+		switch expr := expr.(type) {
+		case *ast.ActionExpr:
+			walk(expr.Expr)
+			used = len(labels)
+		case *ast.AndCodeExpr, *ast.NotCodeExpr, *ast.StateCodeExpr:
+			used = len(labels)
+		case *ast.LabeledExpr:
+			if expr.Label != nil && expr.Label.Val != "" {
+				labels = append(labels, expr)
+			}
+		case *ast.SeqExpr:
+			for _, e := range expr.Exprs {
+				walk(e)
+			}
+		}
+	}
+	walk(expr)
+	return labels[:used]
+}
+
+// nativeArgs returns the arguments of the call of a code block, the
+// variables of the labels in its scope.
+func (b *builder) nativeArgs() string {
+	var args []string
+	vars := b.nativeScopes[len(b.nativeScopes)-1]
+	for _, arg := range b.argsStack[len(b.argsStack)-1] {
+		v := vars[arg.name]
+		if arg.typ != "" && arg.typ != v.typ {
+			args = append(args, v.name+".("+arg.typ+")")
+			continue
+		}
+		args = append(args, v.name)
+	}
+	return strings.Join(args, ", ")
+}
+
+// nativeVar writes the declaration of a new local variable of type typ of
+// the current parse method, and returns its name, prefix followed by an
+// index. The names are unique in the method, so that a variable is never
+// shadowed in the nested blocks of the sub-expressions.
+func (b *builder) nativeVar(w *bytes.Buffer, prefix, typ string) string {
+	b.nativeVarIx++
+	name := prefix + "_" + strconv.Itoa(b.nativeVarIx)
+	emitf(w, "var %s %s", name, typ)
+	b.nativeVarTypes[name] = typ
+	return name
+}
+
+// nativeClassIndex returns the index of the Unicode class cl in the
+// rangeTables variable of the generated parser.
+func (b *builder) nativeClassIndex(cl string) int {
+	for i, c := range b.nativeClasses {
+		if c == cl {
+			return i
+		}
+	}
+	b.nativeClasses = append(b.nativeClasses, cl)
+	return len(b.nativeClasses) - 1
+}
+
+func (b *builder) nativeFuncName(ix int) string {
+	return "parse" + b.ruleName + strconv.Itoa(ix)
+}
+
+func (b *builder) emitCloneState(w *bytes.Buffer) {
+	if b.globalState || !b.optimize {
+		emitf(w, "state := p.cloneState()")
+	}
+}
+
+func (b *builder) emitRestoreState(w *bytes.Buffer) {
+	if b.globalState || !b.optimize {
+		emitf(w, "p.restoreState(state)")
+	}
+}
+
+// assignTo returns the statement that sets a value to the variable v, or
+// an empty statement if v is empty.
+func assignTo(v string) string {
+	if v == "" {
+		return ""
+	}
+	return v + " = %s"
+}
+
+// emitSet writes the statement set for the value val, unless set is empty.
+func emitSet(w *bytes.Buffer, set, val string) {
+	if set != "" {
+		emitf(w, set, val)
+	}
+}
+
+// emitSetIfOk writes the statement set for the value val if the ok variable
+// is true, unless set is empty.
+func emitSetIfOk(w *bytes.Buffer, set, val string) {
+	if set != "" {
+		emitf(w, "if ok {")
+		emitf(w, set, val)
+		emitf(w, "}")
+	}
+}
+
+func emitf(w *bytes.Buffer, f string, args ...interface{}) {
+	fmt.Fprintf(w, f+"\n", args...)
+}
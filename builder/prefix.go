@@ -0,0 +1,190 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// validPrefix returns an error if prefix cannot start a Go identifier.
+func validPrefix(prefix string) error {
+	for i, rn := range prefix {
+		if rn == '_' || unicode.IsLetter(rn) || (i > 0 && unicode.IsDigit(rn)) {
+			continue
+		}
+		return fmt.Errorf("invalid identifier prefix %q", prefix)
+	}
+	return nil
+}
+
+// prefixedName returns the name of the identifier nm with prefix, keeping
+// it exported or unexported like nm, e.g. Parse becomes CalcParse and
+// parser becomes calcParser with the prefix calc.
+func prefixedName(prefix, nm string) string {
+	if ast.IsExported(nm) {
+		return changeFirst(prefix, unicode.ToUpper) + nm
+	}
+	return changeFirst(prefix, unicode.ToLower) + changeFirst(nm, unicode.ToUpper)
+}
+
+func changeFirst(s string, fn func(rune) rune) string {
+	rn, n := utf8.DecodeRuneInString(s)
+	return string(fn(rn)) + s[n:]
+}
+
+// prefixIdentifiers renames the package-level identifiers declared in the
+// generated code src with prefix. The identifiers declared by the
+// initializer of the grammar, the Go code init, are left as is, but the
+// references to the generated identifiers in the initializer and the code
+// blocks are renamed. The methods are not renamed, as their receiver types
+// are.
+func prefixIdentifiers(src []byte, init string, prefix string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{"_": true, "init": true}
+	if init != "" {
+		initf, err := parser.ParseFile(fset, "", init, 0)
+		if err != nil {
+			return nil, err
+		}
+		for nm := range initf.Scope.Objects {
+			keep[nm] = true
+		}
+	}
+	renamed := make(map[*ast.Object]string)
+	for nm, obj := range f.Scope.Objects {
+		if !keep[nm] {
+			renamed[obj] = prefixedName(prefix, nm)
+		}
+	}
+
+	// the names of the fields must not be renamed, except for the embedded
+	// fields, which are named after their renamed type.
+	fields := make(map[string]bool)
+	embedded := make(map[string]string)
+	ast.Inspect(f, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, fld := range st.Fields.List {
+			for _, nm := range fld.Names {
+				fields[nm.Name] = true
+			}
+			if len(fld.Names) == 0 {
+				typ := fld.Type
+				if star, ok := typ.(*ast.StarExpr); ok {
+					typ = star.X
+				}
+				if id, ok := typ.(*ast.Ident); ok {
+					if nm, ok := renamed[id.Obj]; ok {
+						embedded[id.Name] = nm
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	// the doc comments start with the name of what they document.
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				renameDoc(decl.Doc, decl.Name, renamed)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				doc := decl.Doc
+				if len(decl.Specs) > 1 {
+					doc = nil
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					renameDoc(doc, spec.Name, renamed)
+				case *ast.ValueSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					renameDoc(doc, spec.Names[0], renamed)
+				}
+			}
+		}
+	}
+
+	rename := func(id *ast.Ident) {
+		if nm, ok := renamed[id.Obj]; ok {
+			id.Name = nm
+		}
+	}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			if nm, ok := embedded[n.Sel.Name]; ok {
+				n.Sel.Name = nm
+			}
+			return false
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				if nm, ok := embedded[id.Name]; ok {
+					id.Name = nm
+				} else if !fields[id.Name] {
+					rename(id)
+				}
+			} else {
+				ast.Inspect(n.Key, visit)
+			}
+			ast.Inspect(n.Value, visit)
+			return false
+		case *ast.Field:
+			// the names of the fields and parameters are never package-level
+			// identifiers.
+			ast.Inspect(n.Type, visit)
+			return false
+		case *ast.Ident:
+			rename(n)
+		}
+		return true
+	}
+	ast.Inspect(f, visit)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renameDoc renames the name of id at the start of its doc comment, if id
+// is renamed.
+func renameDoc(doc *ast.CommentGroup, id *ast.Ident, renamed map[*ast.Object]string) {
+	if nm, ok := renamed[id.Obj]; ok {
+		replaceDocName(doc, id.Name, nm)
+	}
+}
+
+// replaceDocName replaces old with nm at the start of the doc comment.
+func replaceDocName(doc *ast.CommentGroup, old, nm string) {
+	if doc == nil {
+		return
+	}
+	c := doc.List[0]
+	if strings.HasPrefix(c.Text, "// "+old+" ") {
+		c.Text = "// " + nm + c.Text[len("// "+old):]
+	}
+}
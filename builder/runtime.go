@@ -0,0 +1,257 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"unicode"
+
+	pgast "github.com/mna/pigeon/ast"
+)
+
+// runtimeImport is the import of the runtime package in the parsers
+// generated in the shared runtime mode.
+const runtimeImport = `pigeonrt "github.com/mna/pigeon/runtime"`
+
+// runtimeStdImports are the packages imported by the static code.
+var runtimeStdImports = []string{
+	"bytes", "errors", "fmt", "io", "io/ioutil", "math", "os", "sort",
+	"strconv", "strings", "unicode", "unicode/utf8",
+}
+
+// runtimeExports maps the names of the types of the static code that are
+// exported by the runtime package to whether their fields are exported too.
+// The types of the grammar and their fields are used by the generated
+// parsers to declare their grammar.
+var runtimeExports = map[string]bool{
+	"actionExpr":       true,
+	"andCodeExpr":      true,
+	"andExpr":          true,
+	"anyMatcher":       true,
+	"charClassMatcher": true,
+	"choiceExpr":       true,
+	"current":          true,
+	"errList":          false,
+	"expr":             true,
+	"grammar":          true,
+	"labeledExpr":      true,
+	"litMatcher":       true,
+	"notCodeExpr":      true,
+	"notExpr":          true,
+	"oneOrMoreExpr":    true,
+	"parser":           false,
+	"parserError":      true,
+	"position":         true,
+	"recoveryExpr":     true,
+	"rule":             true,
+	"ruleRefExpr":      true,
+	"seqExpr":          true,
+	"stateCodeExpr":    true,
+	"storeDict":        false,
+	"throwExpr":        true,
+	"zeroOrMoreExpr":   true,
+	"zeroOrOneExpr":    true,
+}
+
+// runtimeCallTemplates maps the templates of the functions that call the
+// code blocks to their version in the shared runtime mode, where they are
+// functions instead of methods of the parser.
+var runtimeCallTemplates = map[string]string{
+	callFuncTemplate:      callFuncRuntimeTemplate,
+	callPredFuncTemplate:  callPredFuncRuntimeTemplate,
+	callStateFuncTemplate: callStateFuncRuntimeTemplate,
+}
+
+// BuildRuntime writes the source code of the runtime package imported by
+// the parsers generated in the shared runtime mode to w. It is the static
+// code of the parsers, with left recursion support, where the types
+// listed in runtimeExports are exported.
+func BuildRuntime(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(codeGeneratedComment + "package runtime\n\nimport (\n")
+	for _, imp := range runtimeStdImports {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString(renderStaticCode(staticCodeParams{
+		GlobalState:   true,
+		LeftRecursion: true,
+		Runtime:       true,
+	}))
+
+	src, err := exportRuntime(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// exportRuntime exports the types listed in runtimeExports in the source
+// code of the runtime package src. Unlike the identifiers of the generated
+// parsers, the fields cannot be renamed by name, so src is type-checked to
+// find the uses of each type and field.
+func exportRuntime(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	pkg, err := conf.Check("runtime", fset, []*ast.File{f}, info)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[types.Object]string)
+	for nm, fields := range runtimeExports {
+		obj := pkg.Scope().Lookup(nm)
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found in the static code", nm)
+		}
+		renamed[obj] = exportedName(nm)
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !fields || !ok {
+			continue
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			if fld := st.Field(i); !fld.Embedded() {
+				renamed[fld] = exportedName(fld.Name())
+			}
+		}
+	}
+	// the embedded fields are named after their type.
+	for _, obj := range info.Defs {
+		v, ok := obj.(*types.Var)
+		if !ok || !v.Embedded() {
+			continue
+		}
+		typ := v.Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); ok {
+			if nm, ok := renamed[named.Obj()]; ok {
+				renamed[v] = nm
+			}
+		}
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			doc := spec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if nm, ok := renamed[info.Defs[spec.Name]]; ok {
+				replaceDocName(doc, spec.Name.Name, nm)
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := info.Defs[id]
+		if obj == nil {
+			obj = info.Uses[id]
+		}
+		if nm, ok := renamed[obj]; ok {
+			id.Name = nm
+		}
+		return true
+	})
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportedName returns nm with its first letter in upper case.
+func exportedName(nm string) string {
+	return changeFirst(nm, unicode.ToUpper)
+}
+
+// addRuntimeImport adds the import of the runtime package after the package
+// clause of the initializer init.
+func addRuntimeImport(init string) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", init, parser.PackageClauseOnly)
+	if err != nil {
+		// without a package clause, the generated code is invalid anyway
+		return init
+	}
+	off := fset.Position(f.Name.End()).Offset
+	return init[:off] + "\n\nimport " + runtimeImport + "\n" + init[off:]
+}
+
+// writeRuntimeGrammar writes the grammar in the shared runtime mode: it is
+// the grammar of the default mode, declared with the exported types and
+// fields of the runtime package and with the functions that call the code
+// blocks in place of the methods of the parser.
+func (b *builder) writeRuntimeGrammar(g *pgast.Grammar) {
+	var buf bytes.Buffer
+	w := b.w
+	b.w = &buf
+	b.writeGrammar(g)
+	b.w = w
+	if b.err != nil {
+		return
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\n"+buf.String(), 0)
+	if err != nil {
+		b.err = err
+		return
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				id.Name = exportedName(id.Name)
+			}
+			// (*parser).callonRule1 becomes callonRule1
+			if sel, ok := n.Value.(*ast.SelectorExpr); ok {
+				if _, ok := sel.X.(*ast.ParenExpr); ok {
+					n.Value = sel.Sel
+				}
+			}
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			if _, ok := runtimeExports[n.Name]; ok {
+				n.Name = "pigeonrt." + exportedName(n.Name)
+			}
+		}
+		return true
+	})
+
+	buf.Reset()
+	for _, decl := range f.Decls {
+		if err := format.Node(&buf, fset, decl); err != nil {
+			b.err = err
+			return
+		}
+		buf.WriteString("\n")
+	}
+	b.writeln(buf.String())
+}
@@ -0,0 +1,100 @@
+//go:generate go run ../bootstrap/cmd/static_code_generator/main.go -- $GOFILE generated_$GOFILE runtimeCode
+
+// +build static_code
+
+package builder
+
+import (
+	"fmt"
+	"io"
+
+	pigeonrt "github.com/mna/pigeon/runtime"
+)
+
+// IMPORTANT: All code below this line is added to the parser as static code
+// the parser fails to compile with an incompatible version of the runtime
+// package.
+const _ = pigeonrt.PackageIsVersion1
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option = pigeonrt.Option
+
+// Stats stores some statistics, gathered during parsing
+type Stats = pigeonrt.Stats
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+type Cloner = pigeonrt.Cloner
+
+// The options of the parser, see the runtime package for details.
+var (
+	AllowInvalidUTF8 = pigeonrt.AllowInvalidUTF8
+	Debug            = pigeonrt.Debug
+	Entrypoint       = pigeonrt.Entrypoint
+	GlobalStore      = pigeonrt.GlobalStore
+	InitState        = pigeonrt.InitState
+	MaxExpressions   = pigeonrt.MaxExpressions
+	Memoize          = pigeonrt.Memoize
+	Recover          = pigeonrt.Recover
+	Statistics       = pigeonrt.Statistics
+)
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseFile(g, filename, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseReader(g, filename, r, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return pigeonrt.Parse(g, filename, b, opts...)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict = pigeonrt.StoreDict
+
+// newCurrent returns the context of the code block run by the parser p. The
+// stores are shared with the parser, so that changes to their content are
+// seen by the parser.
+func newCurrent(p *pigeonrt.Parser) *current {
+	c := p.Current()
+	return &current{
+		pos:         position{line: c.Pos.Line, col: c.Pos.Col, offset: c.Pos.Offset},
+		text:        c.Text,
+		state:       c.State,
+		globalStore: c.GlobalStore,
+	}
+}
@@ -0,0 +1,168 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// writeVMGrammar writes the grammar in the virtual machine mode: the
+// expressions of the rules are compiled to a program, a flat list of
+// instructions that refer to the instructions of their sub-expressions by
+// index, and each rule holds the index of the instruction of its
+// expression.
+func (b *builder) writeVMGrammar(g *ast.Grammar) {
+	ixs := make([]int, len(g.Rules))
+	for i, r := range g.Rules {
+		b.exprIndex = 0
+		b.ruleName = r.Name.Val
+		ixs[i] = b.compileExpr(r.Expr)
+	}
+
+	b.writelnf("var g = &grammar {")
+	b.writelnf("\trules: []*rule{")
+	for i, r := range g.Rules {
+		b.writelnf("{")
+		b.writelnf("\tname: %q,", r.Name.Val)
+		if r.DisplayName != nil && r.DisplayName.Val != "" {
+			b.writelnf("\tdisplayName: %q,", r.DisplayName.Val)
+		}
+		pos := r.Pos()
+		b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+		if b.haveLeftRecursion && r.Leader {
+			b.writelnf("\tleader: true,")
+		}
+		b.writelnf("\tix: %d,", ixs[i])
+		b.writelnf("},")
+	}
+	b.writelnf("\t},")
+	b.writelnf("\tprog: []instr{")
+	for i, in := range b.prog {
+		b.writelnf("// %d", i)
+		b.writeln(in)
+	}
+	b.writelnf("\t},")
+	b.writelnf("}")
+}
+
+// compileExpr compiles expr and its sub-expressions to instructions of the
+// program and returns the index of the instruction of expr. The
+// instructions are numbered in the same order as the expressions are
+// written in the default mode, so that the code blocks get the same names.
+func (b *builder) compileExpr(expr ast.Expression) int {
+	b.exprIndex++
+	ix := len(b.prog)
+	b.prog = append(b.prog, "")
+
+	var op, val string
+	var args []int
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		if expr.FuncIx == 0 {
+			expr.FuncIx = b.exprIndex
+		}
+		op, val = "opAction", "(*parser).call"+b.funcName(expr.FuncIx)
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.AndCodeExpr:
+		if expr.FuncIx == 0 {
+			expr.FuncIx = b.exprIndex
+		}
+		op, val = "opAndCode", "(*parser).call"+b.funcName(expr.FuncIx)
+	case *ast.AndExpr:
+		op = "opAnd"
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.AnyMatcher:
+		op, val = "opAny", b.captureExpr(func() { b.writeAnyMatcher(expr) })
+	case *ast.CharClassMatcher:
+		op, val = "opCharClass", b.captureExpr(func() { b.writeCharClassMatcher(expr) })
+	case *ast.ChoiceExpr:
+		pos := expr.Pos()
+		op, val = "opChoice", fmt.Sprintf("&choiceExpr{pos: position{line: %d, col: %d, offset: %d}}", pos.Line, pos.Col, pos.Off)
+		for _, alt := range expr.Alternatives {
+			args = append(args, b.compileExpr(alt))
+		}
+	case *ast.LabeledExpr:
+		op = "opLabeled"
+		val = `""`
+		if expr.Label != nil {
+			val = fmt.Sprintf("%q", expr.Label.Val)
+		}
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.LitMatcher:
+		op, val = "opLit", b.captureExpr(func() { b.writeLitMatcher(expr) })
+	case *ast.NotCodeExpr:
+		if expr.FuncIx == 0 {
+			expr.FuncIx = b.exprIndex
+		}
+		op, val = "opNotCode", "(*parser).call"+b.funcName(expr.FuncIx)
+	case *ast.NotExpr:
+		op = "opNot"
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.OneOrMoreExpr:
+		op = "opOneOrMore"
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.RecoveryExpr:
+		labels := make([]string, 0, len(expr.Labels))
+		for _, label := range expr.Labels {
+			labels = append(labels, fmt.Sprintf("%q", label))
+		}
+		op, val = "opRecovery", "[]string{"+strings.Join(labels, ", ")+"}"
+		args = append(args, b.compileExpr(expr.Expr), b.compileExpr(expr.RecoverExpr))
+	case *ast.RuleRefExpr:
+		op, val = "opRuleRef", fmt.Sprintf("%q", expr.Name.Val)
+	case *ast.SeqExpr:
+		op = "opSeq"
+		for _, e := range expr.Exprs {
+			args = append(args, b.compileExpr(e))
+		}
+	case *ast.StateCodeExpr:
+		b.globalState = true
+		if expr.FuncIx == 0 {
+			expr.FuncIx = b.exprIndex
+		}
+		op, val = "opStateCode", "(*parser).call"+b.funcName(expr.FuncIx)
+	case *ast.ThrowExpr:
+		op, val = "opThrow", fmt.Sprintf("%q", expr.Label)
+	case *ast.ZeroOrMoreExpr:
+		op = "opZeroOrMore"
+		args = append(args, b.compileExpr(expr.Expr))
+	case *ast.ZeroOrOneExpr:
+		op = "opZeroOrOne"
+		args = append(args, b.compileExpr(expr.Expr))
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+
+	var buf bytes.Buffer
+	pos := expr.Pos()
+	fmt.Fprintf(&buf, "{op: %s, pos: position{line: %d, col: %d, offset: %d}", op, pos.Line, pos.Col, pos.Off)
+	if len(args) > 0 {
+		buf.WriteString(", args: []int{")
+		for i, arg := range args {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%d", arg)
+		}
+		buf.WriteString("}")
+	}
+	if val != "" {
+		buf.WriteString(", val: " + val)
+	}
+	buf.WriteString("},")
+	b.prog[ix] = buf.String()
+	return ix
+}
+
+// captureExpr returns what fn writes for an expression in the default mode,
+// without the trailing comma.
+func (b *builder) captureExpr(fn func()) string {
+	var buf bytes.Buffer
+	w := b.w
+	b.w = &buf
+	fn()
+	b.w = w
+	return strings.TrimSuffix(strings.TrimSpace(buf.String()), ",")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -19,6 +20,22 @@ func compareGrammars(t *testing.T, src string, exp, got *ast.Grammar) bool {
 		}
 	}
 
+	dn, dm := len(exp.Directives), len(got.Directives)
+	if dn != dm {
+		t.Errorf("%q: want %d directives, got %d", src, dn, dm)
+		return false
+	}
+	for i, d := range got.Directives {
+		if exp.Directives[i].Name.Val != d.Name.Val {
+			t.Errorf("%q: want directive %q, got %q", src, exp.Directives[i].Name.Val, d.Name.Val)
+			return false
+		}
+		if !reflect.DeepEqual(exp.Directives[i].Args, d.Args) {
+			t.Errorf("%q: want directive arguments %q, got %q", src, exp.Directives[i].Args, d.Args)
+			return false
+		}
+	}
+
 	rn, rm := len(exp.Rules), len(got.Rules)
 	if rn != rm {
 		t.Errorf("%q: want %d rules, got %d", src, rn, rm)
@@ -49,6 +66,21 @@ func compareRule(t *testing.T, prefix string, exp, got *ast.Rule) bool {
 			return false
 		}
 	}
+	if exp.Type != got.Type {
+		t.Errorf("%q: want Type %q, got %q", prefix, exp.Type, got.Type)
+		return false
+	}
+	pn, pm := len(exp.Params), len(got.Params)
+	if pn != pm {
+		t.Errorf("%q: want %d Params, got %d", prefix, pn, pm)
+		return false
+	}
+	for i, p := range got.Params {
+		if exp.Params[i].Val != p.Val {
+			t.Errorf("%q: want param %q, got %q", prefix, exp.Params[i].Val, p.Val)
+			return false
+		}
+	}
 	return compareExpr(t, prefix, 0, exp.Expr, got.Expr)
 }
 
@@ -262,6 +294,17 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 				return false
 			}
 		}
+		ne, ng := len(exp.Args), len(got.Args)
+		if ne != ng {
+			t.Errorf("%q: want %d Args, got %d", ixPrefix, ne, ng)
+			return false
+		}
+
+		for i, expr := range exp.Args {
+			if !compareExpr(t, prefix, ix+1, expr, got.Args[i]) {
+				return false
+			}
+		}
 
 	case *ast.SeqExpr:
 		got, ok := got.(*ast.SeqExpr)
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// severities of the diagnostics.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// codes of the diagnostics that are not validation errors of the ast
+// package.
+const (
+	// codeParseError is the code of the errors raised while parsing the
+	// grammar.
+	codeParseError = "parse-error"
+
+	// codeUnknownEntrypoint is the code of the alternate entrypoints that
+	// are not rules of the grammar.
+	codeUnknownEntrypoint = "unknown-entrypoint"
+
+	// codeBuildError is the code of the errors raised while building the
+	// parser.
+	codeBuildError = "build-error"
+)
+
+// codedError is an error that has no position in the grammar, with the
+// code of its diagnostic.
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+// diagnostic is an error or warning found in the grammar.
+type diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Offset   int    `json:"offset"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// diagnostics reports the errors and warnings found in the grammar. If
+// format is empty, they are written as free text as soon as they are
+// reported, otherwise they are collected and written in the requested
+// machine-readable format when flush is called.
+type diagnostics struct {
+	w      io.Writer
+	format string
+	file   string
+	list   []diagnostic
+}
+
+// validDiagnosticsFormat returns true if format is a supported value for
+// the -diagnostics-format flag.
+func validDiagnosticsFormat(format string) bool {
+	switch format {
+	case "", "json", "sarif":
+		return true
+	}
+	return false
+}
+
+// report reports the errors in err with the given severity. The title
+// introduces the errors when written as free text.
+func (d *diagnostics) report(title, severity string, err error) {
+	if d.format == "" {
+		fmt.Fprintln(d.w, title+":\n", err)
+		return
+	}
+
+	var errs []error
+	switch err := err.(type) {
+	case errList:
+		errs = err
+	case ast.ValidationErrors:
+		for _, e := range err {
+			errs = append(errs, e)
+		}
+	default:
+		errs = []error{err}
+	}
+
+	for _, err := range errs {
+		diag := diagnostic{File: d.file, Severity: severity}
+		switch err := err.(type) {
+		case *parserError:
+			diag.Line, diag.Column, diag.Offset = err.pos.line, err.pos.col, err.pos.offset
+			diag.Code = codeParseError
+			diag.Message = err.Inner.Error()
+		case *codedError:
+			diag.Code = err.code
+			diag.Message = err.Error()
+		case *ast.ValidationError:
+			diag.Line, diag.Column, diag.Offset = err.Pos.Line, err.Pos.Col, err.Pos.Off
+			if err.Pos.Filename != "" {
+				diag.File = err.Pos.Filename
+			}
+			diag.Code = err.Code
+			diag.Message = err.Msg
+		default:
+			diag.Code = codeParseError
+			diag.Message = err.Error()
+		}
+		d.list = append(d.list, diag)
+	}
+}
+
+// flush writes the diagnostics collected so far in the machine-readable
+// format, if any.
+func (d *diagnostics) flush() error {
+	var v interface{}
+	switch d.format {
+	case "json":
+		v = struct {
+			Diagnostics []diagnostic `json:"diagnostics"`
+		}{d.list}
+	case "sarif":
+		v = d.sarif()
+	default:
+		return nil
+	}
+
+	enc := json.NewEncoder(d.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// SARIF 2.1.0 log, only the properties used by pigeon are defined.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	ByteOffset  int `json:"byteOffset"`
+}
+
+// sarif converts the diagnostics to a SARIF log.
+func (d *diagnostics) sarif() sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "pigeon",
+				InformationURI: "https://github.com/mna/pigeon",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, diag := range d.list {
+		if !rules[diag.Code] {
+			rules[diag.Code] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: diag.Code})
+		}
+
+		loc := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: diag.File},
+			},
+		}
+		// SARIF lines start at 1, errors without a position have no region
+		if diag.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   diag.Line,
+				StartColumn: diag.Column,
+				ByteOffset:  diag.Offset,
+			}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    diag.Code,
+			Level:     diag.Severity,
+			Message:   sarifMessage{Text: diag.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
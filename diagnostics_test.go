@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func testDiagnostics(format string) (*diagnostics, *bytes.Buffer) {
+	var buf bytes.Buffer
+	d := &diagnostics{w: &buf, format: format, file: "test.peg"}
+	d.report("parse error(s)", severityError, errList{
+		&parserError{Inner: errors.New("no match found"), pos: position{line: 2, col: 3, offset: 10}},
+	})
+	d.report("warning(s)", severityWarning, ast.ValidationErrors{
+		{Pos: ast.Pos{Line: 4, Col: 1, Off: 20}, Code: ast.CodeUnusedRule, Msg: "unused rule: A"},
+	})
+	return d, &buf
+}
+
+func TestDiagnosticsText(t *testing.T) {
+	d, buf := testDiagnostics("")
+	if err := d.flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "parse error(s):\n : no match found\nwarning(s):\n 4:1 (20): unused rule: A\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	d, buf := testDiagnostics("json")
+	if buf.Len() > 0 {
+		t.Fatalf("want no output before flush, got %q", buf.String())
+	}
+	if err := d.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Diagnostics []diagnostic
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []diagnostic{
+		{File: "test.peg", Line: 2, Column: 3, Offset: 10, Severity: "error", Code: "parse-error", Message: "no match found"},
+		{File: "test.peg", Line: 4, Column: 1, Offset: 20, Severity: "warning", Code: "unused-rule", Message: "unused rule: A"},
+	}
+	if !reflect.DeepEqual(got.Diagnostics, want) {
+		t.Errorf("want %#v, got %#v", want, got.Diagnostics)
+	}
+}
+
+func TestDiagnosticsSARIF(t *testing.T) {
+	d, buf := testDiagnostics("sarif")
+	d.report("build error", severityError, errors.New("no position"))
+	if err := d.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("want 1 run of a version 2.1.0 log, got %+v", got)
+	}
+
+	run := got.Runs[0]
+	wantRules := []sarifRule{{ID: "parse-error"}, {ID: "unused-rule"}}
+	if !reflect.DeepEqual(run.Tool.Driver.Rules, wantRules) {
+		t.Errorf("want rules %v, got %v", wantRules, run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(run.Results))
+	}
+
+	res := run.Results[1]
+	if res.RuleID != "unused-rule" || res.Level != "warning" || res.Message.Text != "unused rule: A" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	wantRegion := &sarifRegion{StartLine: 4, StartColumn: 1, ByteOffset: 20}
+	if loc := res.Locations[0].PhysicalLocation; loc.ArtifactLocation.URI != "test.peg" || !reflect.DeepEqual(loc.Region, wantRegion) {
+		t.Errorf("want location test.peg %v, got %+v", wantRegion, loc)
+	}
+	if region := run.Results[2].Locations[0].PhysicalLocation.Region; region != nil {
+		t.Errorf("want no region for an error without position, got %+v", region)
+	}
+}
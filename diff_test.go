@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) []byte {
+		return []byte(strings.Join(strings.Fields(s), "\n") + "\n")
+	}
+
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{a: "a b c", b: "a b c", want: ""},
+		{a: "a b c", b: "a x c", want: "@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{a: "", b: "a", want: "@@ -0,0 +1 @@\n+a\n"},
+		{a: "a b", b: "b", want: "@@ -1,2 +1 @@\n-a\n b\n"},
+		{
+			// distant changes are in separate hunks
+			a:    "1 2 3 4 5 6 7 8 9 10 11 12 13 14 15",
+			b:    "x 2 3 4 5 6 7 8 9 10 11 12 13 14 y",
+			want: "@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -12,4 +12,4 @@\n 12\n 13\n 14\n-15\n+y\n",
+		},
+		{
+			// close changes are in the same hunk
+			a:    "1 2 3 4 5 6 7 8 9",
+			b:    "x 2 3 4 5 6 7 y 9",
+			want: "@@ -1,9 +1,9 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n 9\n",
+		},
+	}
+	for _, tc := range cases {
+		var a, b []byte
+		if tc.a != "" {
+			a = lines(tc.a)
+		}
+		if tc.b != "" {
+			b = lines(tc.b)
+		}
+		want := tc.want
+		if want != "" {
+			want = "--- a.go\n+++ b.go\n" + want
+		}
+		if got := unifiedDiff("a.go", "b.go", a, b); got != want {
+			t.Errorf("%q -> %q: want\n%s\ngot\n%s", tc.a, tc.b, want, got)
+		}
+	}
+
+	got := unifiedDiff("a.go", "b.go", []byte("a\nb"), []byte("a\nc"))
+	want := "--- a.go\n+++ b.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n"
+	if got != want {
+		t.Errorf("without final newline: want\n%s\ngot\n%s", want, got)
+	}
+}
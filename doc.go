@@ -33,8 +33,45 @@ The following options can be specified:
 	pathological cases. Can make the parsing slower for typical
 	cases and uses more memory (default: false).
 
+	-check : boolean, if set, the parser is generated again in memory and
+	compared to the existing output file set by -o instead of being written
+	to it. See the Up-to-date check section below for details (default:
+	false).
+
 	-debug : boolean, print debugging info to stdout (default: false).
 
+	-diagnostics-format=FORMAT : string, if set, the errors and warnings found
+	in the grammar, including the unknown alternate entrypoints and the errors
+	raised while building the parser, are written to stderr in a
+	machine-readable format instead of free text. FORMAT is either "json" or
+	"sarif" (SARIF 2.1.0). Each diagnostic has the file, line, column, byte
+	offset, severity ("error" or "warning"), a stable code identifying the
+	kind of diagnostic (e.g. "undefined-rule", "parse-error",
+	"unknown-entrypoint" or "build-error") and the message (default: none).
+	The line, column and offset are 0 if the diagnostic has no position in
+	the grammar.
+
+	-identifier-prefix=PREFIX : string, if set, the package-level identifiers
+	of the generated parser, e.g. Parse, Option or parser, are prefixed with
+	PREFIX so that several parsers can be generated in the same package. See
+	the Identifier prefix section below for details (default: none).
+
+	-infer-label-types : boolean, if set, the labels bound to terminals are
+	passed to the code blocks as []byte, and the labels bound to sequences and
+	repetitions as []interface{}, instead of interface{}. See the Labeled
+	expression section below for details (default: false).
+
+	-line-directives : boolean, if set, the code blocks of the grammar are
+	surrounded by //line directives, so that the compiler errors, panics and
+	coverage profiles refer to the grammar file instead of the generated
+	parser. The input grammar must be a file. See the Line directives section
+	below for details (default: false).
+
+	-native-functions : boolean, if set, each rule of the grammar is
+	compiled to a method of the parser instead of a table of expressions
+	interpreted at runtime. See the Native functions section below for
+	details (default: false).
+
 	-nolint: add '// nolint: ...' comments for generated parser to suppress
 	warnings by gometalinter (https://github.com/alecthomas/gometalinter).
 
@@ -81,6 +118,27 @@ The following options can be specified:
 	code blocks. Non-initializer code blocks in the grammar end up as methods on the
 	*current type, and this option sets the name of the receiver (default: c).
 
+	-shared-runtime : boolean, if set, the generated parser contains only the
+	grammar and its code blocks, and imports the parser, its options and
+	errors from the github.com/mna/pigeon/runtime package. See the Shared
+	runtime section below for details (default: false).
+
+	-support-left-recursion : boolean, if set, left-recursive rules are allowed
+	in the grammar instead of being reported as errors. See the Left recursion
+	section below for details (default: false).
+
+	-vm : boolean, if set, the grammar is compiled to a program run by a
+	virtual machine with an explicit stack instead of recursive calls. See the
+	Virtual machine section below for details (default: false).
+
+	-warnings-as-errors : boolean, if set, the warnings reported for the grammar
+	are treated as errors and no parser is generated. Warnings are reported for
+	repetitions of expressions that can match empty input, for choice
+	alternatives that can never match, for failure labels that are thrown
+	but never recovered or recovered but never thrown and for rules that
+	cannot be reached from the first rule of the grammar or from any of the
+	alternate entrypoints (default: false).
+
 	-alternate-entrypoints=RULE[,RULE...] : string, comma-separated list of rule names
 	that may be used as alternate entrypoints for the parser, in addition to the
 	default entrypoint (the first rule in the grammar) (default: none).
@@ -94,7 +152,7 @@ and go vet-compliant, then the resulting generated code will also be golint-
 and go vet-compliant.
 
 The generated code doesn't use any third-party dependency unless code blocks
-in the grammar require such a dependency.
+in the grammar require such a dependency, or the -shared-runtime flag is set.
 
 PEG syntax
 
@@ -129,11 +187,258 @@ be specified after the rule identifier. E.g.:
 The rule definition operator can be any one of those:
 	=, <-, ← (U+2190), ⟵ (U+27F5)
 
+A rule must not be left-recursive, that is it must not reference itself,
+directly or through other rules, before any input is consumed. Such a rule
+would recurse infinitely in the generated parser, so the pigeon tool reports
+every left-recursive cycle as an error. E.g.:
+	Expr = Expr "+" Term / Term // Expr is left-recursive
+
+Directives
+
+Directives start with "@" followed by the name of the directive and a list
+of arguments up to the end of the line, each argument being either a
+string literal or a sequence of non-whitespace characters. They may appear
+before and after the initializer and between the rules. The "@import"
+directive imports another grammar file, see the Imports section below. The
+"@option" directive sets a command-line option from the grammar, so that it
+does not need to be repeated on every invocation. E.g.:
+	@option nolint
+	@option receiver-name self
+	@option alternate-entrypoints RuleA RuleB
+
+Boolean options are set to true if no value is given. The options that can
+be set this way are -alternate-entrypoints, -identifier-prefix,
+-infer-label-types, -line-directives, -native-functions, -nolint,
+-optimize-basic-latin, -optimize-grammar, -optimize-parser,
+-receiver-name, -shared-runtime, -support-left-recursion, -vm and
+-warnings-as-errors. The options set on the command line take precedence
+over the directives. Unknown directives and options are reported as errors.
+The directives are read the same way by builder.BuildParser, so that the
+programs that generate parsers with the builder package get the same
+parser as the pigeon command.
+
+Imports
+
+A grammar may be split in several files with "@import" directives, whose
+only argument is the name of the imported file, relative to the directory
+of the file that imports it. E.g.:
+	@import "lexer/tokens.peg"
+
+The rules, directives and initializer of the imported file are merged into
+the grammar, and the imported file may itself import other files. Each file
+is imported once, even if several files import it. The rules of the
+imported files come after those of the grammar, so the entrypoint of the
+parser is still the first rule of the grammar, and duplicate rule names are
+reported as errors across files. The initializers are merged in a single
+one: the initializer of the grammar comes first, with its package clause,
+followed by the imports of the other initializers, then by their other
+declarations. The package clause of the initializer of an imported file is
+optional and ignored. The positions of the errors and warnings, and those of
+the //line directives, refer to the file that declares the element.
+
+Up-to-date check
+
+The options that affect the generated parser and that are set on the
+command line are recorded in the header of the generated parser, e.g.:
+	// Code generated by pigeon; DO NOT EDIT.
+	// pigeon options: -nolint -receiver-name=self
+
+When the -check flag is set, pigeon generates the parser again in memory
+with the recorded options, the options set on the command line taking
+precedence, and compares it to the output file set by -o instead of
+writing it. If they differ, e.g. because the grammar or pigeon changed
+since the parser was generated, a unified diff is printed to stdout and
+pigeon exits with the status code 11. This can be used to verify that the
+generated parsers stored in version control are up to date:
+	pigeon -check -o parser.go grammar.peg
+
+The generated code depends only on the grammar, the options and the
+version of pigeon, but it is formatted with the installed version of the
+Go tools, which may change its formatting.
+
+Parsing without generating
+
+When the -parse flag is set, pigeon parses the input file with the grammar
+instead of generating the parser, and prints the parse tree to stdout: a
+line per rule that matched, with its position, the offset of the end of
+the match and the matched text, indented under the rule that referenced
+it. If the input does not match, the errors are those that the generated
+parser would return, and pigeon exits with the status code 12:
+	pigeon -parse input.txt grammar.peg
+
+The code blocks of the grammar are not run: the actions are ignored, the
+predicate code blocks always succeed and the state code blocks do
+nothing. The github.com/mna/pigeon/interp package provides the same
+interpretation of a grammar to Go programs.
+
+Random inputs
+
+The -generate-samples flag prints random inputs accepted by the first rule
+of the grammar instead of generating the parser, e.g. to test the consumers
+of the parser with valid inputs at scale:
+	pigeon -generate-samples 100 grammar.peg
+
+The inputs are printed one per line as Go string literals. They are
+generated by picking at random the alternatives of the choices, the number
+of repetitions, the members of the character classes and the case of the
+literals that ignore it, and the shortest alternatives past a maximum
+depth of rules. Each input is then parsed by interpreting the grammar the
+same way as the -parse flag, and it is generated again if it does not
+match entirely, e.g. if it does not satisfy an and or not predicate. The
+-samples-seed flag sets the seed of the random inputs, so that they can be
+generated again. If no input is accepted after many attempts, pigeon
+exits with the status code 14. The github.com/mna/pigeon/sample package
+generates the inputs from Go programs.
+
+Grammar tests
+
+Test cases may be declared next to the rules with "@test" directives,
+followed by the name of the rule, "accepts" or "rejects", and the input:
+	@test Number accepts "1.5e3"
+	@test Number rejects "01"
+
+The test command runs them by interpreting the grammar the same way as the
+-parse flag, starting each test at its rule as if it was an alternate
+entrypoint:
+	pigeon test grammar.peg
+
+A rule accepts an input if it matches all of it. The failed tests are
+printed with the position of the directive and the error of the parser,
+and pigeon exits with the status code 13 if any test fails. The @test
+directives are ignored when the parser is generated.
+
+Fuzz tests
+
+The -fuzz-test flag writes a test file with native Go fuzz tests for the
+generated parser, in addition to the parser:
+	pigeon -o parser.go -fuzz-test parser_fuzz_test.go grammar.peg
+
+There is a fuzz test for each entrypoint of the parser, FuzzParse for the
+first rule of the grammar and FuzzParse followed by the rule name for each
+of the alternate entrypoints, e.g. FuzzParseExpr. The corpus is seeded with
+the files of the directory set by -fuzz-corpus, "testdata" by default, so
+that the examples run as regular tests with "go test":
+	go test -fuzz FuzzParse
+
+The inputs are parsed with Recover(false) and MaxExpressions set by
+-fuzz-max-expressions, so that a panic or an input that exceeds the limit
+fails the fuzz test with its stack trace. An error without a position
+fails it too.
+
+Identifier prefix
+
+When the -identifier-prefix flag is set, the package-level identifiers of
+the generated parser are renamed with the prefix, keeping them exported or
+unexported: with the prefix "calc", Parse becomes CalcParse, ParseFile
+becomes CalcParseFile, Option becomes CalcOption and the unexported parser
+type becomes calcParser. The methods are not renamed, as their receiver
+types are, so the code blocks of the grammar end up as methods on the
+*calcCurrent type. The identifiers declared by the initializer of the
+grammar are left as is, so they must not collide with those of the other
+parsers of the package. Hand-written code of the package that refers to the
+generated identifiers must use the prefixed names.
+
+Shared runtime
+
+By default, each generated parser contains its own copy of the parser
+code, its options and errors. When the -shared-runtime flag is set, the
+generated parser contains only the grammar and its code blocks, and
+imports them from the github.com/mna/pigeon/runtime package under the
+name pigeonrt, so that a fix of the runtime does not require generating
+the parsers again. The
+generated parser has the same API, e.g. Parse, ParseFile, Option and
+Debug, and the code blocks have the same c.text, c.pos, c.state and
+c.globalStore. The errors returned by the parser are a runtime.ErrList of
+*runtime.ParserError values, whose Pos and Expected fields are exported.
+
+The runtime package is versioned: a generated parser references the
+runtime.PackageIsVersion1 constant, so that it fails to compile with a
+version of the package it is not compatible with. The shared runtime mode
+cannot be combined with the -native-functions, -vm, -optimize-parser and
+-optimize-basic-latin flags.
+
+Line directives
+
+When the -line-directives flag is set, each code block of the grammar is
+preceded by a //line directive that maps it to its position in the grammar
+and followed by a //line directive that maps the rest of the code back to
+the generated file. The declarations of the initializer are mapped the same
+way. The name of the grammar in the directives is relative to the directory
+of the generated file, e.g.:
+	//line calc.peg:14:1
+
+When the parser is written to stdout, the generated file is assumed to be
+in the directory of the grammar, with the name of the grammar and the .go
+extension.
+
+Left recursion
+
+When the -support-left-recursion flag is set, left-recursive rules are
+allowed. For each group of rules that reference each other before any input
+is consumed, one rule is designated as the leader of the group: the first
+rule in grammar order that is part of every left-recursive cycle of the
+group. The generated parser grows the result of the leader: it first
+matches the rule as if the left-recursive reference failed, then matches it
+again with the previous result in place of the left-recursive reference, as
+long as more input is consumed. This makes the usual definition of
+left-associative operators possible:
+	Expr = a:Expr "-" b:Term { return a.(int) - b.(int), nil } / Term
+
+The results of the leaders are memoized, even if the Memoize option is not
+set. A group of rules without a rule that is part of every cycle is reported
+as an error.
+
+Typed rules
+
+The Go type of the value returned by a rule can be specified between angle
+brackets after the rule identifier, before the optional display name. E.g.:
+	Number <int> "number" = [0-9]+ { return strconv.Atoi(string(c.text)) }
+
+The code blocks of the actions that produce the value of a typed rule - the
+actions at the top of the rule's expression or of its choice alternatives -
+return that type instead of interface{}, and the labels bound to a typed
+rule are arguments of that type in all code blocks, instead of interface{}.
+E.g.:
+	Sum <int> = a:Number '+' b:Number { return a + b, nil }
+
+The expression of a typed rule must always produce a value of that type,
+otherwise the generated parser panics when it passes that value to a code
+block. Typed rules are never inlined when the grammar is optimized. If the
+first rule of the grammar is typed, the generated parser also has a
+ParseTyped function that works like Parse but returns the result with the
+type of that rule. The type must not start with "-", so that it is not
+confused with the "<-" rule definition operator.
+
+Parameterized rules
+
+A rule may take parameters, listed between angle brackets right after the
+rule identifier, without whitespace in between. The space is what tells
+the parameters from the type of a typed rule: Start<int> is a rule with a
+parameter named int, while Start <int> is a rule of type int. The
+parameters are used in the rule's expression like rule references. The
+references to a parameterized rule list an expression for each parameter,
+also between angle brackets right after the identifier. E.g.:
+	List<Item, Sep> = first:Item rest:( Sep Item )*
+	Args = '(' List<Expr, ( _ ',' _ )> ')'
+
+Parameterized rules are expanded before the grammar is validated: for each
+distinct list of arguments, a copy of the rule named after the rule and
+the number of the copy, e.g. List_1, replaces the references to the rule
+with those arguments. The code blocks of each copy are generated as
+distinct functions, so the labels of a copy are bound to the values of its
+arguments. A parameterized rule that is never used, or that is the first
+rule of the grammar or an alternate entrypoint, is an error, as it cannot
+be parsed without arguments. The error hints at the type of the rule when
+the rule has a single parameter, as the space before the type may be
+missing.
+
 Expressions
 
 A rule is defined by an expression. The following sections describe the
 various expression types. Expressions can be grouped by using parentheses,
 and a rule can be referenced by its identifier in place of an expression.
+Referencing a rule that is not defined in the grammar is an error reported
+by the pigeon tool before the parser is generated.
 
 Choice expression
 
@@ -147,6 +452,11 @@ of expressions. For example, in this rule, "<=" would never be used because
 the "<" expression comes first:
 	BadChoiceExpr = "<" / "<="
 
+The pigeon tool reports a warning for such alternatives that can never
+match, when an earlier literal or character class always matches the start
+of a later literal, or when an earlier alternative always succeeds, e.g.
+"a"? / "b".
+
 Sequence expression
 
 The sequence expression is a list of expressions that must all match in
@@ -166,6 +476,10 @@ E.g.:
 		return value, nil
 	}
 
+A label must be unique in its scope, a label defined more than once in the
+same scope is an error and no parser is generated. Likewise, a rule must
+not be defined more than once in a grammar.
+
 The variable is typed as an empty interface, and the underlying type depends
 on the following:
 
@@ -200,6 +514,14 @@ is used. E.g.:
 	}
 	RuleB = label:RuleA { // label is int }
 
+When the -infer-label-types flag is set, the variables of the labels
+bound to terminals, sequences and repetitions have the corresponding
+concrete type instead of the empty interface, so that no type assertion is
+needed. Labels bound to typed rules always have the type of the rule, see
+the Typed rules section. The labels bound to and and not expressions stay
+empty interfaces, as their value is always nil. E.g.:
+	Rule = label:[a-z]+ { // label is []interface{}, not interface{} }
+
 And and not expressions
 
 An expression prefixed with the ampersand "&" is the "and" predicate
@@ -230,6 +552,10 @@ occurs zero or more times ("*"), zero or one time "?" or one or more times
 possible. E.g.
 	ZeroOrMoreAs = "A"*
 
+If the repeated expression matches without consuming any input, e.g.
+("A"?)*, the repetition stops, as it would otherwise never end. The pigeon
+tool reports such repetitions as warnings.
+
 Literal matcher
 
 A literal matcher tries to match the input against a single character or a
@@ -419,6 +745,11 @@ this expression is successful, the parser continues the processing of the input.
 the recovery expression is not successful, the parsing fails and the parser starts
 to backtrack.
 
+The pigeon tool follows the rule references from the entrypoints of the grammar
+to find the failure labels that may be recovered at each throw expression. It
+reports a warning for a throw expression whose label can never be recovered, and
+for a label of a recover expression that is never thrown while it is active.
+
 If throw and recover expressions are used together with global state, it is the
 responsibility of the author of the grammar to reset the global state to a valid
 state during the recovery operation.
@@ -442,6 +773,10 @@ as a package with public functions to parse input text. The exported API is:
 	- Recover(bool) Option
 	- Statistics(*Stats) Option
 
+If the first rule of the grammar is typed, the exported API also has:
+	- ParseTyped(string, []byte, ...Option) (T, error)
+where T is the type of that rule.
+
 See the godoc page of the generated parser for the test/predicates grammar
 for an example documentation page of the exported API:
 http://godoc.org/github.com/mna/pigeon/test/predicates.
@@ -459,6 +794,40 @@ but for simple grammars it may evaluate the result immediately, such as in
 the examples/calculator example. There are no constraints imposed on the
 author of the grammar, it can return whatever is needed.
 
+Native functions
+
+By default, the generated parser holds the grammar as a table of
+expressions, and parses the input by interpreting that table. When the
+-native-functions flag is set, each rule is instead compiled to a single
+method of the parser, in which all the expressions of the rule are inlined
+as straight-line Go code, with no dispatch on the type of the expressions.
+The values of the literals, character classes and the any matcher are
+[]byte variables, those of the sequences and repetitions are []interface{}
+variables, and they are only converted to an empty interface when stored in
+one. The labels are local variables of the method, and the code blocks are
+called directly with them instead of through the values stack of the
+parser. The recover expressions of the failure labels get their own method.
+
+The exported API, the results and the errors of the generated parser are
+the same in both modes, and the generated parser is typically about twice
+as fast and allocates less, e.g. on the examples/json grammar. The
+-optimize-parser flag can be combined with -native-functions for a larger
+speedup. The other options can be combined with -native-functions too,
+except -optimize-basic-latin which has no effect. The results are only
+memoized at the rule level when the Memoize option is set.
+
+Virtual machine
+
+The default parser, like the one generated with -native-functions, parses
+nested expressions and rules with recursive calls, so a deeply nested input
+(e.g. 100000 nested JSON arrays) may exhaust the goroutine stack. When the
+-vm flag is set, the grammar is compiled to a program, one instruction per
+expression, run by a virtual machine that keeps track of the expressions
+and rules being parsed on an explicit stack on the heap. The exported API,
+the results and the errors of the generated parser are the same as with the
+default parser, but parsing is somewhat slower. The -vm and
+-native-functions flags cannot be combined.
+
 Error reporting
 
 When the parser returns a non-nil error, the error is always of type errList,
@@ -1,4 +1,5 @@
 // Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -nolint
 
 package main
 
@@ -1940,6 +1941,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
@@ -1950,6 +1952,14 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 			}
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -2040,12 +2050,21 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
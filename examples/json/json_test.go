@@ -5,10 +5,15 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	native "github.com/mna/pigeon/examples/json/native"
 	optimized "github.com/mna/pigeon/examples/json/optimized"
 	optimizedgrammar "github.com/mna/pigeon/examples/json/optimized-grammar"
+	sharedruntime "github.com/mna/pigeon/examples/json/runtime"
+	vm "github.com/mna/pigeon/examples/json/vm"
+	pigeonrt "github.com/mna/pigeon/runtime"
 )
 
 func TestCmpStdlib(t *testing.T) {
@@ -32,6 +37,24 @@ func TestCmpStdlib(t *testing.T) {
 			continue
 		}
 
+		pngot, err := native.ParseFile(file)
+		if err != nil {
+			t.Errorf("%s: native.ParseFile: %v", file, err)
+			continue
+		}
+
+		pvgot, err := vm.ParseFile(file)
+		if err != nil {
+			t.Errorf("%s: vm.ParseFile: %v", file, err)
+			continue
+		}
+
+		prgot, err := sharedruntime.ParseFile(file)
+		if err != nil {
+			t.Errorf("%s: sharedruntime.ParseFile: %v", file, err)
+			continue
+		}
+
 		b, err := ioutil.ReadFile(file)
 		if err != nil {
 			t.Errorf("%s: ioutil.ReadAll: %v", file, err)
@@ -57,6 +80,76 @@ func TestCmpStdlib(t *testing.T) {
 			t.Errorf("%s: optimized grammar not equal", file)
 			continue
 		}
+
+		if !reflect.DeepEqual(pngot, jgot) {
+			t.Errorf("%s: native not equal", file)
+			continue
+		}
+
+		if !reflect.DeepEqual(pvgot, jgot) {
+			t.Errorf("%s: vm not equal", file)
+			continue
+		}
+
+		if !reflect.DeepEqual(prgot, jgot) {
+			t.Errorf("%s: shared runtime not equal", file)
+			continue
+		}
+	}
+}
+
+func TestVariantErrors(t *testing.T) {
+	inputs := []string{
+		``,
+		`{`,
+		`{"a": }`,
+		`[1, 2,]`,
+		`tru`,
+		`"abc`,
+		`"\x"`,
+		`{"a": 1} x`,
+	}
+	for _, in := range inputs {
+		_, err := Parse("", []byte(in))
+		if err == nil {
+			t.Errorf("%q: want error, got none", in)
+			continue
+		}
+		_, nerr := native.Parse("", []byte(in))
+		if nerr == nil || nerr.Error() != err.Error() {
+			t.Errorf("%q: native: want error %v, got %v", in, err, nerr)
+		}
+		_, verr := vm.Parse("", []byte(in))
+		if verr == nil || verr.Error() != err.Error() {
+			t.Errorf("%q: vm: want error %v, got %v", in, err, verr)
+		}
+		_, rerr := sharedruntime.Parse("", []byte(in))
+		if rerr == nil || rerr.Error() != err.Error() {
+			t.Errorf("%q: shared runtime: want error %v, got %v", in, err, rerr)
+		}
+		if _, ok := rerr.(pigeonrt.ErrList); !ok {
+			t.Errorf("%q: shared runtime: want error of type %T, got %T", in, pigeonrt.ErrList{}, rerr)
+		}
+	}
+}
+
+func TestVMDeepNesting(t *testing.T) {
+	const depth = 100000
+
+	in := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+	got, err := vm.Parse("", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < depth-1; i++ {
+		arr, ok := got.([]interface{})
+		if !ok || len(arr) != 1 {
+			t.Fatalf("depth %d: want an array of 1 value, got %#v", i, got)
+		}
+		got = arr[0]
+	}
+	if arr, ok := got.([]interface{}); !ok || len(arr) != 0 {
+		t.Fatalf("depth %d: want an empty array, got %#v", depth-1, got)
 	}
 }
 
@@ -129,6 +222,33 @@ func TestChoiceAltStatistics(t *testing.T) {
 		if !reflect.DeepEqual(test.expectedStats, stats.ChoiceAltCnt) {
 			t.Fatalf("Expected stats to equal %#v, got %#v", test.expectedStats, stats.ChoiceAltCnt)
 		}
+
+		nstats := native.Stats{}
+		_, err = native.Parse("TestStatistics", []byte(test.json), native.Statistics(&nstats, "no match"))
+		if err != nil {
+			t.Fatalf("Expected native parser to parse %s without error, got: %v", test.json, err)
+		}
+		if !reflect.DeepEqual(test.expectedStats, nstats.ChoiceAltCnt) {
+			t.Fatalf("Expected native stats to equal %#v, got %#v", test.expectedStats, nstats.ChoiceAltCnt)
+		}
+
+		vstats := vm.Stats{}
+		_, err = vm.Parse("TestStatistics", []byte(test.json), vm.Statistics(&vstats, "no match"))
+		if err != nil {
+			t.Fatalf("Expected vm parser to parse %s without error, got: %v", test.json, err)
+		}
+		if !reflect.DeepEqual(test.expectedStats, vstats.ChoiceAltCnt) {
+			t.Fatalf("Expected vm stats to equal %#v, got %#v", test.expectedStats, vstats.ChoiceAltCnt)
+		}
+
+		rstats := sharedruntime.Stats{}
+		_, err = sharedruntime.Parse("TestStatistics", []byte(test.json), sharedruntime.Statistics(&rstats, "no match"))
+		if err != nil {
+			t.Fatalf("Expected shared runtime parser to parse %s without error, got: %v", test.json, err)
+		}
+		if !reflect.DeepEqual(test.expectedStats, rstats.ChoiceAltCnt) {
+			t.Fatalf("Expected shared runtime stats to equal %#v, got %#v", test.expectedStats, rstats.ChoiceAltCnt)
+		}
 	}
 }
 
@@ -188,6 +308,48 @@ func BenchmarkPigeonJSONOptimizedGrammar(b *testing.B) {
 	}
 }
 
+func BenchmarkPigeonJSONNative(b *testing.B) {
+	d, err := ioutil.ReadFile("testdata/github-octokit-repos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := native.Parse("", d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPigeonJSONVM(b *testing.B) {
+	d, err := ioutil.ReadFile("testdata/github-octokit-repos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := vm.Parse("", d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPigeonJSONSharedRuntime(b *testing.B) {
+	d, err := ioutil.ReadFile("testdata/github-octokit-repos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := sharedruntime.Parse("", d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkStdlibJSON(b *testing.B) {
 	d, err := ioutil.ReadFile("testdata/github-octokit-repos.json")
 	if err != nil {
@@ -0,0 +1,824 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -nolint -shared-runtime
+
+// Package json parses JSON as defined by [1].
+//
+// BUGS: the escaped forward solidus (`\/`) is not currently handled.
+//
+// [1]: http://www.ecma-international.org/publications/files/ECMA-ST/ECMA-404.pdf
+package json
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	pigeonrt "github.com/mna/pigeon/runtime"
+)
+
+func toIfaceSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.([]interface{})
+}
+
+var g = &pigeonrt.Grammar{
+	Rules: []*pigeonrt.Rule{
+		{
+			Name: "JSON",
+			Pos:  pigeonrt.Position{Line: 17, Col: 1, Offset: 347},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 17, Col: 8, Offset: 356},
+				Run: callonJSON1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 17, Col: 8, Offset: 356},
+					Exprs: []interface{}{
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 17, Col: 8, Offset: 356},
+							Name: "_",
+						},
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 17, Col: 10, Offset: 358},
+							Label: "vals",
+							Expr: &pigeonrt.OneOrMoreExpr{
+								Pos: pigeonrt.Position{Line: 17, Col: 15, Offset: 363},
+								Expr: &pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 17, Col: 15, Offset: 363},
+									Name: "Value",
+								},
+							},
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 17, Col: 22, Offset: 370},
+							Name: "EOF",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Value",
+			Pos:  pigeonrt.Position{Line: 29, Col: 1, Offset: 561},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 29, Col: 9, Offset: 571},
+				Run: callonValue1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 29, Col: 9, Offset: 571},
+					Exprs: []interface{}{
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 29, Col: 9, Offset: 571},
+							Label: "val",
+							Expr: &pigeonrt.ChoiceExpr{
+								Pos: pigeonrt.Position{Line: 29, Col: 15, Offset: 577},
+								Alternatives: []interface{}{
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 15, Offset: 577},
+										Name: "Object",
+									},
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 24, Offset: 586},
+										Name: "Array",
+									},
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 32, Offset: 594},
+										Name: "Number",
+									},
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 41, Offset: 603},
+										Name: "String",
+									},
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 50, Offset: 612},
+										Name: "Bool",
+									},
+									&pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 57, Offset: 619},
+										Name: "Null",
+									},
+								},
+							},
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 29, Col: 64, Offset: 626},
+							Name: "_",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Object",
+			Pos:  pigeonrt.Position{Line: 33, Col: 1, Offset: 653},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 33, Col: 10, Offset: 664},
+				Run: callonObject1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 33, Col: 10, Offset: 664},
+					Exprs: []interface{}{
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 33, Col: 10, Offset: 664},
+							Val:        "{",
+							IgnoreCase: false,
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 33, Col: 14, Offset: 668},
+							Name: "_",
+						},
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 33, Col: 16, Offset: 670},
+							Label: "vals",
+							Expr: &pigeonrt.ZeroOrOneExpr{
+								Pos: pigeonrt.Position{Line: 33, Col: 21, Offset: 675},
+								Expr: &pigeonrt.SeqExpr{
+									Pos: pigeonrt.Position{Line: 33, Col: 23, Offset: 677},
+									Exprs: []interface{}{
+										&pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 33, Col: 23, Offset: 677},
+											Name: "String",
+										},
+										&pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 33, Col: 30, Offset: 684},
+											Name: "_",
+										},
+										&pigeonrt.LitMatcher{
+											Pos:        pigeonrt.Position{Line: 33, Col: 32, Offset: 686},
+											Val:        ":",
+											IgnoreCase: false,
+										},
+										&pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 33, Col: 36, Offset: 690},
+											Name: "_",
+										},
+										&pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 33, Col: 38, Offset: 692},
+											Name: "Value",
+										},
+										&pigeonrt.ZeroOrMoreExpr{
+											Pos: pigeonrt.Position{Line: 33, Col: 44, Offset: 698},
+											Expr: &pigeonrt.SeqExpr{
+												Pos: pigeonrt.Position{Line: 33, Col: 46, Offset: 700},
+												Exprs: []interface{}{
+													&pigeonrt.LitMatcher{
+														Pos:        pigeonrt.Position{Line: 33, Col: 46, Offset: 700},
+														Val:        ",",
+														IgnoreCase: false,
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 33, Col: 50, Offset: 704},
+														Name: "_",
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 33, Col: 52, Offset: 706},
+														Name: "String",
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 33, Col: 59, Offset: 713},
+														Name: "_",
+													},
+													&pigeonrt.LitMatcher{
+														Pos:        pigeonrt.Position{Line: 33, Col: 61, Offset: 715},
+														Val:        ":",
+														IgnoreCase: false,
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 33, Col: 65, Offset: 719},
+														Name: "_",
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 33, Col: 67, Offset: 721},
+														Name: "Value",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 33, Col: 79, Offset: 733},
+							Val:        "}",
+							IgnoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Array",
+			Pos:  pigeonrt.Position{Line: 48, Col: 1, Offset: 1075},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 48, Col: 9, Offset: 1085},
+				Run: callonArray1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 48, Col: 9, Offset: 1085},
+					Exprs: []interface{}{
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 48, Col: 9, Offset: 1085},
+							Val:        "[",
+							IgnoreCase: false,
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 48, Col: 13, Offset: 1089},
+							Name: "_",
+						},
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 48, Col: 15, Offset: 1091},
+							Label: "vals",
+							Expr: &pigeonrt.ZeroOrOneExpr{
+								Pos: pigeonrt.Position{Line: 48, Col: 20, Offset: 1096},
+								Expr: &pigeonrt.SeqExpr{
+									Pos: pigeonrt.Position{Line: 48, Col: 22, Offset: 1098},
+									Exprs: []interface{}{
+										&pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 48, Col: 22, Offset: 1098},
+											Name: "Value",
+										},
+										&pigeonrt.ZeroOrMoreExpr{
+											Pos: pigeonrt.Position{Line: 48, Col: 28, Offset: 1104},
+											Expr: &pigeonrt.SeqExpr{
+												Pos: pigeonrt.Position{Line: 48, Col: 30, Offset: 1106},
+												Exprs: []interface{}{
+													&pigeonrt.LitMatcher{
+														Pos:        pigeonrt.Position{Line: 48, Col: 30, Offset: 1106},
+														Val:        ",",
+														IgnoreCase: false,
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 48, Col: 34, Offset: 1110},
+														Name: "_",
+													},
+													&pigeonrt.RuleRefExpr{
+														Pos:  pigeonrt.Position{Line: 48, Col: 36, Offset: 1112},
+														Name: "Value",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 48, Col: 48, Offset: 1124},
+							Val:        "]",
+							IgnoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Number",
+			Pos:  pigeonrt.Position{Line: 62, Col: 1, Offset: 1430},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 62, Col: 10, Offset: 1441},
+				Run: callonNumber1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 62, Col: 10, Offset: 1441},
+					Exprs: []interface{}{
+						&pigeonrt.ZeroOrOneExpr{
+							Pos: pigeonrt.Position{Line: 62, Col: 10, Offset: 1441},
+							Expr: &pigeonrt.LitMatcher{
+								Pos:        pigeonrt.Position{Line: 62, Col: 10, Offset: 1441},
+								Val:        "-",
+								IgnoreCase: false,
+							},
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 62, Col: 15, Offset: 1446},
+							Name: "Integer",
+						},
+						&pigeonrt.ZeroOrOneExpr{
+							Pos: pigeonrt.Position{Line: 62, Col: 23, Offset: 1454},
+							Expr: &pigeonrt.SeqExpr{
+								Pos: pigeonrt.Position{Line: 62, Col: 25, Offset: 1456},
+								Exprs: []interface{}{
+									&pigeonrt.LitMatcher{
+										Pos:        pigeonrt.Position{Line: 62, Col: 25, Offset: 1456},
+										Val:        ".",
+										IgnoreCase: false,
+									},
+									&pigeonrt.OneOrMoreExpr{
+										Pos: pigeonrt.Position{Line: 62, Col: 29, Offset: 1460},
+										Expr: &pigeonrt.RuleRefExpr{
+											Pos:  pigeonrt.Position{Line: 62, Col: 29, Offset: 1460},
+											Name: "DecimalDigit",
+										},
+									},
+								},
+							},
+						},
+						&pigeonrt.ZeroOrOneExpr{
+							Pos: pigeonrt.Position{Line: 62, Col: 46, Offset: 1477},
+							Expr: &pigeonrt.RuleRefExpr{
+								Pos:  pigeonrt.Position{Line: 62, Col: 46, Offset: 1477},
+								Name: "Exponent",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Integer",
+			Pos:  pigeonrt.Position{Line: 68, Col: 1, Offset: 1632},
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 68, Col: 11, Offset: 1644},
+				Alternatives: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 68, Col: 11, Offset: 1644},
+						Val:        "0",
+						IgnoreCase: false,
+					},
+					&pigeonrt.SeqExpr{
+						Pos: pigeonrt.Position{Line: 68, Col: 17, Offset: 1650},
+						Exprs: []interface{}{
+							&pigeonrt.RuleRefExpr{
+								Pos:  pigeonrt.Position{Line: 68, Col: 17, Offset: 1650},
+								Name: "NonZeroDecimalDigit",
+							},
+							&pigeonrt.ZeroOrMoreExpr{
+								Pos: pigeonrt.Position{Line: 68, Col: 37, Offset: 1670},
+								Expr: &pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 68, Col: 37, Offset: 1670},
+									Name: "DecimalDigit",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Exponent",
+			Pos:  pigeonrt.Position{Line: 70, Col: 1, Offset: 1685},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 70, Col: 12, Offset: 1698},
+				Exprs: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 70, Col: 12, Offset: 1698},
+						Val:        "e",
+						IgnoreCase: true,
+					},
+					&pigeonrt.ZeroOrOneExpr{
+						Pos: pigeonrt.Position{Line: 70, Col: 17, Offset: 1703},
+						Expr: &pigeonrt.CharClassMatcher{
+							Pos:        pigeonrt.Position{Line: 70, Col: 17, Offset: 1703},
+							Val:        "[+-]",
+							Chars:      []rune{'+', '-'},
+							IgnoreCase: false,
+							Inverted:   false,
+						},
+					},
+					&pigeonrt.OneOrMoreExpr{
+						Pos: pigeonrt.Position{Line: 70, Col: 23, Offset: 1709},
+						Expr: &pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 70, Col: 23, Offset: 1709},
+							Name: "DecimalDigit",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "String",
+			Pos:  pigeonrt.Position{Line: 72, Col: 1, Offset: 1724},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 72, Col: 10, Offset: 1735},
+				Run: callonString1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 72, Col: 10, Offset: 1735},
+					Exprs: []interface{}{
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 72, Col: 10, Offset: 1735},
+							Val:        "\"",
+							IgnoreCase: false,
+						},
+						&pigeonrt.ZeroOrMoreExpr{
+							Pos: pigeonrt.Position{Line: 72, Col: 14, Offset: 1739},
+							Expr: &pigeonrt.ChoiceExpr{
+								Pos: pigeonrt.Position{Line: 72, Col: 16, Offset: 1741},
+								Alternatives: []interface{}{
+									&pigeonrt.SeqExpr{
+										Pos: pigeonrt.Position{Line: 72, Col: 16, Offset: 1741},
+										Exprs: []interface{}{
+											&pigeonrt.NotExpr{
+												Pos: pigeonrt.Position{Line: 72, Col: 16, Offset: 1741},
+												Expr: &pigeonrt.RuleRefExpr{
+													Pos:  pigeonrt.Position{Line: 72, Col: 17, Offset: 1742},
+													Name: "EscapedChar",
+												},
+											},
+											&pigeonrt.AnyMatcher{
+												Line: 72, Col: 29, Offset: 1754,
+											},
+										},
+									},
+									&pigeonrt.SeqExpr{
+										Pos: pigeonrt.Position{Line: 72, Col: 33, Offset: 1758},
+										Exprs: []interface{}{
+											&pigeonrt.LitMatcher{
+												Pos:        pigeonrt.Position{Line: 72, Col: 33, Offset: 1758},
+												Val:        "\\",
+												IgnoreCase: false,
+											},
+											&pigeonrt.RuleRefExpr{
+												Pos:  pigeonrt.Position{Line: 72, Col: 38, Offset: 1763},
+												Name: "EscapeSequence",
+											},
+										},
+									},
+								},
+							},
+						},
+						&pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 72, Col: 56, Offset: 1781},
+							Val:        "\"",
+							IgnoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "EscapedChar",
+			Pos:  pigeonrt.Position{Line: 78, Col: 1, Offset: 1953},
+			Expr: &pigeonrt.CharClassMatcher{
+				Pos:        pigeonrt.Position{Line: 78, Col: 15, Offset: 1969},
+				Val:        "[\\x00-\\x1f\"\\\\]",
+				Chars:      []rune{'"', '\\'},
+				Ranges:     []rune{'\x00', '\x1f'},
+				IgnoreCase: false,
+				Inverted:   false,
+			},
+		},
+		{
+			Name: "EscapeSequence",
+			Pos:  pigeonrt.Position{Line: 80, Col: 1, Offset: 1985},
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 80, Col: 18, Offset: 2004},
+				Alternatives: []interface{}{
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 80, Col: 18, Offset: 2004},
+						Name: "SingleCharEscape",
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 80, Col: 37, Offset: 2023},
+						Name: "UnicodeEscape",
+					},
+				},
+			},
+		},
+		{
+			Name: "SingleCharEscape",
+			Pos:  pigeonrt.Position{Line: 82, Col: 1, Offset: 2038},
+			Expr: &pigeonrt.CharClassMatcher{
+				Pos:        pigeonrt.Position{Line: 82, Col: 20, Offset: 2059},
+				Val:        "[\"\\\\/bfnrt]",
+				Chars:      []rune{'"', '\\', '/', 'b', 'f', 'n', 'r', 't'},
+				IgnoreCase: false,
+				Inverted:   false,
+			},
+		},
+		{
+			Name: "UnicodeEscape",
+			Pos:  pigeonrt.Position{Line: 84, Col: 1, Offset: 2072},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 84, Col: 17, Offset: 2090},
+				Exprs: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 84, Col: 17, Offset: 2090},
+						Val:        "u",
+						IgnoreCase: false,
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 84, Col: 21, Offset: 2094},
+						Name: "HexDigit",
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 84, Col: 30, Offset: 2103},
+						Name: "HexDigit",
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 84, Col: 39, Offset: 2112},
+						Name: "HexDigit",
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 84, Col: 48, Offset: 2121},
+						Name: "HexDigit",
+					},
+				},
+			},
+		},
+		{
+			Name: "DecimalDigit",
+			Pos:  pigeonrt.Position{Line: 86, Col: 1, Offset: 2131},
+			Expr: &pigeonrt.CharClassMatcher{
+				Pos:        pigeonrt.Position{Line: 86, Col: 16, Offset: 2148},
+				Val:        "[0-9]",
+				Ranges:     []rune{'0', '9'},
+				IgnoreCase: false,
+				Inverted:   false,
+			},
+		},
+		{
+			Name: "NonZeroDecimalDigit",
+			Pos:  pigeonrt.Position{Line: 88, Col: 1, Offset: 2155},
+			Expr: &pigeonrt.CharClassMatcher{
+				Pos:        pigeonrt.Position{Line: 88, Col: 23, Offset: 2179},
+				Val:        "[1-9]",
+				Ranges:     []rune{'1', '9'},
+				IgnoreCase: false,
+				Inverted:   false,
+			},
+		},
+		{
+			Name: "HexDigit",
+			Pos:  pigeonrt.Position{Line: 90, Col: 1, Offset: 2186},
+			Expr: &pigeonrt.CharClassMatcher{
+				Pos:        pigeonrt.Position{Line: 90, Col: 12, Offset: 2199},
+				Val:        "[0-9a-f]i",
+				Ranges:     []rune{'0', '9', 'a', 'f'},
+				IgnoreCase: true,
+				Inverted:   false,
+			},
+		},
+		{
+			Name: "Bool",
+			Pos:  pigeonrt.Position{Line: 92, Col: 1, Offset: 2210},
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 92, Col: 8, Offset: 2219},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 92, Col: 8, Offset: 2219},
+						Run: callonBool2,
+						Expr: &pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 92, Col: 8, Offset: 2219},
+							Val:        "true",
+							IgnoreCase: false,
+						},
+					},
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 92, Col: 38, Offset: 2249},
+						Run: callonBool4,
+						Expr: &pigeonrt.LitMatcher{
+							Pos:        pigeonrt.Position{Line: 92, Col: 38, Offset: 2249},
+							Val:        "false",
+							IgnoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Null",
+			Pos:  pigeonrt.Position{Line: 94, Col: 1, Offset: 2280},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 94, Col: 8, Offset: 2289},
+				Run: callonNull1,
+				Expr: &pigeonrt.LitMatcher{
+					Pos:        pigeonrt.Position{Line: 94, Col: 8, Offset: 2289},
+					Val:        "null",
+					IgnoreCase: false,
+				},
+			},
+		},
+		{
+			Name:        "_",
+			DisplayName: "\"whitespace\"",
+			Pos:         pigeonrt.Position{Line: 96, Col: 1, Offset: 2317},
+			Expr: &pigeonrt.ZeroOrMoreExpr{
+				Pos: pigeonrt.Position{Line: 96, Col: 18, Offset: 2336},
+				Expr: &pigeonrt.CharClassMatcher{
+					Pos:        pigeonrt.Position{Line: 96, Col: 18, Offset: 2336},
+					Val:        "[ \\t\\r\\n]",
+					Chars:      []rune{' ', '\t', '\r', '\n'},
+					IgnoreCase: false,
+					Inverted:   false,
+				},
+			},
+		},
+		{
+			Name: "EOF",
+			Pos:  pigeonrt.Position{Line: 98, Col: 1, Offset: 2348},
+			Expr: &pigeonrt.NotExpr{
+				Pos: pigeonrt.Position{Line: 98, Col: 7, Offset: 2356},
+				Expr: &pigeonrt.AnyMatcher{
+					Line: 98, Col: 8, Offset: 2357,
+				},
+			},
+		},
+	},
+}
+
+func (c *current) onJSON1(vals interface{}) (interface{}, error) {
+	valsSl := toIfaceSlice(vals)
+	switch len(valsSl) {
+	case 0:
+		return nil, nil
+	case 1:
+		return valsSl[0], nil
+	default:
+		return valsSl, nil
+	}
+}
+
+func callonJSON1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onJSON1(stack["vals"])
+}
+
+func (c *current) onValue1(val interface{}) (interface{}, error) {
+	return val, nil
+}
+
+func callonValue1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onValue1(stack["val"])
+}
+
+func (c *current) onObject1(vals interface{}) (interface{}, error) {
+	res := make(map[string]interface{})
+	valsSl := toIfaceSlice(vals)
+	if len(valsSl) == 0 {
+		return res, nil
+	}
+	res[valsSl[0].(string)] = valsSl[4]
+	restSl := toIfaceSlice(valsSl[5])
+	for _, v := range restSl {
+		vSl := toIfaceSlice(v)
+		res[vSl[2].(string)] = vSl[6]
+	}
+	return res, nil
+}
+
+func callonObject1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onObject1(stack["vals"])
+}
+
+func (c *current) onArray1(vals interface{}) (interface{}, error) {
+	valsSl := toIfaceSlice(vals)
+	if len(valsSl) == 0 {
+		return []interface{}{}, nil
+	}
+	res := []interface{}{valsSl[0]}
+	restSl := toIfaceSlice(valsSl[1])
+	for _, v := range restSl {
+		vSl := toIfaceSlice(v)
+		res = append(res, vSl[2])
+	}
+	return res, nil
+}
+
+func callonArray1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onArray1(stack["vals"])
+}
+
+func (c *current) onNumber1() (interface{}, error) {
+	// JSON numbers have the same syntax as Go's, and are parseable using
+	// strconv.
+	return strconv.ParseFloat(string(c.text), 64)
+}
+
+func callonNumber1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onNumber1()
+}
+
+func (c *current) onString1() (interface{}, error) {
+	// TODO : the forward slash (solidus) is not a valid escape in Go, it will
+	// fail if there's one in the string
+	return strconv.Unquote(string(c.text))
+}
+
+func callonString1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onString1()
+}
+
+func (c *current) onBool2() (interface{}, error) {
+	return true, nil
+}
+
+func callonBool2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onBool2()
+}
+
+func (c *current) onBool4() (interface{}, error) {
+	return false, nil
+}
+
+func callonBool4(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onBool4()
+}
+
+func (c *current) onNull1() (interface{}, error) {
+	return nil, nil
+}
+
+func callonNull1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onNull1()
+}
+
+// the parser fails to compile with an incompatible version of the runtime
+// package.
+const _ = pigeonrt.PackageIsVersion1
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option = pigeonrt.Option
+
+// Stats stores some statistics, gathered during parsing
+type Stats = pigeonrt.Stats
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+type Cloner = pigeonrt.Cloner
+
+// The options of the parser, see the runtime package for details.
+var (
+	AllowInvalidUTF8 = pigeonrt.AllowInvalidUTF8
+	Debug            = pigeonrt.Debug
+	Entrypoint       = pigeonrt.Entrypoint
+	GlobalStore      = pigeonrt.GlobalStore
+	InitState        = pigeonrt.InitState
+	MaxExpressions   = pigeonrt.MaxExpressions
+	Memoize          = pigeonrt.Memoize
+	Recover          = pigeonrt.Recover
+	Statistics       = pigeonrt.Statistics
+)
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseFile(g, filename, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseReader(g, filename, r, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return pigeonrt.Parse(g, filename, b, opts...)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict = pigeonrt.StoreDict
+
+// newCurrent returns the context of the code block run by the parser p. The
+// stores are shared with the parser, so that changes to their content are
+// seen by the parser.
+func newCurrent(p *pigeonrt.Parser) *current {
+	c := p.Current()
+	return &current{
+		pos:         position{line: c.Pos.Line, col: c.Pos.Col, offset: c.Pos.Offset},
+		text:        c.Text,
+		state:       c.State,
+		globalStore: c.GlobalStore,
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/interp"
+	"github.com/mna/pigeon/runtime"
+)
+
+// grammarTest is a test case of the grammar declared by a @test directive,
+// e.g. @test Number accepts "1.5e3" or @test Number rejects "01".
+type grammarTest struct {
+	d      *ast.Directive
+	rule   string
+	accept bool
+	input  string
+}
+
+// String returns the textual representation of the test, as declared in
+// the grammar.
+func (t *grammarTest) String() string {
+	verb := "rejects"
+	if t.accept {
+		verb = "accepts"
+	}
+	return fmt.Sprintf("%s %s %s", t.rule, verb, strconv.Quote(t.input))
+}
+
+// run parses the input of the test with the grammar g, starting at the rule
+// of the test. A rule accepts an input if it matches all of it. It returns
+// nil if the test passes, otherwise an error that describes the failure.
+func (t *grammarTest) run(g *ast.Grammar, opts ...runtime.Option) error {
+	n, err := interp.Parse(g, "", []byte(t.input), append(opts, runtime.Entrypoint(t.rule))...)
+	if err == nil && n.End < len(t.input) {
+		line, col := lineCol(t.input, n.End)
+		err = fmt.Errorf("%d:%d (%d): rule %s: match ends before the end of the input", line, col, n.End, t.rule)
+	}
+	switch {
+	case t.accept && err != nil:
+		return err
+	case !t.accept && err == nil:
+		return fmt.Errorf("rule %s: want no match, got a match", t.rule)
+	}
+	return nil
+}
+
+// lineCol returns the line and column of the offset off in s, the same way
+// as the parsers.
+func lineCol(s string, off int) (line, col int) {
+	line = 1
+	for _, rn := range s[:off] {
+		col++
+		if rn == '\n' {
+			line++
+			col = 0
+		}
+	}
+	return line, col + 1
+}
+
+// grammarTests returns the test cases declared by the @test directives of
+// the grammar g. It returns an error of type ast.ValidationErrors if a
+// directive is invalid.
+func grammarTests(g *ast.Grammar) ([]*grammarTest, error) {
+	rules := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = true
+	}
+
+	var tests []*grammarTest
+	var errs ast.ValidationErrors
+	invalid := func(d *ast.Directive, format string, args ...interface{}) {
+		errs = append(errs, &ast.ValidationError{Pos: d.Pos(), Code: ast.CodeInvalidDirective, Msg: fmt.Sprintf(format, args...)})
+	}
+	for _, d := range g.Directives {
+		if d.Name.Val != "test" {
+			continue
+		}
+		if len(d.Args) != 3 {
+			invalid(d, "@test requires a rule name, accepts or rejects, and an input")
+			continue
+		}
+		t := &grammarTest{d: d, rule: d.Args[0], input: d.Args[2]}
+		switch d.Args[1] {
+		case "accepts":
+			t.accept = true
+		case "rejects":
+		default:
+			invalid(d, "invalid @test verb %s, expected accepts or rejects", d.Args[1])
+			continue
+		}
+		if !rules[t.rule] {
+			invalid(d, "undefined rule: %s", t.rule)
+			continue
+		}
+		tests = append(tests, t)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return tests, nil
+}
+
+// runGrammarTests runs the tests with the grammar g and writes the
+// failures and a summary to w. It returns the number of failed tests.
+func runGrammarTests(w io.Writer, g *ast.Grammar, tests []*grammarTest, opts ...runtime.Option) int {
+	var failed int
+	for _, t := range tests {
+		if err := t.run(g, opts...); err != nil {
+			failed++
+			fmt.Fprintf(w, "--- FAIL: %s: %s\n", t.d.Pos(), t)
+			fmt.Fprintf(w, "    %s\n", strings.Replace(err.Error(), "\n", "\n    ", -1))
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(w, "FAIL: %d of %d tests failed\n", failed, len(tests))
+	} else {
+		fmt.Fprintf(w, "ok: %d tests passed\n", len(tests))
+	}
+	return failed
+}
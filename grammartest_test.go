@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestGrammarTests(t *testing.T) {
+	grammar := `
+@test Number accepts "1.5e3"
+@test Number rejects "01"
+@test Number accepts "1."
+@test Number rejects "1"
+@test Sum accepts "1+2"
+Sum = Number ( '+' Number )* !.
+Number = ( '0' / [1-9] [0-9]* ) ( '.' [0-9]+ )? ( 'e' [0-9]+ )?
+`
+	g, err := Parse("file", []byte(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests, err := grammarTests(g.(*ast.Grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if got := runGrammarTests(&buf, g.(*ast.Grammar), tests); got != 2 {
+		t.Errorf("want 2 failed tests, got %d", got)
+	}
+	want := `--- FAIL: 4:1 (56): Number accepts "1."
+    1:2 (1): rule Number: match ends before the end of the input
+--- FAIL: 5:1 (82): Number rejects "1"
+    rule Number: want no match, got a match
+FAIL: 2 of 5 tests failed
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestGrammarTestsInvalid(t *testing.T) {
+	grammar := `
+@test A
+@test A matches "a"
+@test B accepts "b"
+@test A accepts "a"
+A = 'a'
+`
+	g, err := Parse("file", []byte(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = grammarTests(g.(*ast.Grammar))
+	errs, ok := err.(ast.ValidationErrors)
+	if !ok {
+		t.Fatalf("want error of type %T, got %T", errs, err)
+	}
+	want := []string{
+		"2:1 (1): @test requires a rule name, accepts or rejects, and an input",
+		"3:1 (9): invalid @test verb matches, expected accepts or rejects",
+		"4:1 (29): undefined rule: B",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("want %d errors, got %d: %v", len(want), len(errs), errs)
+	}
+	for i, e := range errs {
+		if e.Error() != want[i] {
+			t.Errorf("%d: want %q, got %q", i, want[i], e.Error())
+		}
+	}
+}
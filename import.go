@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// filenameKey is the key of the global store of the grammar parser that
+// holds the name of the parsed file, set in the positions of the AST.
+const filenameKey = "filename"
+
+// importGrammars merges the grammars imported by the @import directives of
+// g, parsed from the file filename, into g, e.g. @import "lexer.peg". The
+// imported files are resolved relative to the directory of the file that
+// imports them, and may import other files. Each file is imported once.
+// The rules and directives of the imported grammars are added after those
+// of g and their initializers to g.ImportedInits, in import order. The
+// options opts are used to parse the imported files. It returns an error
+// of type ast.ValidationErrors if an import fails, including the parse
+// errors of the imported files.
+func importGrammars(g *ast.Grammar, filename string, opts ...Option) error {
+	imported := make(map[string]bool)
+	if abs, err := filepath.Abs(filename); err == nil {
+		imported[abs] = true
+	}
+
+	var errs ast.ValidationErrors
+	invalid := func(d *ast.Directive, format string, args ...interface{}) {
+		errs = append(errs, &ast.ValidationError{Pos: d.Pos(), Code: ast.CodeInvalidDirective, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	var importFrom func(src *ast.Grammar, filename string)
+	importFrom = func(src *ast.Grammar, filename string) {
+		for _, d := range src.Directives {
+			if d.Name.Val != "import" {
+				continue
+			}
+			if len(d.Args) != 1 {
+				invalid(d, "@import requires exactly one file name")
+				continue
+			}
+
+			nm := d.Args[0]
+			if !filepath.IsAbs(nm) {
+				nm = filepath.Join(filepath.Dir(filename), nm)
+			}
+			abs, err := filepath.Abs(nm)
+			if err != nil {
+				invalid(d, "cannot import %s: %v", d.Args[0], err)
+				continue
+			}
+			if imported[abs] {
+				continue
+			}
+			imported[abs] = true
+
+			v, err := ParseFile(nm, append(opts, GlobalStore(filenameKey, nm))...)
+			if err != nil {
+				list, ok := err.(errList)
+				if !ok {
+					invalid(d, "cannot import %s: %v", d.Args[0], err)
+					continue
+				}
+				for _, err := range list {
+					errs = append(errs, importParseError(nm, err))
+				}
+				continue
+			}
+
+			ig := v.(*ast.Grammar)
+			g.Rules = append(g.Rules, ig.Rules...)
+			g.Directives = append(g.Directives, ig.Directives...)
+			if ig.Init != nil {
+				g.ImportedInits = append(g.ImportedInits, ig.Init)
+			}
+			importFrom(ig, nm)
+		}
+	}
+	importFrom(g, filename)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// importParseError returns the error err raised while parsing the imported
+// file filename as a validation error, so that it is reported with the
+// name of the file.
+func importParseError(filename string, err error) *ast.ValidationError {
+	e := &ast.ValidationError{Pos: ast.Pos{Filename: filename}, Code: codeParseError, Msg: err.Error()}
+	if pe, ok := err.(*parserError); ok {
+		e.Pos.Line, e.Pos.Col, e.Pos.Off = pe.pos.line, pe.pos.col, pe.pos.offset
+		e.Msg = pe.Inner.Error()
+	}
+	return e
+}
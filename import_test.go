@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestImportGrammars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pigeon-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.peg":     "{\npackage a\n}\n@import \"sub/b.peg\"\nA = B",
+		"sub/b.peg": "{\nimport \"fmt\"\n}\n@import \"c.peg\"\n@import \"../a.peg\"\nB = C",
+		"sub/c.peg": "C = 'c'\nB = 'b'",
+		"d.peg":     "@import \"missing.peg\"\n@import\n@import \"e.peg\"\nD = 'd'",
+		"e.peg":     "E = ",
+	}
+	for nm, src := range files {
+		nm = filepath.Join(dir, nm)
+		if err := os.MkdirAll(filepath.Dir(nm), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(nm, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parse := func(nm string) *ast.Grammar {
+		g, err := ParseFile(nm, GlobalStore(filenameKey, nm))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g.(*ast.Grammar)
+	}
+
+	// the files are resolved relative to the importing file, once
+	a := filepath.Join(dir, "a.peg")
+	g := parse(a)
+	if err := importGrammars(g, a); err != nil {
+		t.Fatal(err)
+	}
+	var rules []string
+	for _, r := range g.Rules {
+		rules = append(rules, r.Name.Val+"@"+filepath.ToSlash(r.Pos().Filename[len(dir)+1:]))
+	}
+	want := []string{"A@a.peg", "B@sub/b.peg", "C@sub/c.peg", "B@sub/c.peg"}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("want rules %v, got %v", want, rules)
+	}
+	if len(g.ImportedInits) != 1 || g.ImportedInits[0].Val != "{\nimport \"fmt\"\n}" {
+		t.Errorf("want the initializer of b.peg imported, got %v", g.ImportedInits)
+	}
+	if len(g.Directives) != 3 {
+		t.Errorf("want 3 directives, got %d", len(g.Directives))
+	}
+
+	// duplicate rules are detected across files
+	err = ast.CheckDuplicates(g)
+	wantErr := filepath.Join(dir, "sub", "c.peg") + ":2:1 (8): rule B already defined at " + filepath.Join(dir, "sub", "b.peg") + ":6:1 (52)"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("want error %q, got %v", wantErr, err)
+	}
+
+	d := filepath.Join(dir, "d.peg")
+	err = importGrammars(parse(d), d)
+	errs, ok := err.(ast.ValidationErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("want 3 validation errors, got %v", err)
+	}
+	for i, want := range []struct {
+		file string
+		line int
+		code string
+	}{
+		{d, 1, ast.CodeInvalidDirective},
+		{d, 2, ast.CodeInvalidDirective},
+		{filepath.Join(dir, "e.peg"), 1, codeParseError},
+	} {
+		if got := errs[i]; got.Pos.Filename != want.file || got.Pos.Line != want.line || got.Code != want.code {
+			t.Errorf("%d: want %s:%d %s, got %s: %s", i, want.file, want.line, want.code, got, got.Code)
+		}
+	}
+}
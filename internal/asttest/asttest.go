@@ -0,0 +1,176 @@
+// Package asttest provides helpers to build grammars in the tests of the
+// packages that work on the AST, without parsing a grammar source.
+//
+// The positions of the nodes are set to the index of the node in the order
+// of creation so that errors can be identified. The index must be reset
+// with Reset before building a grammar.
+package asttest
+
+import (
+	"github.com/mna/pigeon/ast"
+)
+
+var posIx int
+
+// Pos returns the position of the next node.
+func Pos() ast.Pos {
+	posIx++
+	return ast.Pos{Line: 1, Col: posIx, Off: posIx - 1}
+}
+
+// Reset resets the index of the positions of the nodes.
+func Reset() {
+	posIx = 0
+}
+
+// Grammar returns a grammar with the rules.
+func Grammar(rules ...*ast.Rule) *ast.Grammar {
+	g := ast.NewGrammar(ast.Pos{})
+	g.Rules = rules
+	return g
+}
+
+// Rule returns the rule name that matches expr.
+func Rule(name string, expr ast.Expression) *ast.Rule {
+	r := ast.NewRule(Pos(), ast.NewIdentifier(ast.Pos{}, name))
+	r.Expr = expr
+	return r
+}
+
+// NamedRule returns the rule name with the display name display that
+// matches expr.
+func NamedRule(name, display string, expr ast.Expression) *ast.Rule {
+	r := Rule(name, expr)
+	r.DisplayName = ast.NewStringLit(ast.Pos{}, display)
+	return r
+}
+
+// TypedRule returns the rule name of type typ that matches expr.
+func TypedRule(name, typ string, expr ast.Expression) *ast.Rule {
+	r := Rule(name, expr)
+	r.Type = typ
+	return r
+}
+
+// ParamRule returns the rule name with the parameters params that matches
+// expr.
+func ParamRule(name string, expr ast.Expression, params ...string) *ast.Rule {
+	r := Rule(name, expr)
+	for _, p := range params {
+		r.Params = append(r.Params, ast.NewIdentifier(Pos(), p))
+	}
+	return r
+}
+
+// Ref returns a reference to the rule name, with the arguments args if the
+// rule has parameters.
+func Ref(name string, args ...ast.Expression) *ast.RuleRefExpr {
+	r := ast.NewRuleRefExpr(Pos())
+	r.Name = ast.NewIdentifier(ast.Pos{}, name)
+	r.Args = args
+	return r
+}
+
+// Lit returns the literal val.
+func Lit(val string) *ast.LitMatcher {
+	return ast.NewLitMatcher(Pos(), val)
+}
+
+// LitI returns the case-insensitive literal val.
+func LitI(val string) *ast.LitMatcher {
+	l := Lit(val)
+	l.IgnoreCase = true
+	return l
+}
+
+// Class returns the character class raw, as written in a grammar, e.g.
+// [a-z]i.
+func Class(raw string) *ast.CharClassMatcher {
+	return ast.NewCharClassMatcher(Pos(), raw)
+}
+
+// Any returns the any character matcher.
+func Any() *ast.AnyMatcher {
+	return ast.NewAnyMatcher(Pos(), ".")
+}
+
+// Seq returns the sequence of exprs.
+func Seq(exprs ...ast.Expression) *ast.SeqExpr {
+	s := ast.NewSeqExpr(Pos())
+	s.Exprs = exprs
+	return s
+}
+
+// Choice returns the choice of the alternatives exprs.
+func Choice(exprs ...ast.Expression) *ast.ChoiceExpr {
+	c := ast.NewChoiceExpr(Pos())
+	c.Alternatives = exprs
+	return c
+}
+
+// Opt returns expr?.
+func Opt(expr ast.Expression) *ast.ZeroOrOneExpr {
+	o := ast.NewZeroOrOneExpr(Pos())
+	o.Expr = expr
+	return o
+}
+
+// Star returns expr*.
+func Star(expr ast.Expression) *ast.ZeroOrMoreExpr {
+	s := ast.NewZeroOrMoreExpr(Pos())
+	s.Expr = expr
+	return s
+}
+
+// Plus returns expr+.
+func Plus(expr ast.Expression) *ast.OneOrMoreExpr {
+	p := ast.NewOneOrMoreExpr(Pos())
+	p.Expr = expr
+	return p
+}
+
+// And returns &expr.
+func And(expr ast.Expression) *ast.AndExpr {
+	a := ast.NewAndExpr(Pos())
+	a.Expr = expr
+	return a
+}
+
+// Not returns !expr.
+func Not(expr ast.Expression) *ast.NotExpr {
+	n := ast.NewNotExpr(Pos())
+	n.Expr = expr
+	return n
+}
+
+// Label returns label:expr.
+func Label(label string, expr ast.Expression) *ast.LabeledExpr {
+	l := ast.NewLabeledExpr(Pos())
+	l.Label = ast.NewIdentifier(ast.Pos{}, label)
+	l.Expr = expr
+	return l
+}
+
+// Action returns expr with the action code, which includes the braces.
+func Action(expr ast.Expression, code string) *ast.ActionExpr {
+	a := ast.NewActionExpr(Pos())
+	a.Expr = expr
+	a.Code = ast.NewCodeBlock(ast.Pos{}, code)
+	return a
+}
+
+// Throw returns %{label}.
+func Throw(label string) *ast.ThrowExpr {
+	t := ast.NewThrowExpr(Pos())
+	t.Label = label
+	return t
+}
+
+// Recover returns expr //{labels} recoverExpr.
+func Recover(expr, recoverExpr ast.Expression, labels ...ast.FailureLabel) *ast.RecoveryExpr {
+	r := ast.NewRecoveryExpr(Pos())
+	r.Expr = expr
+	r.RecoverExpr = recoverExpr
+	r.Labels = labels
+	return r
+}
@@ -0,0 +1,243 @@
+// Package interp parses input with a PEG grammar directly, without
+// generating and compiling a parser first. It is meant to try a grammar
+// while it is being written.
+//
+// The grammar is converted to the grammar of the runtime package, so the
+// input is matched the same way as by the generated parsers, and the
+// errors are the same. The code blocks of the grammar are not run: the
+// actions return the value of their expression, the predicate code blocks
+// always succeed and the state code blocks do nothing. The result is a
+// parse tree of the rules that matched.
+package interp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/runtime"
+)
+
+// nodeLabel is the label of the expression of each rule, whose value holds
+// the nodes of the rules that matched in the expression. It is not a valid
+// identifier, so that it cannot clash with the labels of the grammar.
+const nodeLabel = "*node"
+
+// Node is a node of the parse tree, for a rule that matched.
+type Node struct {
+	// Rule is the name of the rule.
+	Rule string
+
+	// Pos is the position of the start of the match, and End the offset of
+	// the end of the match.
+	Pos ast.Pos
+	End int
+
+	// Text is the text of the match.
+	Text string
+
+	// Children are the nodes of the rules that matched in the expression
+	// of the rule, in input order.
+	Children []*Node
+}
+
+// String returns the textual representation of the tree rooted at n, with
+// a line per node indented by its depth.
+func (n *Node) String() string {
+	var buf bytes.Buffer
+	n.Print(&buf)
+	return buf.String()
+}
+
+// Print writes the textual representation of the tree rooted at n to w.
+func (n *Node) Print(w io.Writer) error {
+	return n.print(w, 0)
+}
+
+func (n *Node) print(w io.Writer, depth int) error {
+	if _, err := fmt.Fprintf(w, "%s%s %d:%d (%d-%d) %q\n", strings.Repeat("  ", depth),
+		n.Rule, n.Pos.Line, n.Pos.Col, n.Pos.Off, n.End, n.Text); err != nil {
+		return err
+	}
+	for _, c := range n.Children {
+		if err := c.print(w, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse parses b with the grammar g and returns the parse tree of the first
+// rule of the grammar, or of the rule set by the runtime.Entrypoint option.
+// The filename is used in the positions and in the errors. The grammar must
+// be valid and its parameterized rules expanded, and the leaders of the
+// left-recursive rules must be marked with ast.MarkLeftRecursion if it has
+// any. The options of the generated parsers, e.g. runtime.MaxExpressions,
+// are supported.
+func Parse(g *ast.Grammar, filename string, b []byte, opts ...runtime.Option) (*Node, error) {
+	v, err := runtime.Parse(convertGrammar(g, filename), filename, b, opts...)
+	n, _ := v.(*Node)
+	return n, err
+}
+
+// convertGrammar returns the grammar of the runtime package for g, where
+// each rule returns a *Node.
+func convertGrammar(g *ast.Grammar, filename string) *runtime.Grammar {
+	rg := &runtime.Grammar{Pos: position(g.Pos())}
+	for _, r := range g.Rules {
+		rr := &runtime.Rule{
+			Pos:    position(r.Pos()),
+			Name:   r.Name.Val,
+			Leader: r.Leader,
+		}
+		if r.DisplayName != nil {
+			rr.DisplayName = r.DisplayName.Val
+		}
+		rr.Expr = &runtime.ActionExpr{
+			Pos: position(r.Expr.Pos()),
+			Expr: &runtime.LabeledExpr{
+				Pos:   position(r.Expr.Pos()),
+				Label: nodeLabel,
+				Expr:  convertExpr(r.Expr),
+			},
+			Run: newNode(r.Name.Val, filename),
+		}
+		rg.Rules = append(rg.Rules, rr)
+	}
+	return rg
+}
+
+// newNode returns the function of the action of the rule nm, which returns
+// the node of the match.
+func newNode(nm, filename string) func(*runtime.Parser) (interface{}, error) {
+	return func(p *runtime.Parser) (interface{}, error) {
+		c := p.Current()
+		n := &Node{
+			Rule: nm,
+			Pos:  ast.Pos{Filename: filename, Line: c.Pos.Line, Col: c.Pos.Col, Off: c.Pos.Offset},
+			End:  c.Pos.Offset + len(c.Text),
+			Text: string(c.Text),
+		}
+		n.Children = appendNodes(n.Children, p.Labels()[nodeLabel])
+		return n, nil
+	}
+}
+
+// appendNodes appends the nodes in the value v of an expression to nodes.
+func appendNodes(nodes []*Node, v interface{}) []*Node {
+	switch v := v.(type) {
+	case *Node:
+		nodes = append(nodes, v)
+	case []interface{}:
+		for _, v := range v {
+			nodes = appendNodes(nodes, v)
+		}
+	}
+	return nodes
+}
+
+// convertExpr returns the expression of the runtime package for expr.
+func convertExpr(expr ast.Expression) interface{} {
+	pos := position(expr.Pos())
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		// the value of the expression is kept, so that its nodes are
+		// children of the rule
+		return convertExpr(expr.Expr)
+	case *ast.AndCodeExpr:
+		return &runtime.AndCodeExpr{Pos: pos, Run: func(*runtime.Parser) (bool, error) { return true, nil }}
+	case *ast.AndExpr:
+		return &runtime.AndExpr{Pos: pos, Expr: convertExpr(expr.Expr)}
+	case *ast.AnyMatcher:
+		return (*runtime.AnyMatcher)(&pos)
+	case *ast.CharClassMatcher:
+		return convertCharClassMatcher(expr)
+	case *ast.ChoiceExpr:
+		ch := &runtime.ChoiceExpr{Pos: pos}
+		for _, alt := range expr.Alternatives {
+			ch.Alternatives = append(ch.Alternatives, convertExpr(alt))
+		}
+		return ch
+	case *ast.LabeledExpr:
+		return &runtime.LabeledExpr{Pos: pos, Label: expr.Label.Val, Expr: convertExpr(expr.Expr)}
+	case *ast.LitMatcher:
+		val := expr.Val
+		if expr.IgnoreCase {
+			val = strings.ToLower(val)
+		}
+		return &runtime.LitMatcher{Pos: pos, Val: val, IgnoreCase: expr.IgnoreCase}
+	case *ast.NotCodeExpr:
+		return &runtime.NotCodeExpr{Pos: pos, Run: func(*runtime.Parser) (bool, error) { return false, nil }}
+	case *ast.NotExpr:
+		return &runtime.NotExpr{Pos: pos, Expr: convertExpr(expr.Expr)}
+	case *ast.OneOrMoreExpr:
+		return &runtime.OneOrMoreExpr{Pos: pos, Expr: convertExpr(expr.Expr)}
+	case *ast.RecoveryExpr:
+		rec := &runtime.RecoveryExpr{
+			Pos:         pos,
+			Expr:        convertExpr(expr.Expr),
+			RecoverExpr: convertExpr(expr.RecoverExpr),
+		}
+		for _, l := range expr.Labels {
+			rec.FailureLabel = append(rec.FailureLabel, string(l))
+		}
+		return rec
+	case *ast.RuleRefExpr:
+		return &runtime.RuleRefExpr{Pos: pos, Name: expr.Name.Val}
+	case *ast.SeqExpr:
+		seq := &runtime.SeqExpr{Pos: pos}
+		for _, e := range expr.Exprs {
+			seq.Exprs = append(seq.Exprs, convertExpr(e))
+		}
+		return seq
+	case *ast.StateCodeExpr:
+		return &runtime.StateCodeExpr{Pos: pos, Run: func(*runtime.Parser) error { return nil }}
+	case *ast.ThrowExpr:
+		return &runtime.ThrowExpr{Pos: pos, Label: expr.Label}
+	case *ast.ZeroOrMoreExpr:
+		return &runtime.ZeroOrMoreExpr{Pos: pos, Expr: convertExpr(expr.Expr)}
+	case *ast.ZeroOrOneExpr:
+		return &runtime.ZeroOrOneExpr{Pos: pos, Expr: convertExpr(expr.Expr)}
+	}
+	panic(fmt.Sprintf("unknown expression type %T", expr))
+}
+
+// convertCharClassMatcher returns the character class matcher of the
+// runtime package for ch, the same way as the code generated by the builder.
+func convertCharClassMatcher(ch *ast.CharClassMatcher) *runtime.CharClassMatcher {
+	m := &runtime.CharClassMatcher{
+		Pos:        position(ch.Pos()),
+		Val:        ch.Val,
+		IgnoreCase: ch.IgnoreCase,
+		Inverted:   ch.Inverted,
+	}
+	lower := func(rn rune) rune {
+		if ch.IgnoreCase {
+			return unicode.ToLower(rn)
+		}
+		return rn
+	}
+	for _, rn := range ch.Chars {
+		m.Chars = append(m.Chars, lower(rn))
+	}
+	for _, rn := range ch.Ranges {
+		m.Ranges = append(m.Ranges, lower(rn))
+	}
+	for _, cl := range ch.UnicodeClasses {
+		rt := ast.UnicodeClass(cl)
+		if rt == nil {
+			// the classes are checked by the parser of the grammar
+			panic(fmt.Sprintf("invalid Unicode class: %s", cl))
+		}
+		m.Classes = append(m.Classes, rt)
+	}
+	return m
+}
+
+// position returns the position of the runtime package for p.
+func position(p ast.Pos) runtime.Position {
+	return runtime.Position{Line: p.Line, Col: p.Col, Offset: p.Off}
+}
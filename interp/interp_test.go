@@ -0,0 +1,158 @@
+package interp
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+	"github.com/mna/pigeon/runtime"
+)
+
+// testGrammar returns the grammar:
+//
+//	Start = List !.
+//	List = Item ( "," _ Item )* _ { return nil, nil }
+//	Item = Word / Number / "(" List ")"
+//	Word = [\pL_] [\pL\pNd_]*
+//	Number = "0x"i [0-9a-f]i+ / [0-9]+
+//	_ "whitespace" = [ \t]*
+func testGrammar() *ast.Grammar {
+	asttest.Reset()
+	return asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("List"), asttest.Not(asttest.Any()))),
+		asttest.Rule("List", asttest.Action(asttest.Seq(
+			asttest.Ref("Item"),
+			asttest.Star(asttest.Seq(asttest.Lit(","), asttest.Ref("_"), asttest.Ref("Item"))),
+			asttest.Ref("_"),
+		), "{ return nil, nil }")),
+		asttest.Rule("Item", asttest.Choice(
+			asttest.Ref("Word"),
+			asttest.Ref("Number"),
+			asttest.Seq(asttest.Lit("("), asttest.Ref("List"), asttest.Lit(")")),
+		)),
+		asttest.Rule("Word", asttest.Seq(asttest.Class(`[\pL_]`), asttest.Star(asttest.Class(`[\pL\pNd_]`)))),
+		asttest.Rule("Number", asttest.Choice(
+			asttest.Seq(asttest.LitI("0x"), asttest.Plus(asttest.Class("[0-9a-f]i"))),
+			asttest.Plus(asttest.Class("[0-9]")),
+		)),
+		asttest.NamedRule("_", "whitespace", asttest.Star(asttest.Class(`[ \t]`))),
+	)
+}
+
+func TestParse(t *testing.T) {
+	g := testGrammar()
+
+	cases := []struct {
+		input string
+		opts  []runtime.Option
+		want  string
+	}{
+		{
+			input: "a,0XfF",
+			want: `Start 1:1 (0-6) "a,0XfF"
+  List 1:1 (0-6) "a,0XfF"
+    Item 1:1 (0-1) "a"
+      Word 1:1 (0-1) "a"
+    _ 1:3 (2-2) ""
+    Item 1:3 (2-6) "0XfF"
+      Number 1:3 (2-6) "0XfF"
+    _ 1:7 (6-6) ""
+`,
+		},
+		{
+			input: "(1)",
+			opts:  []runtime.Option{runtime.Entrypoint("Item")},
+			want: `Item 1:1 (0-3) "(1)"
+  List 1:2 (1-2) "1"
+    Item 1:2 (1-2) "1"
+      Number 1:2 (1-2) "1"
+    _ 1:3 (2-2) ""
+`,
+		},
+		{
+			input: "a,",
+			want:  `file:1:3 (2): no match found, expected: "(", "0x"i, [ \t], [0-9] or [\pL_]`,
+		},
+		{
+			input: "(1",
+			opts:  []runtime.Option{runtime.Entrypoint("Item")},
+			want:  `file:1:3 (2): no match found, expected: ")", ",", [ \t] or [0-9]`,
+		},
+		{
+			input: "((((1))))",
+			opts:  []runtime.Option{runtime.MaxExpressions(20)},
+			want:  `file:1:1 (0): rule Number: max number of expresssions parsed`,
+		},
+	}
+	for _, c := range cases {
+		n, err := Parse(g, "file", []byte(c.input), c.opts...)
+		var got string
+		if err != nil {
+			got = err.Error()
+		} else {
+			got = n.String()
+		}
+		if got != c.want {
+			t.Errorf("%q: want\n%s\ngot\n%s", c.input, c.want, got)
+		}
+	}
+}
+
+func TestParseLeftRecursion(t *testing.T) {
+	// Expr = Expr "-" Term / Term
+	// Term = [0-9]
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("Expr", asttest.Choice(asttest.Seq(asttest.Ref("Expr"), asttest.Lit("-"), asttest.Ref("Term")), asttest.Ref("Term"))),
+		asttest.Rule("Term", asttest.Class("[0-9]")),
+	)
+	if err := ast.MarkLeftRecursion(g); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Parse(g, "", []byte("1-2-3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Expr 1:1 (0-5) "1-2-3"
+  Expr 1:1 (0-3) "1-2"
+    Expr 1:1 (0-1) "1"
+      Term 1:1 (0-1) "1"
+    Term 1:3 (2-3) "2"
+  Term 1:5 (4-5) "3"
+`
+	if got := n.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestParseRuleParams(t *testing.T) {
+	// Start = Sep<Digit, ","> !.
+	// Sep<X, S> = X ( S X )*
+	// Digit = [0-9]
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("Sep", asttest.Ref("Digit"), asttest.Lit(",")), asttest.Not(asttest.Any()))),
+		asttest.ParamRule("Sep", asttest.Seq(
+			asttest.Ref("X"),
+			asttest.Star(asttest.Seq(asttest.Ref("S"), asttest.Ref("X"))),
+		), "X", "S"),
+		asttest.Rule("Digit", asttest.Class("[0-9]")),
+	)
+	if err := ast.ExpandRuleParams(g); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Parse(g, "", []byte("1,2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Start 1:1 (0-3) "1,2"
+  Sep_1 1:1 (0-3) "1,2"
+    Digit 1:1 (0-1) "1"
+    Digit 1:3 (2-3) "2"
+`
+	if got := n.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
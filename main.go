@@ -7,14 +7,20 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math/rand"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/imports"
 
 	"github.com/mna/pigeon/ast"
 	"github.com/mna/pigeon/builder"
+	"github.com/mna/pigeon/interp"
+	"github.com/mna/pigeon/runtime"
+	"github.com/mna/pigeon/sample"
 )
 
 // exit function mockable for tests
@@ -34,22 +40,68 @@ func (r *ruleNamesFlag) Set(value string) error {
 	return nil
 }
 
+// applyOptionDirectives sets the flags of fs from the @option directives
+// of the grammar, e.g. "@option receiver-name self", as parsed by
+// builder.OptionDirectives. The flags set on the command line take
+// precedence over the directives. It returns an error of type
+// ast.ValidationErrors if a directive is invalid.
+func applyOptionDirectives(fs *flag.FlagSet, g *ast.Grammar) error {
+	cmdLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		cmdLine[f.Name] = true
+	})
+
+	dirs, err := builder.OptionDirectives(g)
+	if err != nil {
+		return err
+	}
+	var errs ast.ValidationErrors
+	for _, d := range dirs {
+		if cmdLine[d.Name] {
+			continue
+		}
+		if err := fs.Set(d.Name, d.Value); err != nil {
+			errs = append(errs, &ast.ValidationError{Pos: d.Directive.Pos(), Code: ast.CodeInvalidDirective, Msg: fmt.Sprintf("invalid value %q for option %s", d.Value, d.Name)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func main() {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
 	// define command-line flags
 	var (
 		cacheFlag              = fs.Bool("cache", false, "cache parsing results")
+		checkFlag              = fs.Bool("check", false, "check that the output file is up to date instead of writing it")
 		dbgFlag                = fs.Bool("debug", false, "set debug mode")
+		diagnosticsFormat      = fs.String("diagnostics-format", "", "format of the grammar errors and warnings: json or sarif")
+		fuzzCorpus             = fs.String("fuzz-corpus", "testdata", "directory of the example inputs that seed the corpus of the fuzz test")
+		fuzzMaxExpressions     = fs.Uint64("fuzz-max-expressions", 100000, "maximum number of expressions parsed for an input by the fuzz test")
+		fuzzTestFlag           = fs.String("fuzz-test", "", "write a native Go fuzz test of the parser to this file")
+		generateSamples        = fs.Int("generate-samples", 0, "print N random inputs accepted by the grammar instead of generating the parser")
 		shortHelpFlag          = fs.Bool("h", false, "show help page")
 		longHelpFlag           = fs.Bool("help", false, "show help page")
+		identifierPrefix       = fs.String("identifier-prefix", "", "prefix of the package-level identifiers of the generated parser")
+		inferLabelTypes        = fs.Bool("infer-label-types", false, "pass labels of terminals, sequences and repetitions to code blocks with their concrete type")
+		lineDirectives         = fs.Bool("line-directives", false, "add //line directives that map the code blocks to their position in the grammar")
+		nativeFunctions        = fs.Bool("native-functions", false, "compile each rule to a method of the parser instead of an interpreted expression table")
 		nolint                 = fs.Bool("nolint", false, "add '// nolint: ...' comments to suppress warnings by gometalinter")
 		noRecoverFlag          = fs.Bool("no-recover", false, "do not recover from panic")
 		outputFlag             = fs.String("o", "", "output file, defaults to stdout")
+		parseFlag              = fs.String("parse", "", "parse INPUT with the grammar and print the parse tree instead of generating the parser")
 		optimizeBasicLatinFlag = fs.Bool("optimize-basic-latin", false, "generate optimized parser for Unicode Basic Latin character sets")
 		optimizeGrammar        = fs.Bool("optimize-grammar", false, "optimize the given grammar (EXPERIMENTAL FEATURE)")
 		optimizeParserFlag     = fs.Bool("optimize-parser", false, "generate optimized parser without Debug and Memoize options")
 		recvrNmFlag            = fs.String("receiver-name", "c", "receiver name for the generated methods")
+		samplesSeed            = fs.Int64("samples-seed", 0, "seed of the random inputs printed by -generate-samples, defaults to a random seed")
+		sharedRuntime          = fs.Bool("shared-runtime", false, "generate a parser that imports the runtime package of pigeon instead of embedding it")
+		supportLeftRecursion   = fs.Bool("support-left-recursion", false, "support left-recursive rules in the grammar")
+		vmFlag                 = fs.Bool("vm", false, "generate a parser that runs the grammar on a stack-safe virtual machine")
+		warningsAsErrors       = fs.Bool("warnings-as-errors", false, "treat grammar warnings as errors")
 		noBuildFlag            = fs.Bool("x", false, "do not build, only parse")
 
 		altEntrypointsFlag ruleNamesFlag
@@ -63,6 +115,16 @@ func main() {
 		exit(6)
 	}
 
+	// in test mode, e.g. "pigeon test grammar.peg", the tests declared in the
+	// grammar are run instead of generating the parser
+	testMode := fs.NArg() > 0 && fs.Arg(0) == "test"
+	if testMode {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+			exit(6)
+		}
+	}
+
 	if *shortHelpFlag || *longHelpFlag {
 		fs.Usage()
 		exit(0)
@@ -71,6 +133,38 @@ func main() {
 	if fs.NArg() > 1 {
 		argError(1, "expected one argument, got %q", strings.Join(fs.Args(), " "))
 	}
+	if !validDiagnosticsFormat(*diagnosticsFormat) {
+		argError(1, "invalid diagnostics format %q, expected json or sarif", *diagnosticsFormat)
+	}
+
+	// in check mode, the parser is generated again with the options recorded
+	// in the output file, unless set on the command line
+	var current []byte
+	if *checkFlag {
+		if *outputFlag == "" {
+			argError(1, "-check requires the -o flag")
+		}
+		if *noBuildFlag {
+			argError(1, "-check cannot be combined with -x")
+		}
+		if *parseFlag != "" {
+			argError(1, "-check cannot be combined with -parse")
+		}
+		if testMode {
+			argError(1, "-check cannot be combined with test")
+		}
+		if *fuzzTestFlag != "" {
+			argError(1, "-check cannot be combined with -fuzz-test")
+		}
+		if *generateSamples > 0 {
+			argError(1, "-check cannot be combined with -generate-samples")
+		}
+		current = readOutput(*outputFlag)
+		if err := applyRecordedOptions(fs, builder.RecordedOptions(current)); err != nil {
+			argError(1, "invalid options recorded in %s: %v", *outputFlag, err)
+		}
+	}
+	genOpts := generatorOptions(fs)
 
 	// get input source
 	infile := ""
@@ -91,37 +185,125 @@ func main() {
 		}
 	}()
 
+	// errors and warnings found in the grammar
+	diags := &diagnostics{w: os.Stderr, format: *diagnosticsFormat, file: nm}
+	flushDiags := func() {
+		if err := diags.flush(); err != nil {
+			fmt.Fprintln(os.Stderr, "write error: ", err)
+		}
+	}
+	reportErrors := func(exitCode int, title string, err error) {
+		diags.report(title, severityError, err)
+		flushDiags()
+		exit(exitCode)
+	}
+
 	// parse input
-	g, err := ParseReader(nm, rc, Debug(*dbgFlag), Memoize(*cacheFlag), Recover(!*noRecoverFlag))
+	parseOpts := []Option{Debug(*dbgFlag), Memoize(*cacheFlag), Recover(!*noRecoverFlag)}
+	g, err := ParseReader(nm, rc, append(parseOpts, GlobalStore(filenameKey, infile))...)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "parse error(s):\n", err)
-		exit(3)
+		reportErrors(3, "parse error(s)", err)
 	}
 
-	// validate alternate entrypoints
+	// the grammars imported by the grammar are merged into it, and duplicate
+	// rules and labels, in the same file or not, are reported as parse
+	// errors, as the grammar is ambiguous
 	grammar := g.(*ast.Grammar)
-	rules := make(map[string]struct{}, len(grammar.Rules))
-	for _, rule := range grammar.Rules {
-		rules[rule.Name.Val] = struct{}{}
+	if err := importGrammars(grammar, infile, parseOpts...); err != nil {
+		reportErrors(3, "parse error(s)", err)
 	}
-	for _, entrypoint := range altEntrypointsFlag {
-		if entrypoint == "" {
-			continue
+	if err := ast.CheckDuplicates(grammar); err != nil {
+		reportErrors(3, "parse error(s)", err)
+	}
+
+	// apply the options set in the grammar, unless set on the command line
+	if err := applyOptionDirectives(fs, grammar); err != nil {
+		reportErrors(3, "parse error(s)", err)
+	}
+
+	// the parameterized rules are replaced by their instances
+	if err := ast.ExpandRuleParams(grammar, altEntrypointsFlag...); err != nil {
+		reportErrors(3, "parse error(s)", err)
+	}
+
+	// validate alternate entrypoints
+	if err := checkEntrypoints(grammar, altEntrypointsFlag); err != nil {
+		reportErrors(9, "argument error(s)", err)
+	}
+
+	// validate the grammar before any code is generated
+	err = ast.Validate(grammar)
+	if err == nil {
+		if *supportLeftRecursion {
+			err = ast.MarkLeftRecursion(grammar)
+		} else {
+			err = ast.CheckLeftRecursion(grammar)
 		}
+	}
+	if err != nil {
+		reportErrors(10, "validation error(s)", err)
+	}
 
-		if _, ok := rules[entrypoint]; !ok {
-			fmt.Fprintf(os.Stderr, "argument error:\nunknown rule name %s used as alternate entrypoint\n", entrypoint)
-			exit(9)
+	// the generated parser stops repetitions that make no progress, and
+	// unreachable rules, shadowed alternatives and unmatched failure labels
+	// do not prevent the parser from working, so those are only reported
+	// as warnings, unless requested otherwise.
+	var warnings ast.ValidationErrors
+	for _, err := range []error{
+		ast.CheckRepetitions(grammar),
+		ast.CheckUnreachableRules(grammar, altEntrypointsFlag...),
+		ast.CheckShadowedAlternatives(grammar),
+		ast.CheckFailureLabels(grammar, altEntrypointsFlag...),
+	} {
+		if errs, ok := err.(ast.ValidationErrors); ok {
+			warnings = append(warnings, errs...)
+		}
+	}
+	if len(warnings) > 0 {
+		if *warningsAsErrors {
+			reportErrors(10, "validation error(s)", warnings)
 		}
+		diags.report("warning(s)", severityWarning, warnings)
 	}
 
-	if !*noBuildFlag {
-		if *optimizeGrammar {
-			ast.Optimize(grammar, altEntrypointsFlag...)
+	// the tests and the input are run by interpreting the grammar, the
+	// options of the generated parser do not apply. The diagnostics are
+	// written at once when all of them are known.
+	if testMode {
+		tests, err := grammarTests(grammar)
+		if err != nil {
+			reportErrors(10, "validation error(s)", err)
+		}
+		flushDiags()
+		if runGrammarTests(os.Stdout, grammar, tests, runtime.Recover(!*noRecoverFlag)) > 0 {
+			exit(13)
 		}
+		return
+	}
+	if *parseFlag != "" {
+		flushDiags()
+		parseInput(*parseFlag, grammar, runtime.Recover(!*noRecoverFlag))
+		return
+	}
+	if *generateSamples > 0 {
+		flushDiags()
+		printSamples(grammar, *generateSamples, *samplesSeed)
+		return
+	}
+
+	if *noBuildFlag {
+		flushDiags()
+		return
+	}
 
-		// generate parser
-		out := output(*outputFlag)
+	if *optimizeGrammar {
+		ast.Optimize(grammar, altEntrypointsFlag...)
+	}
+
+	// generate parser
+	var out io.WriteCloser
+	if !*checkFlag {
+		out = output(*outputFlag)
 		defer func() {
 			err := out.Close()
 			if err != nil {
@@ -129,44 +311,92 @@ func main() {
 				exit(8)
 			}
 		}()
+	}
 
-		outBuf := bytes.NewBuffer([]byte{})
-
-		curNmOpt := builder.ReceiverName(*recvrNmFlag)
-		optimizeParser := builder.Optimize(*optimizeParserFlag)
-		basicLatinOptimize := builder.BasicLatinLookupTable(*optimizeBasicLatinFlag)
-		nolintOpt := builder.Nolint(*nolint)
-		if err := builder.BuildParser(outBuf, grammar, curNmOpt, optimizeParser, basicLatinOptimize, nolintOpt); err != nil {
-			fmt.Fprintln(os.Stderr, "build error: ", err)
-			exit(5)
+	outBuf := bytes.NewBuffer([]byte{})
+
+	curNmOpt := builder.ReceiverName(*recvrNmFlag)
+	optimizeParser := builder.Optimize(*optimizeParserFlag)
+	basicLatinOptimize := builder.BasicLatinLookupTable(*optimizeBasicLatinFlag)
+	nolintOpt := builder.Nolint(*nolint)
+	leftRecursionOpt := builder.SupportLeftRecursion(*supportLeftRecursion)
+	inferLabelTypesOpt := builder.InferLabelTypes(*inferLabelTypes)
+	nativeFunctionsOpt := builder.NativeFunctions(*nativeFunctions)
+	vmOpt := builder.VirtualMachine(*vmFlag)
+	prefixOpt := builder.IdentifierPrefix(*identifierPrefix)
+	sharedRuntimeOpt := builder.SharedRuntime(*sharedRuntime)
+	var lineGrammar, lineOutput string
+	if *lineDirectives {
+		if infile == "" {
+			argError(1, "-line-directives requires a grammar file")
 		}
-
-		// Defaults from golang.org/x/tools/cmd/goimports
-		options := &imports.Options{
-			TabWidth:  8,
-			TabIndent: true,
-			Comments:  true,
-			Fragment:  true,
+		lineGrammar, lineOutput = builder.LineDirectiveNames(infile, *outputFlag)
+	}
+	lineDirectivesOpt := builder.LineDirectives(lineGrammar, lineOutput)
+	genOptsOpt := builder.GeneratorOptions(genOpts)
+	var fuzzBuf bytes.Buffer
+	fuzzTestOpt := builder.FuzzTest(nil, "", 0)
+	if *fuzzTestFlag != "" {
+		fuzzTestOpt = builder.FuzzTest(&fuzzBuf, *fuzzCorpus, *fuzzMaxExpressions, altEntrypointsFlag...)
+	}
+	if err := builder.BuildParser(outBuf, grammar, curNmOpt, optimizeParser, basicLatinOptimize, nolintOpt, leftRecursionOpt, inferLabelTypesOpt, nativeFunctionsOpt, vmOpt, prefixOpt, sharedRuntimeOpt, lineDirectivesOpt, genOptsOpt, fuzzTestOpt); err != nil {
+		if _, ok := err.(ast.ValidationErrors); !ok {
+			err = &codedError{code: codeBuildError, err: err}
 		}
+		reportErrors(5, "build error(s)", err)
+	}
+	flushDiags()
+
+	// Defaults from golang.org/x/tools/cmd/goimports
+	options := &imports.Options{
+		TabWidth:  8,
+		TabIndent: true,
+		Comments:  true,
+		Fragment:  true,
+	}
 
-		formattedBuf, err := imports.Process("filename", outBuf.Bytes(), options)
-		if err != nil {
+	formattedBuf, err := imports.Process("filename", outBuf.Bytes(), options)
+	if err != nil {
+		if out != nil {
 			if _, err := out.Write(outBuf.Bytes()); err != nil {
 				fmt.Fprintln(os.Stderr, "write error: ", err)
 				exit(7)
 			}
-			fmt.Fprintln(os.Stderr, "format error: ", err)
-			exit(6)
 		}
+		fmt.Fprintln(os.Stderr, "format error: ", err)
+		exit(6)
+	}
 
-		if _, err := out.Write(formattedBuf); err != nil {
+	if lineGrammar != "" {
+		// the formatting changes the lines of the generated code
+		formattedBuf = builder.RestoreLineDirectives(formattedBuf, lineOutput)
+	}
+	if *checkFlag {
+		if diff := unifiedDiff(*outputFlag, *outputFlag+" (generated)", current, formattedBuf); diff != "" {
+			fmt.Print(diff)
+			fmt.Fprintf(os.Stderr, "check error:\n%s is not up to date with the grammar\n", *outputFlag)
+			exit(11)
+		}
+	} else if _, err := out.Write(formattedBuf); err != nil {
+		fmt.Fprintln(os.Stderr, "write error: ", err)
+		exit(7)
+	}
+
+	if *fuzzTestFlag != "" {
+		f := output(*fuzzTestFlag)
+		if _, err := f.Write(fuzzBuf.Bytes()); err != nil {
 			fmt.Fprintln(os.Stderr, "write error: ", err)
 			exit(7)
 		}
+		if err := f.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "close file error:\n", err)
+			exit(8)
+		}
 	}
 }
 
-var usagePage = `usage: %s [options] [GRAMMAR_FILE]
+var usagePage = `usage: %[1]s [options] [GRAMMAR_FILE]
+       %[1]s test [options] [GRAMMAR_FILE]
 
 Pigeon generates a parser based on a PEG grammar.
 
@@ -175,14 +405,65 @@ generated parser to stdout. If GRAMMAR_FILE is specified, the
 grammar is read from this file instead. If the -o flag is set,
 the generated code is written to this file instead.
 
+The test command runs the tests declared in the grammar with @test
+directives instead of generating the parser, e.g.:
+	@test Number accepts "1.5e3"
+	@test Number rejects "01"
+It prints the failed tests and exits with a non-zero status code if
+any test fails.
+
 	-cache
 		cache parser results to avoid exponential parsing time in
 		pathological cases. Can make the parsing slower for typical
 		cases and uses more memory.
+	-check
+		generate the parser again in memory with the options recorded
+		in OUTPUT_FILE and compare it to OUTPUT_FILE instead of writing
+		it. If they differ, print a unified diff and exit with a
+		non-zero status code. Requires the -o flag.
 	-debug
 		output debugging information while parsing the grammar.
+	-diagnostics-format FORMAT
+		write the errors and warnings found in the grammar to stderr
+		in a machine-readable format instead of free text. FORMAT is
+		either json or sarif (SARIF 2.1.0).
+	-fuzz-corpus DIR
+		directory of the example inputs that seed the corpus of the
+		fuzz test generated with -fuzz-test, relative to the package
+		of the parser. Defaults to "testdata".
+	-fuzz-max-expressions N
+		maximum number of expressions parsed for an input by the fuzz
+		test generated with -fuzz-test. Defaults to 100000.
+	-fuzz-test FILE
+		also write to FILE a test file with a native Go fuzz test for
+		each entrypoint of the parser, FuzzParse for the first rule and
+		FuzzParse followed by the rule name for the alternate ones. The
+		fuzz tests fail on panics and on errors without a position.
+	-generate-samples N
+		print N random inputs accepted by the first rule of the grammar
+		to stdout instead of generating the parser, one per line as a
+		Go string literal. The grammar is interpreted without running
+		its code blocks. See also -samples-seed.
 	-h -help
 		display this help message.
+	-identifier-prefix PREFIX
+		prefix the package-level identifiers of the generated parser
+		with PREFIX, so that several parsers can be generated in the
+		same package. Exported identifiers stay exported and unexported
+		ones stay unexported, e.g. Parse becomes PrefixParse.
+	-infer-label-types
+		pass the labels bound to terminals, sequences and repetitions
+		to the code blocks as []byte or []interface{} instead of
+		interface{}.
+	-line-directives
+		add //line directives around the code blocks of the grammar,
+		so that the compiler errors, stack traces and coverage of the
+		code blocks refer to their position in the grammar file.
+	-native-functions
+		compile each rule of the grammar to a method of the parser,
+		with typed values and labels as local variables, instead of
+		a table of expressions interpreted at runtime. The generated
+		parser is larger, has the same API and results, and is faster.
 	-nolint
 		add '// nolint: ...' comments for generated parser to suppress
 		warnings by gometalinter (https://github.com/alecthomas/gometalinter).
@@ -198,9 +479,35 @@ the generated code is written to this file instead.
 	-optimize-parser
 		generate optimized parser without Debug and Memoize options and
 		with some other optimizations applied.
+	-parse INPUT
+		parse the INPUT file with the grammar and print the parse tree
+		to stdout instead of generating the parser. The grammar is
+		interpreted without running its code blocks. If INPUT does not
+		match the grammar, the errors are printed to stderr.
 	-receiver-name NAME
 		use NAME as for the receiver name of the generated methods
 		for the grammar's code blocks. Defaults to "c".
+	-samples-seed SEED
+		seed of the random inputs printed by -generate-samples, so that
+		the same inputs can be generated again. Defaults to a random
+		seed.
+	-shared-runtime
+		generate a parser that contains only the grammar and its code
+		blocks, and imports the parser, its options and errors from
+		the github.com/mna/pigeon/runtime package instead of embedding
+		a copy of them.
+	-support-left-recursion
+		allow left-recursive rules in the grammar. The generated parser
+		grows the result of left-recursive rules until the longest
+		match is found.
+	-vm
+		generate a parser that runs the grammar as a program on a
+		virtual machine with an explicit stack instead of recursive
+		calls, so that deeply nested input cannot overflow the
+		goroutine stack. The results and errors are the same.
+	-warnings-as-errors
+		treat the warnings reported for the grammar, such as unused
+		or unreachable rules, as errors.
 	-x
 		do not generate the parser, only parse the grammar.
  	-alternate-entrypoints RULE[,RULE...]
@@ -208,6 +515,11 @@ the generated code is written to this file instead.
 		entrypoints for the parser, in addition to the first rule in the
 		grammar.
 
+The options that affect the generated parser, e.g. -nolint or
+-receiver-name, may also be set in the grammar with @option
+directives, the command-line flags taking precedence. Those set on
+the command line are recorded in the header of the generated parser.
+
 See https://godoc.org/github.com/mna/pigeon for more information.
 `
 
@@ -225,6 +537,128 @@ func argError(exitCode int, msg string, args ...interface{}) {
 	exit(exitCode)
 }
 
+// generatorOptions returns the options set on the command line that
+// affect the generated parser, in a form that can be parsed again by
+// applyRecordedOptions, e.g. "-nolint" or "-receiver-name=self".
+func generatorOptions(fs *flag.FlagSet) []string {
+	var opts []string
+	fs.Visit(func(f *flag.Flag) {
+		if !builder.IsOptionDirective(f.Name) {
+			return
+		}
+		val := f.Value.String()
+		if names, ok := f.Value.(*ruleNamesFlag); ok {
+			val = strings.Join(*names, ",")
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() && val == "true" {
+			opts = append(opts, "-"+f.Name)
+			return
+		}
+		opts = append(opts, "-"+f.Name+"="+val)
+	})
+	return opts
+}
+
+// applyRecordedOptions sets the flags of fs from the options recorded in
+// a generated parser by generatorOptions. The flags set on the command
+// line take precedence over the recorded options.
+func applyRecordedOptions(fs *flag.FlagSet, opts []string) error {
+	cmdLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		cmdLine[f.Name] = true
+	})
+
+	for _, opt := range opts {
+		nm, val := strings.TrimPrefix(opt, "-"), "true"
+		if ix := strings.Index(nm, "="); ix >= 0 {
+			nm, val = nm[:ix], nm[ix+1:]
+		}
+		if !strings.HasPrefix(opt, "-") || fs.Lookup(nm) == nil || !builder.IsOptionDirective(nm) {
+			return fmt.Errorf("unknown option %s", opt)
+		}
+		if cmdLine[nm] {
+			continue
+		}
+		if err := fs.Set(nm, val); err != nil {
+			return fmt.Errorf("invalid value %q for option %s", val, nm)
+		}
+	}
+	return nil
+}
+
+// parseInput parses the file input with the grammar g and prints the parse
+// tree to stdout, or the parse errors to stderr.
+func parseInput(input string, g *ast.Grammar, opts ...runtime.Option) {
+	b, err := ioutil.ReadFile(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open error: ", err)
+		exit(2)
+	}
+	n, err := interp.Parse(g, input, b, opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "input parse error(s):\n", err)
+		exit(12)
+	}
+	if err := n.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "write error: ", err)
+		exit(7)
+	}
+}
+
+// checkEntrypoints returns an error if an alternate entrypoint is not a
+// rule of the grammar g. The entrypoints set by an @option directive are
+// reported at the position of the directive.
+func checkEntrypoints(g *ast.Grammar, entrypoints []string) error {
+	rules := make(map[string]bool, len(g.Rules))
+	for _, rule := range g.Rules {
+		rules[rule.Name.Val] = true
+	}
+	positions := make(map[string]ast.Pos)
+	dirs, _ := builder.OptionDirectives(g)
+	for _, d := range dirs {
+		if d.Name == "alternate-entrypoints" {
+			for _, nm := range strings.Split(d.Value, ",") {
+				positions[nm] = d.Directive.Pos()
+			}
+		}
+	}
+
+	var errs errList
+	for _, entrypoint := range entrypoints {
+		if entrypoint == "" || rules[entrypoint] {
+			continue
+		}
+		msg := fmt.Sprintf("unknown rule name %s used as alternate entrypoint", entrypoint)
+		if pos, ok := positions[entrypoint]; ok {
+			errs = append(errs, &ast.ValidationError{Pos: pos, Code: codeUnknownEntrypoint, Msg: msg})
+			continue
+		}
+		errs = append(errs, &codedError{code: codeUnknownEntrypoint, err: errors.New(msg)})
+	}
+	return errs.err()
+}
+
+// printSamples prints n random inputs accepted by the first rule of the
+// grammar g to stdout, one per line as a Go string literal. The inputs are
+// generated with the seed, or a random seed if it is 0.
+func printSamples(g *ast.Grammar, n int, seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	gen := sample.New(g, rand.New(rand.NewSource(seed)))
+	for i := 0; i < n; i++ {
+		s, err := gen.Sample(g.Rules[0].Name.Val)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "sample error:\n", err)
+			exit(14)
+		}
+		if _, err := fmt.Println(strconv.Quote(s)); err != nil {
+			fmt.Fprintln(os.Stderr, "write error: ", err)
+			exit(7)
+		}
+	}
+}
+
 // input gets the name and reader to get input text from.
 func input(filename string) (nm string, rc io.ReadCloser) {
 	nm = "stdin"
@@ -242,6 +676,17 @@ func input(filename string) (nm string, rc io.ReadCloser) {
 	return nm, makeReadCloser(r, inf)
 }
 
+// readOutput returns the content of the generated parser filename, or nil
+// if it does not exist.
+func readOutput(filename string) []byte {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintln(os.Stderr, err)
+		exit(2)
+	}
+	return b
+}
+
 // output gets the writer to write the generated parser to.
 func output(filename string) io.WriteCloser {
 	out := os.Stdout
@@ -266,9 +711,11 @@ func makeReadCloser(r io.Reader, c io.Closer) io.ReadCloser {
 }
 
 // astPos is a helper method for the PEG grammar parser. It returns the
-// position of the current match as an ast.Pos.
+// position of the current match as an ast.Pos, in the file set in the
+// global store under filenameKey, if any.
 func (c *current) astPos() ast.Pos {
-	return ast.Pos{Line: c.pos.line, Col: c.pos.col, Off: c.pos.offset}
+	filename, _ := c.globalStore[filenameKey].(string)
+	return ast.Pos{Filename: filename, Line: c.pos.line, Col: c.pos.col, Off: c.pos.offset}
 }
 
 // toIfaceSlice is a helper function for the PEG grammar parser. It converts
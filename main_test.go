@@ -1,15 +1,23 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/mna/pigeon/ast"
 )
 
 func TestMain(t *testing.T) {
 	stdout, stderr := os.Stdout, os.Stderr
-	os.Stdout, _ = os.Open(os.DevNull)
-	os.Stderr, _ = os.Open(os.DevNull)
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
 	defer func() {
 		exit = os.Exit
 		os.Stdout = stdout
@@ -27,6 +35,33 @@ func TestMain(t *testing.T) {
 		{args: "-h", code: 0},          // help
 		{args: "FILE1 FILE2", code: 1}, // want only 1 non-flag arg
 		{args: "-x", code: 3},          // stdin: no match found
+
+		// unused rules are warnings, unless warnings are errors
+		{args: "-x test/staterestore/staterestore.peg", code: 0},
+		{args: "-x -warnings-as-errors test/staterestore/staterestore.peg", code: 10},
+
+		// diagnostics formats
+		{args: "-x -diagnostics-format xml test/staterestore/staterestore.peg", code: 1},
+		{args: "-x -diagnostics-format json test/staterestore/staterestore.peg", code: 0},
+
+		// parameterized rules cannot be entrypoints
+		{args: "-x -alternate-entrypoints Group test/params/params.peg", code: 0},
+		{args: "-x -alternate-entrypoints List test/params/params.peg", code: 3},
+
+		// parse an input with the grammar
+		{args: "-parse test/params/params.peg test/params/params.peg", code: 12},
+		{args: "-parse testdata/missing test/params/params.peg", code: 2},
+
+		// run the tests declared in the grammar
+		{args: "test test/params/params.peg", code: 0},
+		{args: "test -check -o test/params/params.go test/params/params.peg", code: 1},
+
+		// print random inputs accepted by the grammar
+		{args: "-generate-samples 10 -samples-seed 1 test/params/params.peg", code: 0},
+		{args: "-check -generate-samples 10 -o test/params/params.go test/params/params.peg", code: 1},
+
+		// the fuzz test is not checked
+		{args: "-check -fuzz-test test/params/params_fuzz_test.go -o test/params/params.go test/params/params.peg", code: 1},
 	}
 
 	for _, tc := range cases {
@@ -52,3 +87,231 @@ func runMainRecover() (code int) {
 	main()
 	return 0
 }
+
+func TestApplyOptionDirectives(t *testing.T) {
+	cases := []struct {
+		grammar string
+		args    []string
+		want    map[string]string
+		errs    []string
+	}{
+		{
+			grammar: "@option nolint\n@option receiver-name self\n@option alternate-entrypoints B C\nA = B / C\nB = 'b'\nC = 'c'",
+			want:    map[string]string{"nolint": "true", "receiver-name": "self", "alternate-entrypoints": "[B C]"},
+		},
+		{
+			// command-line flags take precedence
+			grammar: "@option optimize-parser\n@option receiver-name self\nA = 'a'",
+			args:    []string{"-optimize-parser=false", "-receiver-name", "x"},
+			want:    map[string]string{"optimize-parser": "false", "receiver-name": "x"},
+		},
+		{
+			grammar: "@option optimize-parser false\nA = 'a'",
+			want:    map[string]string{"optimize-parser": "false"},
+		},
+		{
+			// imports are handled by importGrammars
+			grammar: "@import \"b.peg\"\n@option nolint\nA = 'a'",
+			want:    map[string]string{"nolint": "true"},
+		},
+		{
+			grammar: "@foo\n@option\n@option o out.go\n@option receiver-name\n@option nolint maybe\nA = 'a'",
+			errs: []string{
+				"1:1 (0): unknown directive @foo",
+				"2:1 (5): missing option name",
+				"3:1 (13): unknown option o",
+				"4:1 (30): option receiver-name requires a value",
+				`5:1 (52): invalid value "maybe" for option nolint`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		fs := flag.NewFlagSet("pigeon", flag.ContinueOnError)
+		fs.Bool("nolint", false, "")
+		fs.Bool("optimize-parser", false, "")
+		fs.String("o", "", "")
+		fs.String("receiver-name", "c", "")
+		var altEntrypoints ruleNamesFlag
+		fs.Var(&altEntrypoints, "alternate-entrypoints", "")
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		g, err := Parse("", []byte(tc.grammar))
+		if err != nil {
+			t.Errorf("%q: parse error: %v", tc.grammar, err)
+			continue
+		}
+		err = applyOptionDirectives(fs, g.(*ast.Grammar))
+
+		var errs []string
+		if err != nil {
+			for _, e := range err.(ast.ValidationErrors) {
+				errs = append(errs, e.Error())
+			}
+		}
+		if !reflect.DeepEqual(errs, tc.errs) {
+			t.Errorf("%q: want errors %q, got %q", tc.grammar, tc.errs, errs)
+		}
+		for nm, want := range tc.want {
+			if got := fs.Lookup(nm).Value.String(); got != want {
+				t.Errorf("%q: want %s=%s, got %s", tc.grammar, nm, want, got)
+			}
+		}
+	}
+}
+
+func TestRecordedOptions(t *testing.T) {
+	newFlagSet := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("pigeon", flag.ContinueOnError)
+		fs.Bool("nolint", false, "")
+		fs.Bool("optimize-parser", false, "")
+		fs.String("o", "", "")
+		fs.String("receiver-name", "c", "")
+		var altEntrypoints ruleNamesFlag
+		fs.Var(&altEntrypoints, "alternate-entrypoints", "")
+		return fs
+	}
+
+	fs := newFlagSet()
+	if err := fs.Parse([]string{"-nolint", "-optimize-parser=false", "-o", "out.go", "-receiver-name", "self", "-alternate-entrypoints", "B,C"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-alternate-entrypoints=B,C", "-nolint", "-optimize-parser=false", "-receiver-name=self"}
+	opts := generatorOptions(fs)
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("want options %q, got %q", want, opts)
+	}
+
+	// the flags set on the command line take precedence
+	fs = newFlagSet()
+	if err := fs.Parse([]string{"-receiver-name", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyRecordedOptions(fs, opts); err != nil {
+		t.Fatal(err)
+	}
+	for nm, want := range map[string]string{"alternate-entrypoints": "[B C]", "nolint": "true", "optimize-parser": "false", "receiver-name": "x"} {
+		if got := fs.Lookup(nm).Value.String(); got != want {
+			t.Errorf("want %s=%s, got %s", nm, want, got)
+		}
+	}
+
+	for _, opt := range []string{"-o=out.go", "-unknown", "nolint", "-nolint=maybe"} {
+		if err := applyRecordedOptions(newFlagSet(), []string{opt}); err == nil {
+			t.Errorf("%s: want error, got none", opt)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() {
+		exit = os.Exit
+		os.Stdout = stdout
+		os.Stderr = stderr
+	}()
+	exit = func(code int) {
+		panic(code)
+	}
+
+	dir, err := ioutil.TempDir("", "pigeon-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "staterestore.go")
+
+	run := func(args string, want int) {
+		os.Args = append([]string{"pigeon"}, strings.Fields(args)...)
+		if got := runMainRecover(); got != want {
+			t.Errorf("%q: want code %d, got %d", args, want, got)
+		}
+	}
+	run("-check test/staterestore/staterestore.peg", 1)
+	run("-check -x -o "+out+" test/staterestore/staterestore.peg", 1)
+	run("-check -o "+out+" test/staterestore/staterestore.peg", 11)
+	run("-nolint -receiver-name self -o "+out+" test/staterestore/staterestore.peg", 0)
+
+	// the recorded options are used, unless set on the command line
+	run("-check -o "+out+" test/staterestore/staterestore.peg", 0)
+	run("-check -receiver-name x -o "+out+" test/staterestore/staterestore.peg", 11)
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(out, append(b, "\n// edited\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("-check -o "+out+" test/staterestore/staterestore.peg", 11)
+}
+
+func TestMainDiagnostics(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() {
+		exit = os.Exit
+		os.Stdout = stdout
+		os.Stderr = stderr
+	}()
+	exit = func(code int) {
+		panic(code)
+	}
+
+	dir, err := ioutil.TempDir("", "pigeon-diagnostics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the diagnostics are written as a single JSON document, the last one
+	// being the error
+	cases := []struct {
+		args string
+		code int
+		want diagnostic
+	}{
+		{
+			args: "-alternate-entrypoints Missing test/staterestore/staterestore.peg",
+			code: 9,
+			want: diagnostic{File: "test/staterestore/staterestore.peg", Severity: "error", Code: "unknown-entrypoint", Message: "unknown rule name Missing used as alternate entrypoint"},
+		},
+		{
+			args: "-vm -native-functions test/staterestore/staterestore.peg",
+			code: 5,
+			want: diagnostic{File: "test/staterestore/staterestore.peg", Severity: "error", Code: "build-error", Message: "the native functions and virtual machine modes are mutually exclusive"},
+		},
+	}
+	for i, tc := range cases {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("stderr%d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stderr = f
+		os.Args = append([]string{"pigeon", "-diagnostics-format", "json", "-o", filepath.Join(dir, "out.go")}, strings.Fields(tc.args)...)
+		got := runMainRecover()
+		f.Close()
+		if got != tc.code {
+			t.Errorf("%q: want code %d, got %d", tc.args, tc.code, got)
+		}
+
+		b, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out struct {
+			Diagnostics []diagnostic
+		}
+		if err := json.Unmarshal(b, &out); err != nil {
+			t.Errorf("%q: invalid JSON: %v\n%s", tc.args, err, b)
+			continue
+		}
+		if n := len(out.Diagnostics); n == 0 || out.Diagnostics[n-1] != tc.want {
+			t.Errorf("%q: want last diagnostic %#v, got %#v", tc.args, tc.want, out.Diagnostics)
+		}
+	}
+}
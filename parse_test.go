@@ -7,16 +7,18 @@ import (
 )
 
 var invalidParseCases = map[string]string{
-	"":           `file:1:1 (0): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
-	"a":          `file:1:2 (1): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
-	"abc":        `file:1:4 (3): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
-	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
+	"":           `file:1:1 (0): no match found, expected: "/*", "//", "@", "\n", "{", [ \t\r] or [\pL_]`,
+	"a":          `file:1:2 (1): no match found, expected: "'", "/*", "//", "<", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
+	"abc":        `file:1:4 (3): no match found, expected: "'", "/*", "//", "<", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
+	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "@", "\n", "{", [ \t\r] or [\pL_]`,
 	`a = +`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
 	`a = *`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
 	`a = ?`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
 	"a ←":        `file:1:4 (5): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
 	"a ← b\nb ←": `file:2:4 (13): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
 	"a ← nil:b":  "file:1:5 (6): rule Identifier: identifier is a reserved word",
+	"a < > = b":  "file:1:3 (2): rule RuleType: empty rule type",
+	"{}\n@x":     "file:1:1 (0): rule Grammar: grammar has no rule",
 	"\xfe":       "file:1:1 (0): invalid encoding",
 	"{}{}":       `file:1:3 (2): no match found, expected: "/*", "//", ";", "\n", [ \t\r] or EOF`,
 
@@ -230,6 +232,80 @@ var validParseCases = map[string]*ast.Grammar{
 			},
 		},
 	},
+	"a <int> = b\nc <[]*ast.Rule> \"C\" <- d": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Type: "int",
+				Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+			},
+			{
+				Name:        ast.NewIdentifier(ast.Pos{}, "c"),
+				DisplayName: ast.NewStringLit(ast.Pos{}, `"C"`),
+				Type:        "[]*ast.Rule",
+				Expr:        &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "d")},
+			},
+		},
+	},
+	"List<Item, Sep> = Item ( Sep Item )*\na = List<b, ','> List< c\n>": {
+		Rules: []*ast.Rule{
+			{
+				Name:   ast.NewIdentifier(ast.Pos{}, "List"),
+				Params: []*ast.Identifier{ast.NewIdentifier(ast.Pos{}, "Item"), ast.NewIdentifier(ast.Pos{}, "Sep")},
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "Item")},
+						&ast.ZeroOrMoreExpr{
+							Expr: &ast.SeqExpr{
+								Exprs: []ast.Expression{
+									&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "Sep")},
+									&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "Item")},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.RuleRefExpr{
+							Name: ast.NewIdentifier(ast.Pos{}, "List"),
+							Args: []ast.Expression{
+								&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+								ast.NewLitMatcher(ast.Pos{}, ","),
+							},
+						},
+						&ast.RuleRefExpr{
+							Name: ast.NewIdentifier(ast.Pos{}, "List"),
+							Args: []ast.Expression{
+								&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "c")},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	"@option nolint\n{ init \n}\n@option receiver-name self // c\na = b\n@x \"y z\"; b = c": {
+		Init: ast.NewCodeBlock(ast.Pos{}, "{ init \n}"),
+		Directives: []*ast.Directive{
+			{Name: ast.NewIdentifier(ast.Pos{}, "option"), Args: []string{"nolint"}},
+			{Name: ast.NewIdentifier(ast.Pos{}, "option"), Args: []string{"receiver-name", "self"}},
+			{Name: ast.NewIdentifier(ast.Pos{}, "x"), Args: []string{"y z"}},
+		},
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+			},
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "b"),
+				Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "c")},
+			},
+		},
+	},
 	"{ init \n}\na 'A'← b": {
 		Init: ast.NewCodeBlock(ast.Pos{}, "{ init \n}"),
 		Rules: []*ast.Rule{
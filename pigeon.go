@@ -36,18 +36,38 @@ var g = &grammar{
 						},
 						&labeledExpr{
 							pos:   position{line: 5, col: 14, offset: 33},
+							label: "directives",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 5, col: 25, offset: 44},
+								expr: &seqExpr{
+									pos: position{line: 5, col: 27, offset: 46},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 27, offset: 46},
+											name: "Directive",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 37, offset: 56},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 5, col: 43, offset: 62},
 							label: "initializer",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 5, col: 26, offset: 45},
+								pos: position{line: 5, col: 55, offset: 74},
 								expr: &seqExpr{
-									pos: position{line: 5, col: 28, offset: 47},
+									pos: position{line: 5, col: 57, offset: 76},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 28, offset: 47},
+											pos:  position{line: 5, col: 57, offset: 76},
 											name: "Initializer",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 40, offset: 59},
+											pos:  position{line: 5, col: 69, offset: 88},
 											name: "__",
 										},
 									},
@@ -55,19 +75,28 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 5, col: 46, offset: 65},
-							label: "rules",
+							pos:   position{line: 5, col: 75, offset: 94},
+							label: "elems",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 5, col: 52, offset: 71},
+								pos: position{line: 5, col: 81, offset: 100},
 								expr: &seqExpr{
-									pos: position{line: 5, col: 54, offset: 73},
+									pos: position{line: 5, col: 83, offset: 102},
 									exprs: []interface{}{
-										&ruleRefExpr{
-											pos:  position{line: 5, col: 54, offset: 73},
-											name: "Rule",
+										&choiceExpr{
+											pos: position{line: 5, col: 85, offset: 104},
+											alternatives: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 5, col: 85, offset: 104},
+													name: "Directive",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 5, col: 97, offset: 116},
+													name: "Rule",
+												},
+											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 59, offset: 78},
+											pos:  position{line: 5, col: 104, offset: 123},
 											name: "__",
 										},
 									},
@@ -75,7 +104,7 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 5, col: 65, offset: 84},
+							pos:  position{line: 5, col: 110, offset: 129},
 							name: "EOF",
 						},
 					},
@@ -84,64 +113,217 @@ var g = &grammar{
 		},
 		{
 			name: "Initializer",
-			pos:  position{line: 24, col: 1, offset: 525},
+			pos:  position{line: 35, col: 1, offset: 971},
 			expr: &actionExpr{
-				pos: position{line: 24, col: 15, offset: 541},
+				pos: position{line: 35, col: 15, offset: 987},
 				run: (*parser).callonInitializer1,
 				expr: &seqExpr{
-					pos: position{line: 24, col: 15, offset: 541},
+					pos: position{line: 35, col: 15, offset: 987},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 24, col: 15, offset: 541},
+							pos:   position{line: 35, col: 15, offset: 987},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 24, col: 20, offset: 546},
+								pos:  position{line: 35, col: 20, offset: 992},
 								name: "CodeBlock",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 24, col: 30, offset: 556},
+							pos:  position{line: 35, col: 30, offset: 1002},
+							name: "EOS",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Directive",
+			pos:  position{line: 39, col: 1, offset: 1032},
+			expr: &actionExpr{
+				pos: position{line: 39, col: 13, offset: 1046},
+				run: (*parser).callonDirective1,
+				expr: &seqExpr{
+					pos: position{line: 39, col: 13, offset: 1046},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 39, col: 13, offset: 1046},
+							val:        "@",
+							ignoreCase: false,
+						},
+						&labeledExpr{
+							pos:   position{line: 39, col: 17, offset: 1050},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 39, col: 22, offset: 1055},
+								name: "IdentifierName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 39, col: 37, offset: 1070},
+							label: "args",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 39, col: 42, offset: 1075},
+								expr: &seqExpr{
+									pos: position{line: 39, col: 44, offset: 1077},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 39, col: 44, offset: 1077},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 39, col: 46, offset: 1079},
+											name: "DirectiveArg",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 39, col: 62, offset: 1095},
 							name: "EOS",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "DirectiveArg",
+			pos:  position{line: 47, col: 1, offset: 1300},
+			expr: &choiceExpr{
+				pos: position{line: 47, col: 16, offset: 1317},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 47, col: 16, offset: 1317},
+						run: (*parser).callonDirectiveArg2,
+						expr: &labeledExpr{
+							pos:   position{line: 47, col: 16, offset: 1317},
+							label: "lit",
+							expr: &ruleRefExpr{
+								pos:  position{line: 47, col: 20, offset: 1321},
+								name: "StringLiteral",
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 54, col: 5, offset: 1524},
+						run: (*parser).callonDirectiveArg5,
+						expr: &oneOrMoreExpr{
+							pos: position{line: 54, col: 5, offset: 1524},
+							expr: &seqExpr{
+								pos: position{line: 54, col: 7, offset: 1526},
+								exprs: []interface{}{
+									&notExpr{
+										pos: position{line: 54, col: 7, offset: 1526},
+										expr: &choiceExpr{
+											pos: position{line: 54, col: 10, offset: 1529},
+											alternatives: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 54, col: 10, offset: 1529},
+													name: "Whitespace",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 54, col: 23, offset: 1542},
+													name: "EOL",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 54, col: 29, offset: 1548},
+													name: "EOF",
+												},
+												&litMatcher{
+													pos:        position{line: 54, col: 35, offset: 1554},
+													val:        ";",
+													ignoreCase: false,
+												},
+												&litMatcher{
+													pos:        position{line: 54, col: 41, offset: 1560},
+													val:        "//",
+													ignoreCase: false,
+												},
+												&litMatcher{
+													pos:        position{line: 54, col: 48, offset: 1567},
+													val:        "/*",
+													ignoreCase: false,
+												},
+											},
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 54, col: 55, offset: 1574},
+										name: "SourceChar",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Rule",
-			pos:  position{line: 28, col: 1, offset: 586},
+			pos:  position{line: 58, col: 1, offset: 1624},
 			expr: &actionExpr{
-				pos: position{line: 28, col: 8, offset: 595},
+				pos: position{line: 58, col: 8, offset: 1633},
 				run: (*parser).callonRule1,
 				expr: &seqExpr{
-					pos: position{line: 28, col: 8, offset: 595},
+					pos: position{line: 58, col: 8, offset: 1633},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 28, col: 8, offset: 595},
+							pos:   position{line: 58, col: 8, offset: 1633},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 13, offset: 600},
+								pos:  position{line: 58, col: 13, offset: 1638},
 								name: "IdentifierName",
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 58, col: 28, offset: 1653},
+							label: "params",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 58, col: 35, offset: 1660},
+								expr: &ruleRefExpr{
+									pos:  position{line: 58, col: 35, offset: 1660},
+									name: "RuleParams",
+								},
+							},
+						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 28, offset: 615},
+							pos:  position{line: 58, col: 47, offset: 1672},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 31, offset: 618},
+							pos:   position{line: 58, col: 50, offset: 1675},
+							label: "typ",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 58, col: 54, offset: 1679},
+								expr: &seqExpr{
+									pos: position{line: 58, col: 56, offset: 1681},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 58, col: 56, offset: 1681},
+											name: "RuleType",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 58, col: 65, offset: 1690},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 58, col: 71, offset: 1696},
 							label: "display",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 28, col: 39, offset: 626},
+								pos: position{line: 58, col: 79, offset: 1704},
 								expr: &seqExpr{
-									pos: position{line: 28, col: 41, offset: 628},
+									pos: position{line: 58, col: 81, offset: 1706},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 41, offset: 628},
+											pos:  position{line: 58, col: 81, offset: 1706},
 											name: "StringLiteral",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 55, offset: 642},
+											pos:  position{line: 58, col: 95, offset: 1720},
 											name: "__",
 										},
 									},
@@ -149,23 +331,23 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 61, offset: 648},
+							pos:  position{line: 58, col: 101, offset: 1726},
 							name: "RuleDefOp",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 71, offset: 658},
+							pos:  position{line: 58, col: 111, offset: 1736},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 74, offset: 661},
+							pos:   position{line: 58, col: 114, offset: 1739},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 79, offset: 666},
+								pos:  position{line: 58, col: 119, offset: 1744},
 								name: "Expression",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 90, offset: 677},
+							pos:  position{line: 58, col: 130, offset: 1755},
 							name: "EOS",
 						},
 					},
@@ -174,69 +356,69 @@ var g = &grammar{
 		},
 		{
 			name: "Expression",
-			pos:  position{line: 41, col: 1, offset: 961},
+			pos:  position{line: 78, col: 1, offset: 2228},
 			expr: &ruleRefExpr{
-				pos:  position{line: 41, col: 14, offset: 976},
+				pos:  position{line: 78, col: 14, offset: 2243},
 				name: "RecoveryExpr",
 			},
 		},
 		{
 			name: "RecoveryExpr",
-			pos:  position{line: 43, col: 1, offset: 990},
+			pos:  position{line: 80, col: 1, offset: 2257},
 			expr: &actionExpr{
-				pos: position{line: 43, col: 16, offset: 1007},
+				pos: position{line: 80, col: 16, offset: 2274},
 				run: (*parser).callonRecoveryExpr1,
 				expr: &seqExpr{
-					pos: position{line: 43, col: 16, offset: 1007},
+					pos: position{line: 80, col: 16, offset: 2274},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 43, col: 16, offset: 1007},
+							pos:   position{line: 80, col: 16, offset: 2274},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 43, col: 21, offset: 1012},
+								pos:  position{line: 80, col: 21, offset: 2279},
 								name: "ChoiceExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 43, col: 32, offset: 1023},
+							pos:   position{line: 80, col: 32, offset: 2290},
 							label: "recoverExprs",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 43, col: 45, offset: 1036},
+								pos: position{line: 80, col: 45, offset: 2303},
 								expr: &seqExpr{
-									pos: position{line: 43, col: 47, offset: 1038},
+									pos: position{line: 80, col: 47, offset: 2305},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 47, offset: 1038},
+											pos:  position{line: 80, col: 47, offset: 2305},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 50, offset: 1041},
+											pos:        position{line: 80, col: 50, offset: 2308},
 											val:        "//{",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 56, offset: 1047},
+											pos:  position{line: 80, col: 56, offset: 2314},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 59, offset: 1050},
+											pos:  position{line: 80, col: 59, offset: 2317},
 											name: "Labels",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 66, offset: 1057},
+											pos:  position{line: 80, col: 66, offset: 2324},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 69, offset: 1060},
+											pos:        position{line: 80, col: 69, offset: 2327},
 											val:        "}",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 73, offset: 1064},
+											pos:  position{line: 80, col: 73, offset: 2331},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 76, offset: 1067},
+											pos:  position{line: 80, col: 76, offset: 2334},
 											name: "ChoiceExpr",
 										},
 									},
@@ -249,44 +431,44 @@ var g = &grammar{
 		},
 		{
 			name: "Labels",
-			pos:  position{line: 58, col: 1, offset: 1481},
+			pos:  position{line: 95, col: 1, offset: 2748},
 			expr: &actionExpr{
-				pos: position{line: 58, col: 10, offset: 1492},
+				pos: position{line: 95, col: 10, offset: 2759},
 				run: (*parser).callonLabels1,
 				expr: &seqExpr{
-					pos: position{line: 58, col: 10, offset: 1492},
+					pos: position{line: 95, col: 10, offset: 2759},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 58, col: 10, offset: 1492},
+							pos:   position{line: 95, col: 10, offset: 2759},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 58, col: 16, offset: 1498},
+								pos:  position{line: 95, col: 16, offset: 2765},
 								name: "IdentifierName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 58, col: 31, offset: 1513},
+							pos:   position{line: 95, col: 31, offset: 2780},
 							label: "labels",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 58, col: 38, offset: 1520},
+								pos: position{line: 95, col: 38, offset: 2787},
 								expr: &seqExpr{
-									pos: position{line: 58, col: 40, offset: 1522},
+									pos: position{line: 95, col: 40, offset: 2789},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 40, offset: 1522},
+											pos:  position{line: 95, col: 40, offset: 2789},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 58, col: 43, offset: 1525},
+											pos:        position{line: 95, col: 43, offset: 2792},
 											val:        ",",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 47, offset: 1529},
+											pos:  position{line: 95, col: 47, offset: 2796},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 50, offset: 1532},
+											pos:  position{line: 95, col: 50, offset: 2799},
 											name: "IdentifierName",
 										},
 									},
@@ -299,44 +481,44 @@ var g = &grammar{
 		},
 		{
 			name: "ChoiceExpr",
-			pos:  position{line: 67, col: 1, offset: 1861},
+			pos:  position{line: 104, col: 1, offset: 3128},
 			expr: &actionExpr{
-				pos: position{line: 67, col: 14, offset: 1876},
+				pos: position{line: 104, col: 14, offset: 3143},
 				run: (*parser).callonChoiceExpr1,
 				expr: &seqExpr{
-					pos: position{line: 67, col: 14, offset: 1876},
+					pos: position{line: 104, col: 14, offset: 3143},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 67, col: 14, offset: 1876},
+							pos:   position{line: 104, col: 14, offset: 3143},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 67, col: 20, offset: 1882},
+								pos:  position{line: 104, col: 20, offset: 3149},
 								name: "ActionExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 67, col: 31, offset: 1893},
+							pos:   position{line: 104, col: 31, offset: 3160},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 67, col: 36, offset: 1898},
+								pos: position{line: 104, col: 36, offset: 3165},
 								expr: &seqExpr{
-									pos: position{line: 67, col: 38, offset: 1900},
+									pos: position{line: 104, col: 38, offset: 3167},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 38, offset: 1900},
+											pos:  position{line: 104, col: 38, offset: 3167},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 67, col: 41, offset: 1903},
+											pos:        position{line: 104, col: 41, offset: 3170},
 											val:        "/",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 45, offset: 1907},
+											pos:  position{line: 104, col: 45, offset: 3174},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 48, offset: 1910},
+											pos:  position{line: 104, col: 48, offset: 3177},
 											name: "ActionExpr",
 										},
 									},
@@ -349,35 +531,35 @@ var g = &grammar{
 		},
 		{
 			name: "ActionExpr",
-			pos:  position{line: 82, col: 1, offset: 2315},
+			pos:  position{line: 119, col: 1, offset: 3582},
 			expr: &actionExpr{
-				pos: position{line: 82, col: 14, offset: 2330},
+				pos: position{line: 119, col: 14, offset: 3597},
 				run: (*parser).callonActionExpr1,
 				expr: &seqExpr{
-					pos: position{line: 82, col: 14, offset: 2330},
+					pos: position{line: 119, col: 14, offset: 3597},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 82, col: 14, offset: 2330},
+							pos:   position{line: 119, col: 14, offset: 3597},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 82, col: 19, offset: 2335},
+								pos:  position{line: 119, col: 19, offset: 3602},
 								name: "SeqExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 82, col: 27, offset: 2343},
+							pos:   position{line: 119, col: 27, offset: 3610},
 							label: "code",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 82, col: 32, offset: 2348},
+								pos: position{line: 119, col: 32, offset: 3615},
 								expr: &seqExpr{
-									pos: position{line: 82, col: 34, offset: 2350},
+									pos: position{line: 119, col: 34, offset: 3617},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 34, offset: 2350},
+											pos:  position{line: 119, col: 34, offset: 3617},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 37, offset: 2353},
+											pos:  position{line: 119, col: 37, offset: 3620},
 											name: "CodeBlock",
 										},
 									},
@@ -390,35 +572,35 @@ var g = &grammar{
 		},
 		{
 			name: "SeqExpr",
-			pos:  position{line: 96, col: 1, offset: 2619},
+			pos:  position{line: 133, col: 1, offset: 3886},
 			expr: &actionExpr{
-				pos: position{line: 96, col: 11, offset: 2631},
+				pos: position{line: 133, col: 11, offset: 3898},
 				run: (*parser).callonSeqExpr1,
 				expr: &seqExpr{
-					pos: position{line: 96, col: 11, offset: 2631},
+					pos: position{line: 133, col: 11, offset: 3898},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 96, col: 11, offset: 2631},
+							pos:   position{line: 133, col: 11, offset: 3898},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 96, col: 17, offset: 2637},
+								pos:  position{line: 133, col: 17, offset: 3904},
 								name: "LabeledExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 96, col: 29, offset: 2649},
+							pos:   position{line: 133, col: 29, offset: 3916},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 96, col: 34, offset: 2654},
+								pos: position{line: 133, col: 34, offset: 3921},
 								expr: &seqExpr{
-									pos: position{line: 96, col: 36, offset: 2656},
+									pos: position{line: 133, col: 36, offset: 3923},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 36, offset: 2656},
+											pos:  position{line: 133, col: 36, offset: 3923},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 39, offset: 2659},
+											pos:  position{line: 133, col: 39, offset: 3926},
 											name: "LabeledExpr",
 										},
 									},
@@ -431,42 +613,42 @@ var g = &grammar{
 		},
 		{
 			name: "LabeledExpr",
-			pos:  position{line: 109, col: 1, offset: 3010},
+			pos:  position{line: 146, col: 1, offset: 4277},
 			expr: &choiceExpr{
-				pos: position{line: 109, col: 15, offset: 3026},
+				pos: position{line: 146, col: 15, offset: 4293},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 109, col: 15, offset: 3026},
+						pos: position{line: 146, col: 15, offset: 4293},
 						run: (*parser).callonLabeledExpr2,
 						expr: &seqExpr{
-							pos: position{line: 109, col: 15, offset: 3026},
+							pos: position{line: 146, col: 15, offset: 4293},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 109, col: 15, offset: 3026},
+									pos:   position{line: 146, col: 15, offset: 4293},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 109, col: 21, offset: 3032},
+										pos:  position{line: 146, col: 21, offset: 4299},
 										name: "Identifier",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 109, col: 32, offset: 3043},
+									pos:  position{line: 146, col: 32, offset: 4310},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 109, col: 35, offset: 3046},
+									pos:        position{line: 146, col: 35, offset: 4313},
 									val:        ":",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 109, col: 39, offset: 3050},
+									pos:  position{line: 146, col: 39, offset: 4317},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 109, col: 42, offset: 3053},
+									pos:   position{line: 146, col: 42, offset: 4320},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 109, col: 47, offset: 3058},
+										pos:  position{line: 146, col: 47, offset: 4325},
 										name: "PrefixedExpr",
 									},
 								},
@@ -474,11 +656,11 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 115, col: 5, offset: 3231},
+						pos:  position{line: 152, col: 5, offset: 4498},
 						name: "PrefixedExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 115, col: 20, offset: 3246},
+						pos:  position{line: 152, col: 20, offset: 4513},
 						name: "ThrowExpr",
 					},
 				},
@@ -486,33 +668,33 @@ var g = &grammar{
 		},
 		{
 			name: "PrefixedExpr",
-			pos:  position{line: 117, col: 1, offset: 3257},
+			pos:  position{line: 154, col: 1, offset: 4524},
 			expr: &choiceExpr{
-				pos: position{line: 117, col: 16, offset: 3274},
+				pos: position{line: 154, col: 16, offset: 4541},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 117, col: 16, offset: 3274},
+						pos: position{line: 154, col: 16, offset: 4541},
 						run: (*parser).callonPrefixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 117, col: 16, offset: 3274},
+							pos: position{line: 154, col: 16, offset: 4541},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 117, col: 16, offset: 3274},
+									pos:   position{line: 154, col: 16, offset: 4541},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 117, col: 19, offset: 3277},
+										pos:  position{line: 154, col: 19, offset: 4544},
 										name: "PrefixedOp",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 117, col: 30, offset: 3288},
+									pos:  position{line: 154, col: 30, offset: 4555},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 117, col: 33, offset: 3291},
+									pos:   position{line: 154, col: 33, offset: 4558},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 117, col: 38, offset: 3296},
+										pos:  position{line: 154, col: 38, offset: 4563},
 										name: "SuffixedExpr",
 									},
 								},
@@ -520,7 +702,7 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 128, col: 5, offset: 3578},
+						pos:  position{line: 165, col: 5, offset: 4845},
 						name: "SuffixedExpr",
 					},
 				},
@@ -528,20 +710,20 @@ var g = &grammar{
 		},
 		{
 			name: "PrefixedOp",
-			pos:  position{line: 130, col: 1, offset: 3592},
+			pos:  position{line: 167, col: 1, offset: 4859},
 			expr: &actionExpr{
-				pos: position{line: 130, col: 14, offset: 3607},
+				pos: position{line: 167, col: 14, offset: 4874},
 				run: (*parser).callonPrefixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 130, col: 16, offset: 3609},
+					pos: position{line: 167, col: 16, offset: 4876},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 130, col: 16, offset: 3609},
+							pos:        position{line: 167, col: 16, offset: 4876},
 							val:        "&",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 130, col: 22, offset: 3615},
+							pos:        position{line: 167, col: 22, offset: 4882},
 							val:        "!",
 							ignoreCase: false,
 						},
@@ -551,33 +733,33 @@ var g = &grammar{
 		},
 		{
 			name: "SuffixedExpr",
-			pos:  position{line: 134, col: 1, offset: 3657},
+			pos:  position{line: 171, col: 1, offset: 4924},
 			expr: &choiceExpr{
-				pos: position{line: 134, col: 16, offset: 3674},
+				pos: position{line: 171, col: 16, offset: 4941},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 134, col: 16, offset: 3674},
+						pos: position{line: 171, col: 16, offset: 4941},
 						run: (*parser).callonSuffixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 134, col: 16, offset: 3674},
+							pos: position{line: 171, col: 16, offset: 4941},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 134, col: 16, offset: 3674},
+									pos:   position{line: 171, col: 16, offset: 4941},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 134, col: 21, offset: 3679},
+										pos:  position{line: 171, col: 21, offset: 4946},
 										name: "PrimaryExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 134, col: 33, offset: 3691},
+									pos:  position{line: 171, col: 33, offset: 4958},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 134, col: 36, offset: 3694},
+									pos:   position{line: 171, col: 36, offset: 4961},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 134, col: 39, offset: 3697},
+										pos:  position{line: 171, col: 39, offset: 4964},
 										name: "SuffixedOp",
 									},
 								},
@@ -585,7 +767,7 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 153, col: 5, offset: 4227},
+						pos:  position{line: 190, col: 5, offset: 5494},
 						name: "PrimaryExpr",
 					},
 				},
@@ -593,25 +775,25 @@ var g = &grammar{
 		},
 		{
 			name: "SuffixedOp",
-			pos:  position{line: 155, col: 1, offset: 4241},
+			pos:  position{line: 192, col: 1, offset: 5508},
 			expr: &actionExpr{
-				pos: position{line: 155, col: 14, offset: 4256},
+				pos: position{line: 192, col: 14, offset: 5523},
 				run: (*parser).callonSuffixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 155, col: 16, offset: 4258},
+					pos: position{line: 192, col: 16, offset: 5525},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 155, col: 16, offset: 4258},
+							pos:        position{line: 192, col: 16, offset: 5525},
 							val:        "?",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 155, col: 22, offset: 4264},
+							pos:        position{line: 192, col: 22, offset: 5531},
 							val:        "*",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 155, col: 28, offset: 4270},
+							pos:        position{line: 192, col: 28, offset: 5537},
 							val:        "+",
 							ignoreCase: false,
 						},
@@ -621,59 +803,59 @@ var g = &grammar{
 		},
 		{
 			name: "PrimaryExpr",
-			pos:  position{line: 159, col: 1, offset: 4312},
+			pos:  position{line: 196, col: 1, offset: 5579},
 			expr: &choiceExpr{
-				pos: position{line: 159, col: 15, offset: 4328},
+				pos: position{line: 196, col: 15, offset: 5595},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 15, offset: 4328},
+						pos:  position{line: 196, col: 15, offset: 5595},
 						name: "LitMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 28, offset: 4341},
+						pos:  position{line: 196, col: 28, offset: 5608},
 						name: "CharClassMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 47, offset: 4360},
+						pos:  position{line: 196, col: 47, offset: 5627},
 						name: "AnyMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 60, offset: 4373},
+						pos:  position{line: 196, col: 60, offset: 5640},
 						name: "RuleRefExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 74, offset: 4387},
+						pos:  position{line: 196, col: 74, offset: 5654},
 						name: "SemanticPredExpr",
 					},
 					&actionExpr{
-						pos: position{line: 159, col: 93, offset: 4406},
+						pos: position{line: 196, col: 93, offset: 5673},
 						run: (*parser).callonPrimaryExpr7,
 						expr: &seqExpr{
-							pos: position{line: 159, col: 93, offset: 4406},
+							pos: position{line: 196, col: 93, offset: 5673},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 159, col: 93, offset: 4406},
+									pos:        position{line: 196, col: 93, offset: 5673},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 159, col: 97, offset: 4410},
+									pos:  position{line: 196, col: 97, offset: 5677},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 159, col: 100, offset: 4413},
+									pos:   position{line: 196, col: 100, offset: 5680},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 159, col: 105, offset: 4418},
+										pos:  position{line: 196, col: 105, offset: 5685},
 										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 159, col: 116, offset: 4429},
+									pos:  position{line: 196, col: 116, offset: 5696},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 159, col: 119, offset: 4432},
+									pos:        position{line: 196, col: 119, offset: 5699},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -685,48 +867,75 @@ var g = &grammar{
 		},
 		{
 			name: "RuleRefExpr",
-			pos:  position{line: 162, col: 1, offset: 4461},
+			pos:  position{line: 199, col: 1, offset: 5728},
 			expr: &actionExpr{
-				pos: position{line: 162, col: 15, offset: 4477},
+				pos: position{line: 199, col: 15, offset: 5744},
 				run: (*parser).callonRuleRefExpr1,
 				expr: &seqExpr{
-					pos: position{line: 162, col: 15, offset: 4477},
+					pos: position{line: 199, col: 15, offset: 5744},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 162, col: 15, offset: 4477},
+							pos:   position{line: 199, col: 15, offset: 5744},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 162, col: 20, offset: 4482},
+								pos:  position{line: 199, col: 20, offset: 5749},
 								name: "IdentifierName",
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 199, col: 35, offset: 5764},
+							label: "args",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 199, col: 40, offset: 5769},
+								expr: &ruleRefExpr{
+									pos:  position{line: 199, col: 40, offset: 5769},
+									name: "RuleArgs",
+								},
+							},
+						},
 						&notExpr{
-							pos: position{line: 162, col: 35, offset: 4497},
+							pos: position{line: 199, col: 50, offset: 5779},
 							expr: &seqExpr{
-								pos: position{line: 162, col: 38, offset: 4500},
+								pos: position{line: 199, col: 53, offset: 5782},
 								exprs: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 162, col: 38, offset: 4500},
+										pos:  position{line: 199, col: 53, offset: 5782},
 										name: "__",
 									},
 									&zeroOrOneExpr{
-										pos: position{line: 162, col: 41, offset: 4503},
+										pos: position{line: 199, col: 56, offset: 5785},
 										expr: &seqExpr{
-											pos: position{line: 162, col: 43, offset: 4505},
+											pos: position{line: 199, col: 58, offset: 5787},
 											exprs: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 162, col: 43, offset: 4505},
+													pos:  position{line: 199, col: 58, offset: 5787},
+													name: "RuleType",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 199, col: 67, offset: 5796},
+													name: "__",
+												},
+											},
+										},
+									},
+									&zeroOrOneExpr{
+										pos: position{line: 199, col: 73, offset: 5802},
+										expr: &seqExpr{
+											pos: position{line: 199, col: 75, offset: 5804},
+											exprs: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 199, col: 75, offset: 5804},
 													name: "StringLiteral",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 162, col: 57, offset: 4519},
+													pos:  position{line: 199, col: 89, offset: 5818},
 													name: "__",
 												},
 											},
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 162, col: 63, offset: 4525},
+										pos:  position{line: 199, col: 95, offset: 5824},
 										name: "RuleDefOp",
 									},
 								},
@@ -738,30 +947,30 @@ var g = &grammar{
 		},
 		{
 			name: "SemanticPredExpr",
-			pos:  position{line: 167, col: 1, offset: 4641},
+			pos:  position{line: 207, col: 1, offset: 6010},
 			expr: &actionExpr{
-				pos: position{line: 167, col: 20, offset: 4662},
+				pos: position{line: 207, col: 20, offset: 6031},
 				run: (*parser).callonSemanticPredExpr1,
 				expr: &seqExpr{
-					pos: position{line: 167, col: 20, offset: 4662},
+					pos: position{line: 207, col: 20, offset: 6031},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 167, col: 20, offset: 4662},
+							pos:   position{line: 207, col: 20, offset: 6031},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 167, col: 23, offset: 4665},
+								pos:  position{line: 207, col: 23, offset: 6034},
 								name: "SemanticPredOp",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 167, col: 38, offset: 4680},
+							pos:  position{line: 207, col: 38, offset: 6049},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 167, col: 41, offset: 4683},
+							pos:   position{line: 207, col: 41, offset: 6052},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 167, col: 46, offset: 4688},
+								pos:  position{line: 207, col: 46, offset: 6057},
 								name: "CodeBlock",
 							},
 						},
@@ -771,25 +980,25 @@ var g = &grammar{
 		},
 		{
 			name: "SemanticPredOp",
-			pos:  position{line: 187, col: 1, offset: 5135},
+			pos:  position{line: 227, col: 1, offset: 6504},
 			expr: &actionExpr{
-				pos: position{line: 187, col: 18, offset: 5154},
+				pos: position{line: 227, col: 18, offset: 6523},
 				run: (*parser).callonSemanticPredOp1,
 				expr: &choiceExpr{
-					pos: position{line: 187, col: 20, offset: 5156},
+					pos: position{line: 227, col: 20, offset: 6525},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 187, col: 20, offset: 5156},
+							pos:        position{line: 227, col: 20, offset: 6525},
 							val:        "#",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 187, col: 26, offset: 5162},
+							pos:        position{line: 227, col: 26, offset: 6531},
 							val:        "&",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 187, col: 32, offset: 5168},
+							pos:        position{line: 227, col: 32, offset: 6537},
 							val:        "!",
 							ignoreCase: false,
 						},
@@ -797,54 +1006,231 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "RuleParams",
+			pos:  position{line: 231, col: 1, offset: 6579},
+			expr: &actionExpr{
+				pos: position{line: 231, col: 14, offset: 6594},
+				run: (*parser).callonRuleParams1,
+				expr: &seqExpr{
+					pos: position{line: 231, col: 14, offset: 6594},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 231, col: 14, offset: 6594},
+							val:        "<",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 231, col: 18, offset: 6598},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 231, col: 21, offset: 6601},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 231, col: 27, offset: 6607},
+								name: "IdentifierName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 231, col: 42, offset: 6622},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 231, col: 47, offset: 6627},
+								expr: &seqExpr{
+									pos: position{line: 231, col: 49, offset: 6629},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 231, col: 49, offset: 6629},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 231, col: 52, offset: 6632},
+											val:        ",",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 231, col: 56, offset: 6636},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 231, col: 59, offset: 6639},
+											name: "IdentifierName",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 231, col: 77, offset: 6657},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 231, col: 80, offset: 6660},
+							val:        ">",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RuleArgs",
+			pos:  position{line: 239, col: 1, offset: 6872},
+			expr: &actionExpr{
+				pos: position{line: 239, col: 12, offset: 6885},
+				run: (*parser).callonRuleArgs1,
+				expr: &seqExpr{
+					pos: position{line: 239, col: 12, offset: 6885},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 239, col: 12, offset: 6885},
+							val:        "<",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 239, col: 16, offset: 6889},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 239, col: 19, offset: 6892},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 239, col: 25, offset: 6898},
+								name: "Expression",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 239, col: 36, offset: 6909},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 239, col: 41, offset: 6914},
+								expr: &seqExpr{
+									pos: position{line: 239, col: 43, offset: 6916},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 239, col: 43, offset: 6916},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 239, col: 46, offset: 6919},
+											val:        ",",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 239, col: 50, offset: 6923},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 239, col: 53, offset: 6926},
+											name: "Expression",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 239, col: 67, offset: 6940},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 239, col: 70, offset: 6943},
+							val:        ">",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "RuleDefOp",
-			pos:  position{line: 191, col: 1, offset: 5210},
+			pos:  position{line: 247, col: 1, offset: 7144},
 			expr: &choiceExpr{
-				pos: position{line: 191, col: 13, offset: 5224},
+				pos: position{line: 247, col: 13, offset: 7158},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 191, col: 13, offset: 5224},
+						pos:        position{line: 247, col: 13, offset: 7158},
 						val:        "=",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 19, offset: 5230},
+						pos:        position{line: 247, col: 19, offset: 7164},
 						val:        "<-",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 26, offset: 5237},
+						pos:        position{line: 247, col: 26, offset: 7171},
 						val:        "←",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 37, offset: 5248},
+						pos:        position{line: 247, col: 37, offset: 7182},
 						val:        "⟵",
 						ignoreCase: false,
 					},
 				},
 			},
 		},
+		{
+			name: "RuleType",
+			pos:  position{line: 249, col: 1, offset: 7192},
+			expr: &actionExpr{
+				pos: position{line: 249, col: 12, offset: 7205},
+				run: (*parser).callonRuleType1,
+				expr: &seqExpr{
+					pos: position{line: 249, col: 12, offset: 7205},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 249, col: 12, offset: 7205},
+							val:        "<",
+							ignoreCase: false,
+						},
+						&notExpr{
+							pos: position{line: 249, col: 16, offset: 7209},
+							expr: &litMatcher{
+								pos:        position{line: 249, col: 17, offset: 7210},
+								val:        "-",
+								ignoreCase: false,
+							},
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 249, col: 21, offset: 7214},
+							expr: &charClassMatcher{
+								pos:        position{line: 249, col: 21, offset: 7214},
+								val:        "[^<>\\r\\n]",
+								chars:      []rune{'<', '>', '\r', '\n'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 249, col: 32, offset: 7225},
+							val:        ">",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "SourceChar",
-			pos:  position{line: 193, col: 1, offset: 5258},
+			pos:  position{line: 257, col: 1, offset: 7392},
 			expr: &anyMatcher{
-				line: 193, col: 14, offset: 5273,
+				line: 257, col: 14, offset: 7407,
 			},
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 194, col: 1, offset: 5275},
+			pos:  position{line: 258, col: 1, offset: 7409},
 			expr: &choiceExpr{
-				pos: position{line: 194, col: 11, offset: 5287},
+				pos: position{line: 258, col: 11, offset: 7421},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 194, col: 11, offset: 5287},
+						pos:  position{line: 258, col: 11, offset: 7421},
 						name: "MultiLineComment",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 194, col: 30, offset: 5306},
+						pos:  position{line: 258, col: 30, offset: 7440},
 						name: "SingleLineComment",
 					},
 				},
@@ -852,37 +1238,37 @@ var g = &grammar{
 		},
 		{
 			name: "MultiLineComment",
-			pos:  position{line: 195, col: 1, offset: 5324},
+			pos:  position{line: 259, col: 1, offset: 7458},
 			expr: &seqExpr{
-				pos: position{line: 195, col: 20, offset: 5345},
+				pos: position{line: 259, col: 20, offset: 7479},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 195, col: 20, offset: 5345},
+						pos:        position{line: 259, col: 20, offset: 7479},
 						val:        "/*",
 						ignoreCase: false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 195, col: 25, offset: 5350},
+						pos: position{line: 259, col: 25, offset: 7484},
 						expr: &seqExpr{
-							pos: position{line: 195, col: 27, offset: 5352},
+							pos: position{line: 259, col: 27, offset: 7486},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 195, col: 27, offset: 5352},
+									pos: position{line: 259, col: 27, offset: 7486},
 									expr: &litMatcher{
-										pos:        position{line: 195, col: 28, offset: 5353},
+										pos:        position{line: 259, col: 28, offset: 7487},
 										val:        "*/",
 										ignoreCase: false,
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 195, col: 33, offset: 5358},
+									pos:  position{line: 259, col: 33, offset: 7492},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 195, col: 47, offset: 5372},
+						pos:        position{line: 259, col: 47, offset: 7506},
 						val:        "*/",
 						ignoreCase: false,
 					},
@@ -891,46 +1277,46 @@ var g = &grammar{
 		},
 		{
 			name: "MultiLineCommentNoLineTerminator",
-			pos:  position{line: 196, col: 1, offset: 5377},
+			pos:  position{line: 260, col: 1, offset: 7511},
 			expr: &seqExpr{
-				pos: position{line: 196, col: 36, offset: 5414},
+				pos: position{line: 260, col: 36, offset: 7548},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 196, col: 36, offset: 5414},
+						pos:        position{line: 260, col: 36, offset: 7548},
 						val:        "/*",
 						ignoreCase: false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 196, col: 41, offset: 5419},
+						pos: position{line: 260, col: 41, offset: 7553},
 						expr: &seqExpr{
-							pos: position{line: 196, col: 43, offset: 5421},
+							pos: position{line: 260, col: 43, offset: 7555},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 196, col: 43, offset: 5421},
+									pos: position{line: 260, col: 43, offset: 7555},
 									expr: &choiceExpr{
-										pos: position{line: 196, col: 46, offset: 5424},
+										pos: position{line: 260, col: 46, offset: 7558},
 										alternatives: []interface{}{
 											&litMatcher{
-												pos:        position{line: 196, col: 46, offset: 5424},
+												pos:        position{line: 260, col: 46, offset: 7558},
 												val:        "*/",
 												ignoreCase: false,
 											},
 											&ruleRefExpr{
-												pos:  position{line: 196, col: 53, offset: 5431},
+												pos:  position{line: 260, col: 53, offset: 7565},
 												name: "EOL",
 											},
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 196, col: 59, offset: 5437},
+									pos:  position{line: 260, col: 59, offset: 7571},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 196, col: 73, offset: 5451},
+						pos:        position{line: 260, col: 73, offset: 7585},
 						val:        "*/",
 						ignoreCase: false,
 					},
@@ -939,37 +1325,37 @@ var g = &grammar{
 		},
 		{
 			name: "SingleLineComment",
-			pos:  position{line: 197, col: 1, offset: 5456},
+			pos:  position{line: 261, col: 1, offset: 7590},
 			expr: &seqExpr{
-				pos: position{line: 197, col: 21, offset: 5478},
+				pos: position{line: 261, col: 21, offset: 7612},
 				exprs: []interface{}{
 					&notExpr{
-						pos: position{line: 197, col: 21, offset: 5478},
+						pos: position{line: 261, col: 21, offset: 7612},
 						expr: &litMatcher{
-							pos:        position{line: 197, col: 23, offset: 5480},
+							pos:        position{line: 261, col: 23, offset: 7614},
 							val:        "//{",
 							ignoreCase: false,
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 197, col: 30, offset: 5487},
+						pos:        position{line: 261, col: 30, offset: 7621},
 						val:        "//",
 						ignoreCase: false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 197, col: 35, offset: 5492},
+						pos: position{line: 261, col: 35, offset: 7626},
 						expr: &seqExpr{
-							pos: position{line: 197, col: 37, offset: 5494},
+							pos: position{line: 261, col: 37, offset: 7628},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 197, col: 37, offset: 5494},
+									pos: position{line: 261, col: 37, offset: 7628},
 									expr: &ruleRefExpr{
-										pos:  position{line: 197, col: 38, offset: 5495},
+										pos:  position{line: 261, col: 38, offset: 7629},
 										name: "EOL",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 197, col: 42, offset: 5499},
+									pos:  position{line: 261, col: 42, offset: 7633},
 									name: "SourceChar",
 								},
 							},
@@ -980,15 +1366,15 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 199, col: 1, offset: 5514},
+			pos:  position{line: 263, col: 1, offset: 7648},
 			expr: &actionExpr{
-				pos: position{line: 199, col: 14, offset: 5529},
+				pos: position{line: 263, col: 14, offset: 7663},
 				run: (*parser).callonIdentifier1,
 				expr: &labeledExpr{
-					pos:   position{line: 199, col: 14, offset: 5529},
+					pos:   position{line: 263, col: 14, offset: 7663},
 					label: "ident",
 					expr: &ruleRefExpr{
-						pos:  position{line: 199, col: 20, offset: 5535},
+						pos:  position{line: 263, col: 20, offset: 7669},
 						name: "IdentifierName",
 					},
 				},
@@ -996,21 +1382,21 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierName",
-			pos:  position{line: 207, col: 1, offset: 5754},
+			pos:  position{line: 271, col: 1, offset: 7888},
 			expr: &actionExpr{
-				pos: position{line: 207, col: 18, offset: 5773},
+				pos: position{line: 271, col: 18, offset: 7907},
 				run: (*parser).callonIdentifierName1,
 				expr: &seqExpr{
-					pos: position{line: 207, col: 18, offset: 5773},
+					pos: position{line: 271, col: 18, offset: 7907},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 207, col: 18, offset: 5773},
+							pos:  position{line: 271, col: 18, offset: 7907},
 							name: "IdentifierStart",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 207, col: 34, offset: 5789},
+							pos: position{line: 271, col: 34, offset: 7923},
 							expr: &ruleRefExpr{
-								pos:  position{line: 207, col: 34, offset: 5789},
+								pos:  position{line: 271, col: 34, offset: 7923},
 								name: "IdentifierPart",
 							},
 						},
@@ -1020,9 +1406,9 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierStart",
-			pos:  position{line: 210, col: 1, offset: 5871},
+			pos:  position{line: 274, col: 1, offset: 8005},
 			expr: &charClassMatcher{
-				pos:        position{line: 210, col: 19, offset: 5891},
+				pos:        position{line: 274, col: 19, offset: 8025},
 				val:        "[\\pL_]",
 				chars:      []rune{'_'},
 				classes:    []*unicode.RangeTable{rangeTable("L")},
@@ -1032,16 +1418,16 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierPart",
-			pos:  position{line: 211, col: 1, offset: 5898},
+			pos:  position{line: 275, col: 1, offset: 8032},
 			expr: &choiceExpr{
-				pos: position{line: 211, col: 18, offset: 5917},
+				pos: position{line: 275, col: 18, offset: 8051},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 211, col: 18, offset: 5917},
+						pos:  position{line: 275, col: 18, offset: 8051},
 						name: "IdentifierStart",
 					},
 					&charClassMatcher{
-						pos:        position{line: 211, col: 36, offset: 5935},
+						pos:        position{line: 275, col: 36, offset: 8069},
 						val:        "[\\p{Nd}]",
 						classes:    []*unicode.RangeTable{rangeTable("Nd")},
 						ignoreCase: false,
@@ -1052,28 +1438,28 @@ var g = &grammar{
 		},
 		{
 			name: "LitMatcher",
-			pos:  position{line: 213, col: 1, offset: 5945},
+			pos:  position{line: 277, col: 1, offset: 8079},
 			expr: &actionExpr{
-				pos: position{line: 213, col: 14, offset: 5960},
+				pos: position{line: 277, col: 14, offset: 8094},
 				run: (*parser).callonLitMatcher1,
 				expr: &seqExpr{
-					pos: position{line: 213, col: 14, offset: 5960},
+					pos: position{line: 277, col: 14, offset: 8094},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 213, col: 14, offset: 5960},
+							pos:   position{line: 277, col: 14, offset: 8094},
 							label: "lit",
 							expr: &ruleRefExpr{
-								pos:  position{line: 213, col: 18, offset: 5964},
+								pos:  position{line: 277, col: 18, offset: 8098},
 								name: "StringLiteral",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 213, col: 32, offset: 5978},
+							pos:   position{line: 277, col: 32, offset: 8112},
 							label: "ignore",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 213, col: 39, offset: 5985},
+								pos: position{line: 277, col: 39, offset: 8119},
 								expr: &litMatcher{
-									pos:        position{line: 213, col: 39, offset: 5985},
+									pos:        position{line: 277, col: 39, offset: 8119},
 									val:        "i",
 									ignoreCase: false,
 								},
@@ -1085,74 +1471,74 @@ var g = &grammar{
 		},
 		{
 			name: "StringLiteral",
-			pos:  position{line: 226, col: 1, offset: 6384},
+			pos:  position{line: 290, col: 1, offset: 8518},
 			expr: &choiceExpr{
-				pos: position{line: 226, col: 17, offset: 6402},
+				pos: position{line: 290, col: 17, offset: 8536},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 226, col: 17, offset: 6402},
+						pos: position{line: 290, col: 17, offset: 8536},
 						run: (*parser).callonStringLiteral2,
 						expr: &choiceExpr{
-							pos: position{line: 226, col: 19, offset: 6404},
+							pos: position{line: 290, col: 19, offset: 8538},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 226, col: 19, offset: 6404},
+									pos: position{line: 290, col: 19, offset: 8538},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 19, offset: 6404},
+											pos:        position{line: 290, col: 19, offset: 8538},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 226, col: 23, offset: 6408},
+											pos: position{line: 290, col: 23, offset: 8542},
 											expr: &ruleRefExpr{
-												pos:  position{line: 226, col: 23, offset: 6408},
+												pos:  position{line: 290, col: 23, offset: 8542},
 												name: "DoubleStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 41, offset: 6426},
+											pos:        position{line: 290, col: 41, offset: 8560},
 											val:        "\"",
 											ignoreCase: false,
 										},
 									},
 								},
 								&seqExpr{
-									pos: position{line: 226, col: 47, offset: 6432},
+									pos: position{line: 290, col: 47, offset: 8566},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 47, offset: 6432},
+											pos:        position{line: 290, col: 47, offset: 8566},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 226, col: 51, offset: 6436},
+											pos:  position{line: 290, col: 51, offset: 8570},
 											name: "SingleStringChar",
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 68, offset: 6453},
+											pos:        position{line: 290, col: 68, offset: 8587},
 											val:        "'",
 											ignoreCase: false,
 										},
 									},
 								},
 								&seqExpr{
-									pos: position{line: 226, col: 74, offset: 6459},
+									pos: position{line: 290, col: 74, offset: 8593},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 74, offset: 6459},
+											pos:        position{line: 290, col: 74, offset: 8593},
 											val:        "`",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 226, col: 78, offset: 6463},
+											pos: position{line: 290, col: 78, offset: 8597},
 											expr: &ruleRefExpr{
-												pos:  position{line: 226, col: 78, offset: 6463},
+												pos:  position{line: 290, col: 78, offset: 8597},
 												name: "RawStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 93, offset: 6478},
+											pos:        position{line: 290, col: 93, offset: 8612},
 											val:        "`",
 											ignoreCase: false,
 										},
@@ -1162,35 +1548,35 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 228, col: 5, offset: 6551},
+						pos: position{line: 292, col: 5, offset: 8685},
 						run: (*parser).callonStringLiteral18,
 						expr: &choiceExpr{
-							pos: position{line: 228, col: 7, offset: 6553},
+							pos: position{line: 292, col: 7, offset: 8687},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 228, col: 9, offset: 6555},
+									pos: position{line: 292, col: 9, offset: 8689},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 9, offset: 6555},
+											pos:        position{line: 292, col: 9, offset: 8689},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 228, col: 13, offset: 6559},
+											pos: position{line: 292, col: 13, offset: 8693},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 13, offset: 6559},
+												pos:  position{line: 292, col: 13, offset: 8693},
 												name: "DoubleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 228, col: 33, offset: 6579},
+											pos: position{line: 292, col: 33, offset: 8713},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 33, offset: 6579},
+													pos:  position{line: 292, col: 33, offset: 8713},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 39, offset: 6585},
+													pos:  position{line: 292, col: 39, offset: 8719},
 													name: "EOF",
 												},
 											},
@@ -1198,29 +1584,29 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 228, col: 51, offset: 6597},
+									pos: position{line: 292, col: 51, offset: 8731},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 51, offset: 6597},
+											pos:        position{line: 292, col: 51, offset: 8731},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&zeroOrOneExpr{
-											pos: position{line: 228, col: 55, offset: 6601},
+											pos: position{line: 292, col: 55, offset: 8735},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 55, offset: 6601},
+												pos:  position{line: 292, col: 55, offset: 8735},
 												name: "SingleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 228, col: 75, offset: 6621},
+											pos: position{line: 292, col: 75, offset: 8755},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 75, offset: 6621},
+													pos:  position{line: 292, col: 75, offset: 8755},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 81, offset: 6627},
+													pos:  position{line: 292, col: 81, offset: 8761},
 													name: "EOF",
 												},
 											},
@@ -1228,22 +1614,22 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 228, col: 91, offset: 6637},
+									pos: position{line: 292, col: 91, offset: 8771},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 91, offset: 6637},
+											pos:        position{line: 292, col: 91, offset: 8771},
 											val:        "`",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 228, col: 95, offset: 6641},
+											pos: position{line: 292, col: 95, offset: 8775},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 95, offset: 6641},
+												pos:  position{line: 292, col: 95, offset: 8775},
 												name: "RawStringChar",
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 228, col: 110, offset: 6656},
+											pos:  position{line: 292, col: 110, offset: 8790},
 											name: "EOF",
 										},
 									},
@@ -1256,51 +1642,51 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringChar",
-			pos:  position{line: 232, col: 1, offset: 6758},
+			pos:  position{line: 296, col: 1, offset: 8892},
 			expr: &choiceExpr{
-				pos: position{line: 232, col: 20, offset: 6779},
+				pos: position{line: 296, col: 20, offset: 8913},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 232, col: 20, offset: 6779},
+						pos: position{line: 296, col: 20, offset: 8913},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 232, col: 20, offset: 6779},
+								pos: position{line: 296, col: 20, offset: 8913},
 								expr: &choiceExpr{
-									pos: position{line: 232, col: 23, offset: 6782},
+									pos: position{line: 296, col: 23, offset: 8916},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 232, col: 23, offset: 6782},
+											pos:        position{line: 296, col: 23, offset: 8916},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 232, col: 29, offset: 6788},
+											pos:        position{line: 296, col: 29, offset: 8922},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 232, col: 36, offset: 6795},
+											pos:  position{line: 296, col: 36, offset: 8929},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 232, col: 42, offset: 6801},
+								pos:  position{line: 296, col: 42, offset: 8935},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 232, col: 55, offset: 6814},
+						pos: position{line: 296, col: 55, offset: 8948},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 232, col: 55, offset: 6814},
+								pos:        position{line: 296, col: 55, offset: 8948},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 232, col: 60, offset: 6819},
+								pos:  position{line: 296, col: 60, offset: 8953},
 								name: "DoubleStringEscape",
 							},
 						},
@@ -1310,51 +1696,51 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringChar",
-			pos:  position{line: 233, col: 1, offset: 6838},
+			pos:  position{line: 297, col: 1, offset: 8972},
 			expr: &choiceExpr{
-				pos: position{line: 233, col: 20, offset: 6859},
+				pos: position{line: 297, col: 20, offset: 8993},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 233, col: 20, offset: 6859},
+						pos: position{line: 297, col: 20, offset: 8993},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 233, col: 20, offset: 6859},
+								pos: position{line: 297, col: 20, offset: 8993},
 								expr: &choiceExpr{
-									pos: position{line: 233, col: 23, offset: 6862},
+									pos: position{line: 297, col: 23, offset: 8996},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 233, col: 23, offset: 6862},
+											pos:        position{line: 297, col: 23, offset: 8996},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 233, col: 29, offset: 6868},
+											pos:        position{line: 297, col: 29, offset: 9002},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 233, col: 36, offset: 6875},
+											pos:  position{line: 297, col: 36, offset: 9009},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 233, col: 42, offset: 6881},
+								pos:  position{line: 297, col: 42, offset: 9015},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 233, col: 55, offset: 6894},
+						pos: position{line: 297, col: 55, offset: 9028},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 233, col: 55, offset: 6894},
+								pos:        position{line: 297, col: 55, offset: 9028},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 233, col: 60, offset: 6899},
+								pos:  position{line: 297, col: 60, offset: 9033},
 								name: "SingleStringEscape",
 							},
 						},
@@ -1364,20 +1750,20 @@ var g = &grammar{
 		},
 		{
 			name: "RawStringChar",
-			pos:  position{line: 234, col: 1, offset: 6918},
+			pos:  position{line: 298, col: 1, offset: 9052},
 			expr: &seqExpr{
-				pos: position{line: 234, col: 17, offset: 6936},
+				pos: position{line: 298, col: 17, offset: 9070},
 				exprs: []interface{}{
 					&notExpr{
-						pos: position{line: 234, col: 17, offset: 6936},
+						pos: position{line: 298, col: 17, offset: 9070},
 						expr: &litMatcher{
-							pos:        position{line: 234, col: 18, offset: 6937},
+							pos:        position{line: 298, col: 18, offset: 9071},
 							val:        "`",
 							ignoreCase: false,
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 234, col: 22, offset: 6941},
+						pos:  position{line: 298, col: 22, offset: 9075},
 						name: "SourceChar",
 					},
 				},
@@ -1385,40 +1771,40 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringEscape",
-			pos:  position{line: 236, col: 1, offset: 6953},
+			pos:  position{line: 300, col: 1, offset: 9087},
 			expr: &choiceExpr{
-				pos: position{line: 236, col: 22, offset: 6976},
+				pos: position{line: 300, col: 22, offset: 9110},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 236, col: 24, offset: 6978},
+						pos: position{line: 300, col: 24, offset: 9112},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 236, col: 24, offset: 6978},
+								pos:        position{line: 300, col: 24, offset: 9112},
 								val:        "\"",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 236, col: 30, offset: 6984},
+								pos:  position{line: 300, col: 30, offset: 9118},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 237, col: 7, offset: 7013},
+						pos: position{line: 301, col: 7, offset: 9147},
 						run: (*parser).callonDoubleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 237, col: 9, offset: 7015},
+							pos: position{line: 301, col: 9, offset: 9149},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 9, offset: 7015},
+									pos:  position{line: 301, col: 9, offset: 9149},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 22, offset: 7028},
+									pos:  position{line: 301, col: 22, offset: 9162},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 28, offset: 7034},
+									pos:  position{line: 301, col: 28, offset: 9168},
 									name: "EOF",
 								},
 							},
@@ -1429,40 +1815,40 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringEscape",
-			pos:  position{line: 240, col: 1, offset: 7099},
+			pos:  position{line: 304, col: 1, offset: 9233},
 			expr: &choiceExpr{
-				pos: position{line: 240, col: 22, offset: 7122},
+				pos: position{line: 304, col: 22, offset: 9256},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 240, col: 24, offset: 7124},
+						pos: position{line: 304, col: 24, offset: 9258},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 240, col: 24, offset: 7124},
+								pos:        position{line: 304, col: 24, offset: 9258},
 								val:        "'",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 240, col: 30, offset: 7130},
+								pos:  position{line: 304, col: 30, offset: 9264},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 241, col: 7, offset: 7159},
+						pos: position{line: 305, col: 7, offset: 9293},
 						run: (*parser).callonSingleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 241, col: 9, offset: 7161},
+							pos: position{line: 305, col: 9, offset: 9295},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 9, offset: 7161},
+									pos:  position{line: 305, col: 9, offset: 9295},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 22, offset: 7174},
+									pos:  position{line: 305, col: 22, offset: 9308},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 28, offset: 7180},
+									pos:  position{line: 305, col: 28, offset: 9314},
 									name: "EOF",
 								},
 							},
@@ -1473,28 +1859,28 @@ var g = &grammar{
 		},
 		{
 			name: "CommonEscapeSequence",
-			pos:  position{line: 245, col: 1, offset: 7246},
+			pos:  position{line: 309, col: 1, offset: 9380},
 			expr: &choiceExpr{
-				pos: position{line: 245, col: 24, offset: 7271},
+				pos: position{line: 309, col: 24, offset: 9405},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 24, offset: 7271},
+						pos:  position{line: 309, col: 24, offset: 9405},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 43, offset: 7290},
+						pos:  position{line: 309, col: 43, offset: 9424},
 						name: "OctalEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 57, offset: 7304},
+						pos:  position{line: 309, col: 57, offset: 9438},
 						name: "HexEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 69, offset: 7316},
+						pos:  position{line: 309, col: 69, offset: 9450},
 						name: "LongUnicodeEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 89, offset: 7336},
+						pos:  position{line: 309, col: 89, offset: 9470},
 						name: "ShortUnicodeEscape",
 					},
 				},
@@ -1502,47 +1888,47 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 246, col: 1, offset: 7355},
+			pos:  position{line: 310, col: 1, offset: 9489},
 			expr: &choiceExpr{
-				pos: position{line: 246, col: 20, offset: 7376},
+				pos: position{line: 310, col: 20, offset: 9510},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 246, col: 20, offset: 7376},
+						pos:        position{line: 310, col: 20, offset: 9510},
 						val:        "a",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 26, offset: 7382},
+						pos:        position{line: 310, col: 26, offset: 9516},
 						val:        "b",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 32, offset: 7388},
+						pos:        position{line: 310, col: 32, offset: 9522},
 						val:        "n",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 38, offset: 7394},
+						pos:        position{line: 310, col: 38, offset: 9528},
 						val:        "f",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 44, offset: 7400},
+						pos:        position{line: 310, col: 44, offset: 9534},
 						val:        "r",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 50, offset: 7406},
+						pos:        position{line: 310, col: 50, offset: 9540},
 						val:        "t",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 56, offset: 7412},
+						pos:        position{line: 310, col: 56, offset: 9546},
 						val:        "v",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 62, offset: 7418},
+						pos:        position{line: 310, col: 62, offset: 9552},
 						val:        "\\",
 						ignoreCase: false,
 					},
@@ -1551,50 +1937,50 @@ var g = &grammar{
 		},
 		{
 			name: "OctalEscape",
-			pos:  position{line: 247, col: 1, offset: 7423},
+			pos:  position{line: 311, col: 1, offset: 9557},
 			expr: &choiceExpr{
-				pos: position{line: 247, col: 15, offset: 7439},
+				pos: position{line: 311, col: 15, offset: 9573},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 247, col: 15, offset: 7439},
+						pos: position{line: 311, col: 15, offset: 9573},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 15, offset: 7439},
+								pos:  position{line: 311, col: 15, offset: 9573},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 26, offset: 7450},
+								pos:  position{line: 311, col: 26, offset: 9584},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 37, offset: 7461},
+								pos:  position{line: 311, col: 37, offset: 9595},
 								name: "OctalDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 248, col: 7, offset: 7478},
+						pos: position{line: 312, col: 7, offset: 9612},
 						run: (*parser).callonOctalEscape6,
 						expr: &seqExpr{
-							pos: position{line: 248, col: 7, offset: 7478},
+							pos: position{line: 312, col: 7, offset: 9612},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 248, col: 7, offset: 7478},
+									pos:  position{line: 312, col: 7, offset: 9612},
 									name: "OctalDigit",
 								},
 								&choiceExpr{
-									pos: position{line: 248, col: 20, offset: 7491},
+									pos: position{line: 312, col: 20, offset: 9625},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 20, offset: 7491},
+											pos:  position{line: 312, col: 20, offset: 9625},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 33, offset: 7504},
+											pos:  position{line: 312, col: 33, offset: 9638},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 39, offset: 7510},
+											pos:  position{line: 312, col: 39, offset: 9644},
 											name: "EOF",
 										},
 									},
@@ -1607,52 +1993,52 @@ var g = &grammar{
 		},
 		{
 			name: "HexEscape",
-			pos:  position{line: 251, col: 1, offset: 7571},
+			pos:  position{line: 315, col: 1, offset: 9705},
 			expr: &choiceExpr{
-				pos: position{line: 251, col: 13, offset: 7585},
+				pos: position{line: 315, col: 13, offset: 9719},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 251, col: 13, offset: 7585},
+						pos: position{line: 315, col: 13, offset: 9719},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 251, col: 13, offset: 7585},
+								pos:        position{line: 315, col: 13, offset: 9719},
 								val:        "x",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 251, col: 17, offset: 7589},
+								pos:  position{line: 315, col: 17, offset: 9723},
 								name: "HexDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 251, col: 26, offset: 7598},
+								pos:  position{line: 315, col: 26, offset: 9732},
 								name: "HexDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 252, col: 7, offset: 7613},
+						pos: position{line: 316, col: 7, offset: 9747},
 						run: (*parser).callonHexEscape6,
 						expr: &seqExpr{
-							pos: position{line: 252, col: 7, offset: 7613},
+							pos: position{line: 316, col: 7, offset: 9747},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 252, col: 7, offset: 7613},
+									pos:        position{line: 316, col: 7, offset: 9747},
 									val:        "x",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 252, col: 13, offset: 7619},
+									pos: position{line: 316, col: 13, offset: 9753},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 13, offset: 7619},
+											pos:  position{line: 316, col: 13, offset: 9753},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 26, offset: 7632},
+											pos:  position{line: 316, col: 26, offset: 9766},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 32, offset: 7638},
+											pos:  position{line: 316, col: 32, offset: 9772},
 											name: "EOF",
 										},
 									},
@@ -1665,80 +2051,80 @@ var g = &grammar{
 		},
 		{
 			name: "LongUnicodeEscape",
-			pos:  position{line: 255, col: 1, offset: 7705},
+			pos:  position{line: 319, col: 1, offset: 9839},
 			expr: &choiceExpr{
-				pos: position{line: 256, col: 5, offset: 7732},
+				pos: position{line: 320, col: 5, offset: 9866},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 256, col: 5, offset: 7732},
+						pos: position{line: 320, col: 5, offset: 9866},
 						run: (*parser).callonLongUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 256, col: 5, offset: 7732},
+							pos: position{line: 320, col: 5, offset: 9866},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 256, col: 5, offset: 7732},
+									pos:        position{line: 320, col: 5, offset: 9866},
 									val:        "U",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 9, offset: 7736},
+									pos:  position{line: 320, col: 9, offset: 9870},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 18, offset: 7745},
+									pos:  position{line: 320, col: 18, offset: 9879},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 27, offset: 7754},
+									pos:  position{line: 320, col: 27, offset: 9888},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 36, offset: 7763},
+									pos:  position{line: 320, col: 36, offset: 9897},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 45, offset: 7772},
+									pos:  position{line: 320, col: 45, offset: 9906},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 54, offset: 7781},
+									pos:  position{line: 320, col: 54, offset: 9915},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 63, offset: 7790},
+									pos:  position{line: 320, col: 63, offset: 9924},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 72, offset: 7799},
+									pos:  position{line: 320, col: 72, offset: 9933},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 259, col: 7, offset: 7901},
+						pos: position{line: 323, col: 7, offset: 10035},
 						run: (*parser).callonLongUnicodeEscape13,
 						expr: &seqExpr{
-							pos: position{line: 259, col: 7, offset: 7901},
+							pos: position{line: 323, col: 7, offset: 10035},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 259, col: 7, offset: 7901},
+									pos:        position{line: 323, col: 7, offset: 10035},
 									val:        "U",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 259, col: 13, offset: 7907},
+									pos: position{line: 323, col: 13, offset: 10041},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 13, offset: 7907},
+											pos:  position{line: 323, col: 13, offset: 10041},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 26, offset: 7920},
+											pos:  position{line: 323, col: 26, offset: 10054},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 32, offset: 7926},
+											pos:  position{line: 323, col: 32, offset: 10060},
 											name: "EOF",
 										},
 									},
@@ -1751,64 +2137,64 @@ var g = &grammar{
 		},
 		{
 			name: "ShortUnicodeEscape",
-			pos:  position{line: 262, col: 1, offset: 7989},
+			pos:  position{line: 326, col: 1, offset: 10123},
 			expr: &choiceExpr{
-				pos: position{line: 263, col: 5, offset: 8017},
+				pos: position{line: 327, col: 5, offset: 10151},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 263, col: 5, offset: 8017},
+						pos: position{line: 327, col: 5, offset: 10151},
 						run: (*parser).callonShortUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 263, col: 5, offset: 8017},
+							pos: position{line: 327, col: 5, offset: 10151},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 263, col: 5, offset: 8017},
+									pos:        position{line: 327, col: 5, offset: 10151},
 									val:        "u",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 9, offset: 8021},
+									pos:  position{line: 327, col: 9, offset: 10155},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 18, offset: 8030},
+									pos:  position{line: 327, col: 18, offset: 10164},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 27, offset: 8039},
+									pos:  position{line: 327, col: 27, offset: 10173},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 36, offset: 8048},
+									pos:  position{line: 327, col: 36, offset: 10182},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 266, col: 7, offset: 8150},
+						pos: position{line: 330, col: 7, offset: 10284},
 						run: (*parser).callonShortUnicodeEscape9,
 						expr: &seqExpr{
-							pos: position{line: 266, col: 7, offset: 8150},
+							pos: position{line: 330, col: 7, offset: 10284},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 266, col: 7, offset: 8150},
+									pos:        position{line: 330, col: 7, offset: 10284},
 									val:        "u",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 266, col: 13, offset: 8156},
+									pos: position{line: 330, col: 13, offset: 10290},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 13, offset: 8156},
+											pos:  position{line: 330, col: 13, offset: 10290},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 26, offset: 8169},
+											pos:  position{line: 330, col: 26, offset: 10303},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 32, offset: 8175},
+											pos:  position{line: 330, col: 32, offset: 10309},
 											name: "EOF",
 										},
 									},
@@ -1821,9 +2207,9 @@ var g = &grammar{
 		},
 		{
 			name: "OctalDigit",
-			pos:  position{line: 270, col: 1, offset: 8239},
+			pos:  position{line: 334, col: 1, offset: 10373},
 			expr: &charClassMatcher{
-				pos:        position{line: 270, col: 14, offset: 8254},
+				pos:        position{line: 334, col: 14, offset: 10388},
 				val:        "[0-7]",
 				ranges:     []rune{'0', '7'},
 				ignoreCase: false,
@@ -1832,9 +2218,9 @@ var g = &grammar{
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 271, col: 1, offset: 8260},
+			pos:  position{line: 335, col: 1, offset: 10394},
 			expr: &charClassMatcher{
-				pos:        position{line: 271, col: 16, offset: 8277},
+				pos:        position{line: 335, col: 16, offset: 10411},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1843,9 +2229,9 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 272, col: 1, offset: 8283},
+			pos:  position{line: 336, col: 1, offset: 10417},
 			expr: &charClassMatcher{
-				pos:        position{line: 272, col: 12, offset: 8296},
+				pos:        position{line: 336, col: 12, offset: 10430},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
@@ -1854,44 +2240,44 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassMatcher",
-			pos:  position{line: 274, col: 1, offset: 8307},
+			pos:  position{line: 338, col: 1, offset: 10441},
 			expr: &choiceExpr{
-				pos: position{line: 274, col: 20, offset: 8328},
+				pos: position{line: 338, col: 20, offset: 10462},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 274, col: 20, offset: 8328},
+						pos: position{line: 338, col: 20, offset: 10462},
 						run: (*parser).callonCharClassMatcher2,
 						expr: &seqExpr{
-							pos: position{line: 274, col: 20, offset: 8328},
+							pos: position{line: 338, col: 20, offset: 10462},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 274, col: 20, offset: 8328},
+									pos:        position{line: 338, col: 20, offset: 10462},
 									val:        "[",
 									ignoreCase: false,
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 274, col: 24, offset: 8332},
+									pos: position{line: 338, col: 24, offset: 10466},
 									expr: &choiceExpr{
-										pos: position{line: 274, col: 26, offset: 8334},
+										pos: position{line: 338, col: 26, offset: 10468},
 										alternatives: []interface{}{
 											&ruleRefExpr{
-												pos:  position{line: 274, col: 26, offset: 8334},
+												pos:  position{line: 338, col: 26, offset: 10468},
 												name: "ClassCharRange",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 274, col: 43, offset: 8351},
+												pos:  position{line: 338, col: 43, offset: 10485},
 												name: "ClassChar",
 											},
 											&seqExpr{
-												pos: position{line: 274, col: 55, offset: 8363},
+												pos: position{line: 338, col: 55, offset: 10497},
 												exprs: []interface{}{
 													&litMatcher{
-														pos:        position{line: 274, col: 55, offset: 8363},
+														pos:        position{line: 338, col: 55, offset: 10497},
 														val:        "\\",
 														ignoreCase: false,
 													},
 													&ruleRefExpr{
-														pos:  position{line: 274, col: 60, offset: 8368},
+														pos:  position{line: 338, col: 60, offset: 10502},
 														name: "UnicodeClassEscape",
 													},
 												},
@@ -1900,14 +2286,14 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 274, col: 82, offset: 8390},
+									pos:        position{line: 338, col: 82, offset: 10524},
 									val:        "]",
 									ignoreCase: false,
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 274, col: 86, offset: 8394},
+									pos: position{line: 338, col: 86, offset: 10528},
 									expr: &litMatcher{
-										pos:        position{line: 274, col: 86, offset: 8394},
+										pos:        position{line: 338, col: 86, offset: 10528},
 										val:        "i",
 										ignoreCase: false,
 									},
@@ -1916,44 +2302,44 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 278, col: 5, offset: 8501},
+						pos: position{line: 342, col: 5, offset: 10635},
 						run: (*parser).callonCharClassMatcher15,
 						expr: &seqExpr{
-							pos: position{line: 278, col: 5, offset: 8501},
+							pos: position{line: 342, col: 5, offset: 10635},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 278, col: 5, offset: 8501},
+									pos:        position{line: 342, col: 5, offset: 10635},
 									val:        "[",
 									ignoreCase: false,
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 278, col: 9, offset: 8505},
+									pos: position{line: 342, col: 9, offset: 10639},
 									expr: &seqExpr{
-										pos: position{line: 278, col: 11, offset: 8507},
+										pos: position{line: 342, col: 11, offset: 10641},
 										exprs: []interface{}{
 											&notExpr{
-												pos: position{line: 278, col: 11, offset: 8507},
+												pos: position{line: 342, col: 11, offset: 10641},
 												expr: &ruleRefExpr{
-													pos:  position{line: 278, col: 14, offset: 8510},
+													pos:  position{line: 342, col: 14, offset: 10644},
 													name: "EOL",
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 278, col: 20, offset: 8516},
+												pos:  position{line: 342, col: 20, offset: 10650},
 												name: "SourceChar",
 											},
 										},
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 278, col: 36, offset: 8532},
+									pos: position{line: 342, col: 36, offset: 10666},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 278, col: 36, offset: 8532},
+											pos:  position{line: 342, col: 36, offset: 10666},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 278, col: 42, offset: 8538},
+											pos:  position{line: 342, col: 42, offset: 10672},
 											name: "EOF",
 										},
 									},
@@ -1966,21 +2352,21 @@ var g = &grammar{
 		},
 		{
 			name: "ClassCharRange",
-			pos:  position{line: 282, col: 1, offset: 8648},
+			pos:  position{line: 346, col: 1, offset: 10782},
 			expr: &seqExpr{
-				pos: position{line: 282, col: 18, offset: 8667},
+				pos: position{line: 346, col: 18, offset: 10801},
 				exprs: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 18, offset: 8667},
+						pos:  position{line: 346, col: 18, offset: 10801},
 						name: "ClassChar",
 					},
 					&litMatcher{
-						pos:        position{line: 282, col: 28, offset: 8677},
+						pos:        position{line: 346, col: 28, offset: 10811},
 						val:        "-",
 						ignoreCase: false,
 					},
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 32, offset: 8681},
+						pos:  position{line: 346, col: 32, offset: 10815},
 						name: "ClassChar",
 					},
 				},
@@ -1988,51 +2374,51 @@ var g = &grammar{
 		},
 		{
 			name: "ClassChar",
-			pos:  position{line: 283, col: 1, offset: 8691},
+			pos:  position{line: 347, col: 1, offset: 10825},
 			expr: &choiceExpr{
-				pos: position{line: 283, col: 13, offset: 8705},
+				pos: position{line: 347, col: 13, offset: 10839},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 283, col: 13, offset: 8705},
+						pos: position{line: 347, col: 13, offset: 10839},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 283, col: 13, offset: 8705},
+								pos: position{line: 347, col: 13, offset: 10839},
 								expr: &choiceExpr{
-									pos: position{line: 283, col: 16, offset: 8708},
+									pos: position{line: 347, col: 16, offset: 10842},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 283, col: 16, offset: 8708},
+											pos:        position{line: 347, col: 16, offset: 10842},
 											val:        "]",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 283, col: 22, offset: 8714},
+											pos:        position{line: 347, col: 22, offset: 10848},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 283, col: 29, offset: 8721},
+											pos:  position{line: 347, col: 29, offset: 10855},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 283, col: 35, offset: 8727},
+								pos:  position{line: 347, col: 35, offset: 10861},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 283, col: 48, offset: 8740},
+						pos: position{line: 347, col: 48, offset: 10874},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 283, col: 48, offset: 8740},
+								pos:        position{line: 347, col: 48, offset: 10874},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 283, col: 53, offset: 8745},
+								pos:  position{line: 347, col: 53, offset: 10879},
 								name: "CharClassEscape",
 							},
 						},
@@ -2042,51 +2428,51 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassEscape",
-			pos:  position{line: 284, col: 1, offset: 8761},
+			pos:  position{line: 348, col: 1, offset: 10895},
 			expr: &choiceExpr{
-				pos: position{line: 284, col: 19, offset: 8781},
+				pos: position{line: 348, col: 19, offset: 10915},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 284, col: 21, offset: 8783},
+						pos: position{line: 348, col: 21, offset: 10917},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 284, col: 21, offset: 8783},
+								pos:        position{line: 348, col: 21, offset: 10917},
 								val:        "]",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 284, col: 27, offset: 8789},
+								pos:  position{line: 348, col: 27, offset: 10923},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 285, col: 7, offset: 8818},
+						pos: position{line: 349, col: 7, offset: 10952},
 						run: (*parser).callonCharClassEscape5,
 						expr: &seqExpr{
-							pos: position{line: 285, col: 7, offset: 8818},
+							pos: position{line: 349, col: 7, offset: 10952},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 285, col: 7, offset: 8818},
+									pos: position{line: 349, col: 7, offset: 10952},
 									expr: &litMatcher{
-										pos:        position{line: 285, col: 8, offset: 8819},
+										pos:        position{line: 349, col: 8, offset: 10953},
 										val:        "p",
 										ignoreCase: false,
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 285, col: 14, offset: 8825},
+									pos: position{line: 349, col: 14, offset: 10959},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 14, offset: 8825},
+											pos:  position{line: 349, col: 14, offset: 10959},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 27, offset: 8838},
+											pos:  position{line: 349, col: 27, offset: 10972},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 33, offset: 8844},
+											pos:  position{line: 349, col: 33, offset: 10978},
 											name: "EOF",
 										},
 									},
@@ -2099,49 +2485,49 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeClassEscape",
-			pos:  position{line: 289, col: 1, offset: 8910},
+			pos:  position{line: 353, col: 1, offset: 11044},
 			expr: &seqExpr{
-				pos: position{line: 289, col: 22, offset: 8933},
+				pos: position{line: 353, col: 22, offset: 11067},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 289, col: 22, offset: 8933},
+						pos:        position{line: 353, col: 22, offset: 11067},
 						val:        "p",
 						ignoreCase: false,
 					},
 					&choiceExpr{
-						pos: position{line: 290, col: 7, offset: 8946},
+						pos: position{line: 354, col: 7, offset: 11080},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 290, col: 7, offset: 8946},
+								pos:  position{line: 354, col: 7, offset: 11080},
 								name: "SingleCharUnicodeClass",
 							},
 							&actionExpr{
-								pos: position{line: 291, col: 7, offset: 8975},
+								pos: position{line: 355, col: 7, offset: 11109},
 								run: (*parser).callonUnicodeClassEscape5,
 								expr: &seqExpr{
-									pos: position{line: 291, col: 7, offset: 8975},
+									pos: position{line: 355, col: 7, offset: 11109},
 									exprs: []interface{}{
 										&notExpr{
-											pos: position{line: 291, col: 7, offset: 8975},
+											pos: position{line: 355, col: 7, offset: 11109},
 											expr: &litMatcher{
-												pos:        position{line: 291, col: 8, offset: 8976},
+												pos:        position{line: 355, col: 8, offset: 11110},
 												val:        "{",
 												ignoreCase: false,
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 291, col: 14, offset: 8982},
+											pos: position{line: 355, col: 14, offset: 11116},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 14, offset: 8982},
+													pos:  position{line: 355, col: 14, offset: 11116},
 													name: "SourceChar",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 27, offset: 8995},
+													pos:  position{line: 355, col: 27, offset: 11129},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 33, offset: 9001},
+													pos:  position{line: 355, col: 33, offset: 11135},
 													name: "EOF",
 												},
 											},
@@ -2150,26 +2536,26 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 292, col: 7, offset: 9072},
+								pos: position{line: 356, col: 7, offset: 11206},
 								run: (*parser).callonUnicodeClassEscape13,
 								expr: &seqExpr{
-									pos: position{line: 292, col: 7, offset: 9072},
+									pos: position{line: 356, col: 7, offset: 11206},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 292, col: 7, offset: 9072},
+											pos:        position{line: 356, col: 7, offset: 11206},
 											val:        "{",
 											ignoreCase: false,
 										},
 										&labeledExpr{
-											pos:   position{line: 292, col: 11, offset: 9076},
+											pos:   position{line: 356, col: 11, offset: 11210},
 											label: "ident",
 											expr: &ruleRefExpr{
-												pos:  position{line: 292, col: 17, offset: 9082},
+												pos:  position{line: 356, col: 17, offset: 11216},
 												name: "IdentifierName",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 292, col: 32, offset: 9097},
+											pos:        position{line: 356, col: 32, offset: 11231},
 											val:        "}",
 											ignoreCase: false,
 										},
@@ -2177,34 +2563,34 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 298, col: 7, offset: 9274},
+								pos: position{line: 362, col: 7, offset: 11408},
 								run: (*parser).callonUnicodeClassEscape19,
 								expr: &seqExpr{
-									pos: position{line: 298, col: 7, offset: 9274},
+									pos: position{line: 362, col: 7, offset: 11408},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 298, col: 7, offset: 9274},
+											pos:        position{line: 362, col: 7, offset: 11408},
 											val:        "{",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 298, col: 11, offset: 9278},
+											pos:  position{line: 362, col: 11, offset: 11412},
 											name: "IdentifierName",
 										},
 										&choiceExpr{
-											pos: position{line: 298, col: 28, offset: 9295},
+											pos: position{line: 362, col: 28, offset: 11429},
 											alternatives: []interface{}{
 												&litMatcher{
-													pos:        position{line: 298, col: 28, offset: 9295},
+													pos:        position{line: 362, col: 28, offset: 11429},
 													val:        "]",
 													ignoreCase: false,
 												},
 												&ruleRefExpr{
-													pos:  position{line: 298, col: 34, offset: 9301},
+													pos:  position{line: 362, col: 34, offset: 11435},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 298, col: 40, offset: 9307},
+													pos:  position{line: 362, col: 40, offset: 11441},
 													name: "EOF",
 												},
 											},
@@ -2219,9 +2605,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharUnicodeClass",
-			pos:  position{line: 302, col: 1, offset: 9390},
+			pos:  position{line: 366, col: 1, offset: 11524},
 			expr: &charClassMatcher{
-				pos:        position{line: 302, col: 26, offset: 9417},
+				pos:        position{line: 366, col: 26, offset: 11551},
 				val:        "[LMNCPZS]",
 				chars:      []rune{'L', 'M', 'N', 'C', 'P', 'Z', 'S'},
 				ignoreCase: false,
@@ -2230,12 +2616,12 @@ var g = &grammar{
 		},
 		{
 			name: "AnyMatcher",
-			pos:  position{line: 304, col: 1, offset: 9428},
+			pos:  position{line: 368, col: 1, offset: 11562},
 			expr: &actionExpr{
-				pos: position{line: 304, col: 14, offset: 9443},
+				pos: position{line: 368, col: 14, offset: 11577},
 				run: (*parser).callonAnyMatcher1,
 				expr: &litMatcher{
-					pos:        position{line: 304, col: 14, offset: 9443},
+					pos:        position{line: 368, col: 14, offset: 11577},
 					val:        ".",
 					ignoreCase: false,
 				},
@@ -2243,36 +2629,36 @@ var g = &grammar{
 		},
 		{
 			name: "ThrowExpr",
-			pos:  position{line: 309, col: 1, offset: 9518},
+			pos:  position{line: 373, col: 1, offset: 11652},
 			expr: &choiceExpr{
-				pos: position{line: 309, col: 13, offset: 9532},
+				pos: position{line: 373, col: 13, offset: 11666},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 309, col: 13, offset: 9532},
+						pos: position{line: 373, col: 13, offset: 11666},
 						run: (*parser).callonThrowExpr2,
 						expr: &seqExpr{
-							pos: position{line: 309, col: 13, offset: 9532},
+							pos: position{line: 373, col: 13, offset: 11666},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 309, col: 13, offset: 9532},
+									pos:        position{line: 373, col: 13, offset: 11666},
 									val:        "%",
 									ignoreCase: false,
 								},
 								&litMatcher{
-									pos:        position{line: 309, col: 17, offset: 9536},
+									pos:        position{line: 373, col: 17, offset: 11670},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&labeledExpr{
-									pos:   position{line: 309, col: 21, offset: 9540},
+									pos:   position{line: 373, col: 21, offset: 11674},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 309, col: 27, offset: 9546},
+										pos:  position{line: 373, col: 27, offset: 11680},
 										name: "IdentifierName",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 309, col: 42, offset: 9561},
+									pos:        position{line: 373, col: 42, offset: 11695},
 									val:        "}",
 									ignoreCase: false,
 								},
@@ -2280,27 +2666,27 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 313, col: 5, offset: 9669},
+						pos: position{line: 377, col: 5, offset: 11803},
 						run: (*parser).callonThrowExpr9,
 						expr: &seqExpr{
-							pos: position{line: 313, col: 5, offset: 9669},
+							pos: position{line: 377, col: 5, offset: 11803},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 313, col: 5, offset: 9669},
+									pos:        position{line: 377, col: 5, offset: 11803},
 									val:        "%",
 									ignoreCase: false,
 								},
 								&litMatcher{
-									pos:        position{line: 313, col: 9, offset: 9673},
+									pos:        position{line: 377, col: 9, offset: 11807},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 313, col: 13, offset: 9677},
+									pos:  position{line: 377, col: 13, offset: 11811},
 									name: "IdentifierName",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 313, col: 28, offset: 9692},
+									pos:  position{line: 377, col: 28, offset: 11826},
 									name: "EOF",
 								},
 							},
@@ -2311,27 +2697,27 @@ var g = &grammar{
 		},
 		{
 			name: "CodeBlock",
-			pos:  position{line: 317, col: 1, offset: 9763},
+			pos:  position{line: 381, col: 1, offset: 11897},
 			expr: &choiceExpr{
-				pos: position{line: 317, col: 13, offset: 9777},
+				pos: position{line: 381, col: 13, offset: 11911},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 317, col: 13, offset: 9777},
+						pos: position{line: 381, col: 13, offset: 11911},
 						run: (*parser).callonCodeBlock2,
 						expr: &seqExpr{
-							pos: position{line: 317, col: 13, offset: 9777},
+							pos: position{line: 381, col: 13, offset: 11911},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 317, col: 13, offset: 9777},
+									pos:        position{line: 381, col: 13, offset: 11911},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 317, col: 17, offset: 9781},
+									pos:  position{line: 381, col: 17, offset: 11915},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 317, col: 22, offset: 9786},
+									pos:        position{line: 381, col: 22, offset: 11920},
 									val:        "}",
 									ignoreCase: false,
 								},
@@ -2339,22 +2725,22 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 321, col: 5, offset: 9885},
+						pos: position{line: 385, col: 5, offset: 12019},
 						run: (*parser).callonCodeBlock7,
 						expr: &seqExpr{
-							pos: position{line: 321, col: 5, offset: 9885},
+							pos: position{line: 385, col: 5, offset: 12019},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 321, col: 5, offset: 9885},
+									pos:        position{line: 385, col: 5, offset: 12019},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 9, offset: 9889},
+									pos:  position{line: 385, col: 9, offset: 12023},
 									name: "Code",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 14, offset: 9894},
+									pos:  position{line: 385, col: 14, offset: 12028},
 									name: "EOF",
 								},
 							},
@@ -2365,21 +2751,21 @@ var g = &grammar{
 		},
 		{
 			name: "Code",
-			pos:  position{line: 325, col: 1, offset: 9959},
+			pos:  position{line: 389, col: 1, offset: 12093},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 325, col: 8, offset: 9968},
+				pos: position{line: 389, col: 8, offset: 12102},
 				expr: &choiceExpr{
-					pos: position{line: 325, col: 10, offset: 9970},
+					pos: position{line: 389, col: 10, offset: 12104},
 					alternatives: []interface{}{
 						&oneOrMoreExpr{
-							pos: position{line: 325, col: 10, offset: 9970},
+							pos: position{line: 389, col: 10, offset: 12104},
 							expr: &seqExpr{
-								pos: position{line: 325, col: 12, offset: 9972},
+								pos: position{line: 389, col: 12, offset: 12106},
 								exprs: []interface{}{
 									&notExpr{
-										pos: position{line: 325, col: 12, offset: 9972},
+										pos: position{line: 389, col: 12, offset: 12106},
 										expr: &charClassMatcher{
-											pos:        position{line: 325, col: 13, offset: 9973},
+											pos:        position{line: 389, col: 13, offset: 12107},
 											val:        "[{}]",
 											chars:      []rune{'{', '}'},
 											ignoreCase: false,
@@ -2387,26 +2773,26 @@ var g = &grammar{
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 325, col: 18, offset: 9978},
+										pos:  position{line: 389, col: 18, offset: 12112},
 										name: "SourceChar",
 									},
 								},
 							},
 						},
 						&seqExpr{
-							pos: position{line: 325, col: 34, offset: 9994},
+							pos: position{line: 389, col: 34, offset: 12128},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 325, col: 34, offset: 9994},
+									pos:        position{line: 389, col: 34, offset: 12128},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 325, col: 38, offset: 9998},
+									pos:  position{line: 389, col: 38, offset: 12132},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 325, col: 43, offset: 10003},
+									pos:        position{line: 389, col: 43, offset: 12137},
 									val:        "}",
 									ignoreCase: false,
 								},
@@ -2418,22 +2804,22 @@ var g = &grammar{
 		},
 		{
 			name: "__",
-			pos:  position{line: 327, col: 1, offset: 10011},
+			pos:  position{line: 391, col: 1, offset: 12145},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 327, col: 6, offset: 10018},
+				pos: position{line: 391, col: 6, offset: 12152},
 				expr: &choiceExpr{
-					pos: position{line: 327, col: 8, offset: 10020},
+					pos: position{line: 391, col: 8, offset: 12154},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 8, offset: 10020},
+							pos:  position{line: 391, col: 8, offset: 12154},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 21, offset: 10033},
+							pos:  position{line: 391, col: 21, offset: 12167},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 27, offset: 10039},
+							pos:  position{line: 391, col: 27, offset: 12173},
 							name: "Comment",
 						},
 					},
@@ -2442,18 +2828,18 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 328, col: 1, offset: 10050},
+			pos:  position{line: 392, col: 1, offset: 12184},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 328, col: 5, offset: 10056},
+				pos: position{line: 392, col: 5, offset: 12190},
 				expr: &choiceExpr{
-					pos: position{line: 328, col: 7, offset: 10058},
+					pos: position{line: 392, col: 7, offset: 12192},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 7, offset: 10058},
+							pos:  position{line: 392, col: 7, offset: 12192},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 20, offset: 10071},
+							pos:  position{line: 392, col: 20, offset: 12205},
 							name: "MultiLineCommentNoLineTerminator",
 						},
 					},
@@ -2462,9 +2848,9 @@ var g = &grammar{
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 330, col: 1, offset: 10108},
+			pos:  position{line: 394, col: 1, offset: 12242},
 			expr: &charClassMatcher{
-				pos:        position{line: 330, col: 14, offset: 10123},
+				pos:        position{line: 394, col: 14, offset: 12257},
 				val:        "[ \\t\\r]",
 				chars:      []rune{' ', '\t', '\r'},
 				ignoreCase: false,
@@ -2473,62 +2859,62 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 331, col: 1, offset: 10131},
+			pos:  position{line: 395, col: 1, offset: 12265},
 			expr: &litMatcher{
-				pos:        position{line: 331, col: 7, offset: 10139},
+				pos:        position{line: 395, col: 7, offset: 12273},
 				val:        "\n",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "EOS",
-			pos:  position{line: 332, col: 1, offset: 10144},
+			pos:  position{line: 396, col: 1, offset: 12278},
 			expr: &choiceExpr{
-				pos: position{line: 332, col: 7, offset: 10152},
+				pos: position{line: 396, col: 7, offset: 12286},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 332, col: 7, offset: 10152},
+						pos: position{line: 396, col: 7, offset: 12286},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 7, offset: 10152},
+								pos:  position{line: 396, col: 7, offset: 12286},
 								name: "__",
 							},
 							&litMatcher{
-								pos:        position{line: 332, col: 10, offset: 10155},
+								pos:        position{line: 396, col: 10, offset: 12289},
 								val:        ";",
 								ignoreCase: false,
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 332, col: 16, offset: 10161},
+						pos: position{line: 396, col: 16, offset: 12295},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 16, offset: 10161},
+								pos:  position{line: 396, col: 16, offset: 12295},
 								name: "_",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 332, col: 18, offset: 10163},
+								pos: position{line: 396, col: 18, offset: 12297},
 								expr: &ruleRefExpr{
-									pos:  position{line: 332, col: 18, offset: 10163},
+									pos:  position{line: 396, col: 18, offset: 12297},
 									name: "SingleLineComment",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 37, offset: 10182},
+								pos:  position{line: 396, col: 37, offset: 12316},
 								name: "EOL",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 332, col: 43, offset: 10188},
+						pos: position{line: 396, col: 43, offset: 12322},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 43, offset: 10188},
+								pos:  position{line: 396, col: 43, offset: 12322},
 								name: "__",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 46, offset: 10191},
+								pos:  position{line: 396, col: 46, offset: 12325},
 								name: "EOF",
 							},
 						},
@@ -2538,18 +2924,18 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 334, col: 1, offset: 10196},
+			pos:  position{line: 398, col: 1, offset: 12330},
 			expr: &notExpr{
-				pos: position{line: 334, col: 7, offset: 10204},
+				pos: position{line: 398, col: 7, offset: 12338},
 				expr: &anyMatcher{
-					line: 334, col: 8, offset: 10205,
+					line: 398, col: 8, offset: 12339,
 				},
 			},
 		},
 	},
 }
 
-func (c *current) onGrammar1(initializer, rules interface{}) (interface{}, error) {
+func (c *current) onGrammar1(directives, initializer, elems interface{}) (interface{}, error) {
 	pos := c.astPos()
 
 	// create the grammar, assign its initializer
@@ -2559,10 +2945,21 @@ func (c *current) onGrammar1(initializer, rules interface{}) (interface{}, error
 		g.Init = initSlice[0].(*ast.CodeBlock)
 	}
 
-	rulesSlice := toIfaceSlice(rules)
-	g.Rules = make([]*ast.Rule, len(rulesSlice))
-	for i, duo := range rulesSlice {
-		g.Rules[i] = duo.([]interface{})[0].(*ast.Rule)
+	// directives may appear before and after the initializer, and
+	// between the rules
+	for _, duo := range toIfaceSlice(directives) {
+		g.Directives = append(g.Directives, duo.([]interface{})[0].(*ast.Directive))
+	}
+	for _, duo := range toIfaceSlice(elems) {
+		switch elem := duo.([]interface{})[0].(type) {
+		case *ast.Directive:
+			g.Directives = append(g.Directives, elem)
+		case *ast.Rule:
+			g.Rules = append(g.Rules, elem)
+		}
+	}
+	if len(g.Rules) == 0 {
+		return g, errors.New("grammar has no rule")
 	}
 
 	return g, nil
@@ -2571,7 +2968,7 @@ func (c *current) onGrammar1(initializer, rules interface{}) (interface{}, error
 func (p *parser) callonGrammar1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onGrammar1(stack["initializer"], stack["rules"])
+	return p.cur.onGrammar1(stack["directives"], stack["initializer"], stack["elems"])
 }
 
 func (c *current) onInitializer1(code interface{}) (interface{}, error) {
@@ -2584,10 +2981,56 @@ func (p *parser) callonInitializer1() (interface{}, error) {
 	return p.cur.onInitializer1(stack["code"])
 }
 
-func (c *current) onRule1(name, display, expr interface{}) (interface{}, error) {
+func (c *current) onDirective1(name, args interface{}) (interface{}, error) {
+	d := ast.NewDirective(c.astPos(), name.(*ast.Identifier))
+	for _, duo := range toIfaceSlice(args) {
+		d.Args = append(d.Args, duo.([]interface{})[1].(string))
+	}
+	return d, nil
+}
+
+func (p *parser) callonDirective1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDirective1(stack["name"], stack["args"])
+}
+
+func (c *current) onDirectiveArg2(lit interface{}) (interface{}, error) {
+	s, err := strconv.Unquote(lit.(*ast.StringLit).Val)
+	if err != nil {
+		// invalid string literals are reported by the escape rules
+		s = ""
+	}
+	return s, nil
+}
+
+func (p *parser) callonDirectiveArg2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDirectiveArg2(stack["lit"])
+}
+
+func (c *current) onDirectiveArg5() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonDirectiveArg5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDirectiveArg5()
+}
+
+func (c *current) onRule1(name, params, typ, display, expr interface{}) (interface{}, error) {
 	pos := c.astPos()
 
 	rule := ast.NewRule(pos, name.(*ast.Identifier))
+	if params != nil {
+		rule.Params = params.([]*ast.Identifier)
+	}
+	typeSlice := toIfaceSlice(typ)
+	if len(typeSlice) > 0 {
+		rule.Type = typeSlice[0].(string)
+	}
 	displaySlice := toIfaceSlice(display)
 	if len(displaySlice) > 0 {
 		rule.DisplayName = displaySlice[0].(*ast.StringLit)
@@ -2600,7 +3043,7 @@ func (c *current) onRule1(name, display, expr interface{}) (interface{}, error)
 func (p *parser) callonRule1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onRule1(stack["name"], stack["display"], stack["expr"])
+	return p.cur.onRule1(stack["name"], stack["params"], stack["typ"], stack["display"], stack["expr"])
 }
 
 func (c *current) onRecoveryExpr1(expr, recoverExprs interface{}) (interface{}, error) {
@@ -2789,16 +3232,19 @@ func (p *parser) callonPrimaryExpr7() (interface{}, error) {
 	return p.cur.onPrimaryExpr7(stack["expr"])
 }
 
-func (c *current) onRuleRefExpr1(name interface{}) (interface{}, error) {
+func (c *current) onRuleRefExpr1(name, args interface{}) (interface{}, error) {
 	ref := ast.NewRuleRefExpr(c.astPos())
 	ref.Name = name.(*ast.Identifier)
+	if args != nil {
+		ref.Args = args.([]ast.Expression)
+	}
 	return ref, nil
 }
 
 func (p *parser) callonRuleRefExpr1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onRuleRefExpr1(stack["name"])
+	return p.cur.onRuleRefExpr1(stack["name"], stack["args"])
 }
 
 func (c *current) onSemanticPredExpr1(op, code interface{}) (interface{}, error) {
@@ -2838,6 +3284,48 @@ func (p *parser) callonSemanticPredOp1() (interface{}, error) {
 	return p.cur.onSemanticPredOp1()
 }
 
+func (c *current) onRuleParams1(first, rest interface{}) (interface{}, error) {
+	params := []*ast.Identifier{first.(*ast.Identifier)}
+	for _, sl := range toIfaceSlice(rest) {
+		params = append(params, sl.([]interface{})[3].(*ast.Identifier))
+	}
+	return params, nil
+}
+
+func (p *parser) callonRuleParams1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleParams1(stack["first"], stack["rest"])
+}
+
+func (c *current) onRuleArgs1(first, rest interface{}) (interface{}, error) {
+	args := []ast.Expression{first.(ast.Expression)}
+	for _, sl := range toIfaceSlice(rest) {
+		args = append(args, sl.([]interface{})[3].(ast.Expression))
+	}
+	return args, nil
+}
+
+func (p *parser) callonRuleArgs1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleArgs1(stack["first"], stack["rest"])
+}
+
+func (c *current) onRuleType1() (interface{}, error) {
+	typ := strings.TrimSpace(string(c.text[1 : len(c.text)-1]))
+	if typ == "" {
+		return "", errors.New("empty rule type")
+	}
+	return typ, nil
+}
+
+func (p *parser) callonRuleType1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleType1()
+}
+
 func (c *current) onIdentifier1(ident interface{}) (interface{}, error) {
 	astIdent := ast.NewIdentifier(c.astPos(), string(c.text))
 	if reservedWords[astIdent.Val] {
@@ -4291,6 +4779,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
@@ -4301,6 +4790,14 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 			}
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -4391,12 +4888,21 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -0,0 +1,22 @@
+//go:generate go run ../bootstrap/cmd/runtime_generator/main.go -- generated_runtime.go
+
+/*
+Package runtime is the runtime of the parsers generated by pigeon with the
+-shared-runtime flag. It provides the parser, its options and errors, and
+the matching of the expressions of the grammar, so that the generated
+parsers only contain their grammar and code blocks.
+
+The source code of this package is generated from the static code that
+pigeon writes in the other parsers, so that both behave the same. The
+types of the grammar and their fields are exported for the declaration of
+the grammar by the generated parsers, they are not meant to be used
+directly. The errors returned by the parsers are an ErrList of
+*ParserError values.
+*/
+package runtime
+
+// PackageIsVersion1 is referenced by the parsers generated for this version
+// of the runtime package, so that they fail to compile with an incompatible
+// version. A constant for the next version is added when the generated
+// parsers depend on a change of the package.
+const PackageIsVersion1 = true
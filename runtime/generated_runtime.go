@@ -0,0 +1,1409 @@
+// Code generated by pigeon; DO NOT EDIT.
+
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// errNoRule is returned when the grammar to parse has no rule.
+	errNoRule = errors.New("grammar has no rule")
+
+	// errInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	errInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// errInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	errInvalidEncoding = errors.New("invalid encoding")
+
+	// errMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	errMaxExprCnt = errors.New("max number of expresssions parsed")
+)
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option func(*Parser) Option
+
+// MaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func MaxExpressions(maxExprCnt uint64) Option {
+	return func(p *Parser) Option {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return MaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// Entrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func Entrypoint(ruleName string) Option {
+	return func(p *Parser) Option {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		return Entrypoint(oldEntrypoint)
+	}
+}
+
+// Statistics adds a user provided Stats struct to the parser to allow
+// the user to process the results after the parsing has finished.
+// Also the key for the "no match" counter is set.
+//
+// Example usage:
+//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
+func Statistics(stats *Stats, choiceNoMatch string) Option {
+	return func(p *Parser) Option {
+		oldStats := p.Stats
+		p.Stats = stats
+		oldChoiceNoMatch := p.choiceNoMatch
+		p.choiceNoMatch = choiceNoMatch
+		if p.Stats.ChoiceAltCnt == nil {
+			p.Stats.ChoiceAltCnt = make(map[string]map[string]int)
+		}
+		return Statistics(oldStats, oldChoiceNoMatch)
+	}
+}
+
+// Debug creates an Option to set the debug flag to b. When set to true,
+// debugging information is printed to stdout while parsing.
+//
+// The default is false.
+func Debug(b bool) Option {
+	return func(p *Parser) Option {
+		old := p.debug
+		p.debug = b
+		return Debug(old)
+	}
+}
+
+// Memoize creates an Option to set the memoize flag to b. When set to true,
+// the parser will cache all results so each expression is evaluated only
+// once. This guarantees linear parsing time even for pathological cases,
+// at the expense of more memory and slower times for typical cases.
+//
+// The default is false.
+func Memoize(b bool) Option {
+	return func(p *Parser) Option {
+		old := p.memoize
+		p.memoize = b
+		return Memoize(old)
+	}
+}
+
+// AllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func AllowInvalidUTF8(b bool) Option {
+	return func(p *Parser) Option {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return AllowInvalidUTF8(old)
+	}
+}
+
+// Recover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func Recover(b bool) Option {
+	return func(p *Parser) Option {
+		old := p.recover
+		p.recover = b
+		return Recover(old)
+	}
+}
+
+// GlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func GlobalStore(key string, value interface{}) Option {
+	return func(p *Parser) Option {
+		old := p.cur.GlobalStore[key]
+		p.cur.GlobalStore[key] = value
+		return GlobalStore(key, old)
+	}
+}
+
+// InitState creates an Option to set a key to a certain value in
+// the global "state" store.
+func InitState(key string, value interface{}) Option {
+	return func(p *Parser) Option {
+		old := p.cur.State[key]
+		p.cur.State[key] = value
+		return InitState(key, old)
+	}
+}
+
+// ParseFile parses the file identified by filename with the grammar g.
+func ParseFile(g *Grammar, filename string, opts ...Option) (i interface{}, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return ParseReader(g, filename, f, opts...)
+}
+
+// ParseReader parses the data from r with the grammar g using filename as
+// information in the error messages.
+func ParseReader(g *Grammar, filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(g, filename, b, opts...)
+}
+
+// Parse parses the data from b with the grammar g using filename as
+// information in the error messages.
+func Parse(g *Grammar, filename string, b []byte, opts ...Option) (interface{}, error) {
+	return newParser(filename, b, opts...).parse(g)
+}
+
+// Position records a position in the text.
+type Position struct {
+	Line, Col, Offset int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.Line, p.Col, p.Offset)
+}
+
+// savepoint stores all state required to go back to this point in the
+// parser.
+type savepoint struct {
+	Position
+	rn rune
+	w  int
+}
+
+type Current struct {
+	Pos  Position // start position of the match
+	Text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	State StoreDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	GlobalStore StoreDict
+}
+
+type StoreDict map[string]interface{}
+
+// the AST types...
+
+type Grammar struct {
+	Pos   Position
+	Rules []*Rule
+}
+
+type Rule struct {
+	Pos         Position
+	Name        string
+	DisplayName string
+	Leader      bool
+	Expr        interface{}
+}
+
+type ChoiceExpr struct {
+	Pos          Position
+	Alternatives []interface{}
+}
+
+type ActionExpr struct {
+	Pos  Position
+	Expr interface{}
+	Run  func(*Parser) (interface{}, error)
+}
+
+type RecoveryExpr struct {
+	Pos          Position
+	Expr         interface{}
+	RecoverExpr  interface{}
+	FailureLabel []string
+}
+
+type SeqExpr struct {
+	Pos   Position
+	Exprs []interface{}
+}
+
+type ThrowExpr struct {
+	Pos   Position
+	Label string
+}
+
+type LabeledExpr struct {
+	Pos   Position
+	Label string
+	Expr  interface{}
+}
+
+type Expr struct {
+	Pos  Position
+	Expr interface{}
+}
+
+type AndExpr Expr
+type NotExpr Expr
+type ZeroOrOneExpr Expr
+type ZeroOrMoreExpr Expr
+type OneOrMoreExpr Expr
+
+type RuleRefExpr struct {
+	Pos  Position
+	Name string
+}
+
+type StateCodeExpr struct {
+	Pos Position
+	Run func(*Parser) error
+}
+
+type AndCodeExpr struct {
+	Pos Position
+	Run func(*Parser) (bool, error)
+}
+
+type NotCodeExpr struct {
+	Pos Position
+	Run func(*Parser) (bool, error)
+}
+
+type LitMatcher struct {
+	Pos        Position
+	Val        string
+	IgnoreCase bool
+}
+
+type CharClassMatcher struct {
+	Pos             Position
+	Val             string
+	BasicLatinChars [128]bool
+	Chars           []rune
+	Ranges          []rune
+	Classes         []*unicode.RangeTable
+	IgnoreCase      bool
+	Inverted        bool
+}
+
+type AnyMatcher Position
+
+// ErrList cumulates the errors found by the parser.
+type ErrList []error
+
+func (e *ErrList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e ErrList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *ErrList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+func (e ErrList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// ParserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type ParserError struct {
+	Inner    error
+	Pos      Position
+	Prefix   string
+	Expected []string
+}
+
+// Error returns the error message.
+func (p *ParserError) Error() string {
+	return p.Prefix + ": " + p.Inner.Error()
+}
+
+// newParser creates a parser with the specified input source and options.
+func newParser(filename string, b []byte, opts ...Option) *Parser {
+	stats := Stats{
+		ChoiceAltCnt: make(map[string]map[string]int),
+	}
+
+	p := &Parser{
+		filename: filename,
+		errs:     new(ErrList),
+		data:     b,
+		pt:       savepoint{Position: Position{Line: 1}},
+		recover:  true,
+		cur: Current{
+			State:       make(StoreDict),
+			GlobalStore: make(StoreDict),
+		},
+		maxFailPos:      Position{Col: 1, Line: 1},
+		maxFailExpected: make([]string, 0, 20),
+		Stats:           &stats,
+		emptyState:      make(StoreDict),
+	}
+	p.setOptions(opts)
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+
+	return p
+}
+
+// setOptions applies the options to the parser.
+func (p *Parser) setOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+type resultTuple struct {
+	v   interface{}
+	b   bool
+	end savepoint
+}
+
+const choiceNoMatch = -1
+
+// Stats stores some statistics, gathered during parsing
+type Stats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+type Parser struct {
+	filename string
+	pt       savepoint
+	cur      Current
+
+	data []byte
+	errs *ErrList
+
+	depth   int
+	recover bool
+	debug   bool
+
+	memoize bool
+	// memoization table for the packrat algorithm:
+	// map[offset in source] map[expression or rule] {value, match}
+	memo map[int]map[interface{}]resultTuple
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*Rule
+	// variables stack, map of label to value
+	vstack []map[string]interface{}
+	// rule stack, allows identification of the current rule in errors
+	rstack []*Rule
+
+	// parse fail
+	maxFailPos            Position
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+
+	*Stats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]interface{}
+
+	// emptyState contains an empty storeDict, which is used to optimize cloneState if global "state" store is not used.
+	emptyState StoreDict
+}
+
+// push a variable set on the vstack.
+func (p *Parser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]interface{})
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *Parser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// Current returns the context of the code block run by the parser.
+func (p *Parser) Current() *Current {
+	return &p.cur
+}
+
+// Labels returns the values of the labels in scope of the code block run by
+// the parser, by label name.
+func (p *Parser) Labels() map[string]interface{} {
+	return p.vstack[len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *Parser) pushRecovery(labels []string, expr interface{}) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]interface{}, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *Parser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *Parser) print(prefix, s string) string {
+	if !p.debug {
+		return s
+	}
+
+	fmt.Printf("%s %d:%d:%d: %s [%#U]\n",
+		prefix, p.pt.Line, p.pt.Col, p.pt.Offset, s, p.pt.rn)
+	return s
+}
+
+func (p *Parser) in(s string) string {
+	p.depth++
+	return p.print(strings.Repeat(" ", p.depth)+">", s)
+}
+
+func (p *Parser) out(s string) string {
+	p.depth--
+	return p.print(strings.Repeat(" ", p.depth)+"<", s)
+}
+
+func (p *Parser) addErr(err error) {
+	p.addErrAt(err, p.pt.Position, []string{})
+}
+
+func (p *Parser) addErrAt(err error, pos Position, expected []string) {
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.Line, pos.Col, pos.Offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.DisplayName != "" {
+			buf.WriteString("rule " + rule.DisplayName)
+		} else {
+			buf.WriteString("rule " + rule.Name)
+		}
+	}
+	pe := &ParserError{Inner: err, Pos: pos, Prefix: buf.String(), Expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *Parser) failAt(fail bool, pos Position, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.Offset < p.maxFailPos.Offset {
+			return
+		}
+
+		if pos.Offset > p.maxFailPos.Offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+	}
+}
+
+// read advances the parser to the next rune.
+func (p *Parser) read() {
+	p.pt.Offset += p.pt.w
+	rn, n := utf8.DecodeRune(p.data[p.pt.Offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.Col++
+	if rn == '\n' {
+		p.pt.Line++
+		p.pt.Col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(errInvalidEncoding)
+		}
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *Parser) restore(pt savepoint) {
+	if p.debug {
+		defer p.out(p.in("restore"))
+	}
+	if pt.Offset == p.pt.Offset {
+		return
+	}
+	p.pt = pt
+}
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+//
+// This is used in conjunction with the global state feature to create proper
+// copies of the state to allow the parser to properly restore the state in
+// the case of backtracking.
+type Cloner interface {
+	Clone() interface{}
+}
+
+// clone and return parser current state.
+func (p *Parser) cloneState() StoreDict {
+	if p.debug {
+		defer p.out(p.in("cloneState"))
+	}
+
+	if len(p.cur.State) == 0 {
+		if len(p.emptyState) > 0 {
+			p.emptyState = make(StoreDict)
+		}
+		return p.emptyState
+	}
+
+	state := make(StoreDict, len(p.cur.State))
+	for k, v := range p.cur.State {
+		if c, ok := v.(Cloner); ok {
+			state[k] = c.Clone()
+		} else {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+// restore parser current state to the state storeDict.
+// every restoreState should applied only one time for every cloned state
+func (p *Parser) restoreState(state StoreDict) {
+	if p.debug {
+		defer p.out(p.in("restoreState"))
+	}
+	p.cur.State = state
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *Parser) sliceFrom(start savepoint) []byte {
+	return p.data[start.Position.Offset:p.pt.Position.Offset]
+}
+
+func (p *Parser) getMemoized(node interface{}) (resultTuple, bool) {
+	if len(p.memo) == 0 {
+		return resultTuple{}, false
+	}
+	m := p.memo[p.pt.Offset]
+	if len(m) == 0 {
+		return resultTuple{}, false
+	}
+	res, ok := m[node]
+	return res, ok
+}
+
+func (p *Parser) setMemoized(pt savepoint, node interface{}, tuple resultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[interface{}]resultTuple)
+	}
+	m := p.memo[pt.Offset]
+	if m == nil {
+		m = make(map[interface{}]resultTuple)
+		p.memo[pt.Offset] = m
+	}
+	m[node] = tuple
+}
+
+func (p *Parser) buildRulesTable(g *Grammar) {
+	p.rules = make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		p.rules[r.Name] = r
+	}
+}
+
+func (p *Parser) parse(g *Grammar) (val interface{}, err error) {
+	if len(g.Rules) == 0 {
+		p.addErr(errNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+	// start rule is rule [0] unless an alternate entrypoint is specified
+	if p.entrypoint == "" {
+		p.entrypoint = g.Rules[0].Name
+	}
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				if p.debug {
+					defer p.out(p.in("panic handler"))
+				}
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(errInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	p.read() // advance to first rune
+	val, ok = p.parseRule(startRule)
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+			for _, v := range p.maxFailExpected {
+				maxFailExpectedMap[v] = struct{}{}
+			}
+			expected := make([]string, 0, len(maxFailExpectedMap))
+			eof := false
+			if _, ok := maxFailExpectedMap["!."]; ok {
+				delete(maxFailExpectedMap, "!.")
+				eof = true
+			}
+			for k := range maxFailExpectedMap {
+				expected = append(expected, k)
+			}
+			sort.Strings(expected)
+			if eof {
+				expected = append(expected, "EOF")
+			}
+			p.addErrAt(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.maxFailPos, expected)
+		}
+
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+func listJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return fmt.Sprintf("%s %s %s", strings.Join(list[:len(list)-1], sep), lastSep, list[len(list)-1])
+	}
+}
+
+func (p *Parser) parseRule(rule *Rule) (interface{}, bool) {
+	if rule.Leader {
+		return p.parseRuleRecursiveLeader(rule)
+	}
+	if p.debug {
+		defer p.out(p.in("parseRule " + rule.Name))
+	}
+
+	if p.memoize {
+		res, ok := p.getMemoized(rule)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+	}
+
+	start := p.pt
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	val, ok := p.parseExpr(rule.Expr)
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+
+	if p.memoize {
+		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+// parseRuleRecursiveLeader parses the leader of a group of left-recursive
+// rules by growing a seed. The result of the rule at the current position
+// is first memoized as a failure, then the rule is parsed repeatedly, each
+// time with the previous result memoized for its left-recursive references,
+// until it fails or no longer consumes more input than the previous result.
+func (p *Parser) parseRuleRecursiveLeader(rule *Rule) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRecursiveLeader " + rule.Name))
+	}
+
+	res, ok := p.getMemoized(rule)
+	if ok {
+		p.restore(res.end)
+		return res.v, res.b
+	}
+
+	start := p.pt
+	last := resultTuple{nil, false, start}
+	errCnt := len(*p.errs)
+	var lastErrs []error
+	state := p.cloneState()
+	lastState := p.cloneState()
+	for {
+		// every attempt starts at the same position and with the same
+		// state, only the memoized seed changes.
+		p.setMemoized(start, rule, last)
+		if p.memoize {
+			p.forgetMemoized(start)
+		}
+		p.restore(start)
+		p.restoreState(state)
+		state = p.cloneState()
+		*p.errs = (*p.errs)[:errCnt]
+
+		p.rstack = append(p.rstack, rule)
+		p.pushV()
+		val, ok := p.parseExpr(rule.Expr)
+		p.popV()
+		p.rstack = p.rstack[:len(p.rstack)-1]
+
+		if !ok || (last.b && p.pt.Offset <= last.end.Offset) {
+			break
+		}
+		last = resultTuple{val, ok, p.pt}
+		lastErrs = append(lastErrs[:0], (*p.errs)[errCnt:]...)
+		lastState = p.cloneState()
+	}
+
+	p.restore(last.end)
+	p.restoreState(lastState)
+	*p.errs = append((*p.errs)[:errCnt], lastErrs...)
+	if p.memoize {
+		p.forgetMemoized(start)
+	}
+	if last.b && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	p.setMemoized(start, rule, last)
+	return last.v, last.b
+}
+
+// forgetMemoized removes the results memoized at the position of pt, as
+// they may depend on the seed of a left-recursive rule that is being grown.
+// The seeds memoized for the leaders are kept.
+func (p *Parser) forgetMemoized(pt savepoint) {
+	m := p.memo[pt.Offset]
+	for node := range m {
+		if r, ok := node.(*Rule); ok && r.Leader {
+			continue
+		}
+		delete(m, node)
+	}
+}
+
+func (p *Parser) parseExpr(expr interface{}) (interface{}, bool) {
+	var pt savepoint
+
+	if p.memoize {
+		res, ok := p.getMemoized(expr)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+		pt = p.pt
+	}
+
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(errMaxExprCnt)
+	}
+
+	var val interface{}
+	var ok bool
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *AndCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *AndExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *AnyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *CharClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *ChoiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *LabeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *LitMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *NotCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *NotExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *OneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *RecoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *RuleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *SeqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *StateCodeExpr:
+		val, ok = p.parseStateCodeExpr(expr)
+	case *ThrowExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *ZeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *ZeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	if p.memoize {
+		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *Parser) parseActionExpr(act *ActionExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseActionExpr"))
+	}
+
+	start := p.pt
+	val, ok := p.parseExpr(act.Expr)
+	if ok {
+		p.cur.Pos = start.Position
+		p.cur.Text = p.sliceFrom(start)
+		state := p.cloneState()
+		actVal, err := act.Run(p)
+		if err != nil {
+			p.addErrAt(err, start.Position, []string{})
+		}
+		p.restoreState(state)
+
+		val = actVal
+	}
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	return val, ok
+}
+
+func (p *Parser) parseAndCodeExpr(and *AndCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := and.Run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, ok
+}
+
+func (p *Parser) parseAndExpr(and *AndExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	_, ok := p.parseExpr(and.Expr)
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, ok
+}
+
+func (p *Parser) parseAnyMatcher(any *AnyMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAnyMatcher"))
+	}
+
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.Position, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.Position, ".")
+	return p.sliceFrom(start), true
+}
+
+func (p *Parser) parseCharClassMatcher(chr *CharClassMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseCharClassMatcher"))
+	}
+
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.Position, chr.Val)
+		return nil, false
+	}
+
+	if chr.IgnoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.Chars {
+		if rn == cur {
+			if chr.Inverted {
+				p.failAt(false, start.Position, chr.Val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.Position, chr.Val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.Ranges); i += 2 {
+		if cur >= chr.Ranges[i] && cur <= chr.Ranges[i+1] {
+			if chr.Inverted {
+				p.failAt(false, start.Position, chr.Val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.Position, chr.Val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.Classes {
+		if unicode.Is(cl, cur) {
+			if chr.Inverted {
+				p.failAt(false, start.Position, chr.Val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.Position, chr.Val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.Inverted {
+		p.read()
+		p.failAt(true, start.Position, chr.Val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.Position, chr.Val)
+	return nil, false
+}
+
+func (p *Parser) incChoiceAltCnt(ch *ChoiceExpr, altI int) {
+	choiceIdent := fmt.Sprintf("%s %d:%d", p.rstack[len(p.rstack)-1].Name, ch.Pos.Line, ch.Pos.Col)
+	m := p.ChoiceAltCnt[choiceIdent]
+	if m == nil {
+		m = make(map[string]int)
+		p.ChoiceAltCnt[choiceIdent] = m
+	}
+	// We increment altI by 1, so the keys do not start at 0
+	alt := strconv.Itoa(altI + 1)
+	if altI == choiceNoMatch {
+		alt = p.choiceNoMatch
+	}
+	m[alt]++
+}
+
+func (p *Parser) parseChoiceExpr(ch *ChoiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseChoiceExpr"))
+	}
+
+	for altI, alt := range ch.Alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		state := p.cloneState()
+
+		p.pushV()
+		val, ok := p.parseExpr(alt)
+		p.popV()
+		if ok {
+			p.incChoiceAltCnt(ch, altI)
+			return val, ok
+		}
+		p.restoreState(state)
+	}
+	p.incChoiceAltCnt(ch, choiceNoMatch)
+	return nil, false
+}
+
+func (p *Parser) parseLabeledExpr(lab *LabeledExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLabeledExpr"))
+	}
+
+	p.pushV()
+	val, ok := p.parseExpr(lab.Expr)
+	p.popV()
+	if ok && lab.Label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.Label] = val
+	}
+	return val, ok
+}
+
+func (p *Parser) parseLitMatcher(lit *LitMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLitMatcher"))
+	}
+
+	ignoreCase := ""
+	if lit.IgnoreCase {
+		ignoreCase = "i"
+	}
+	val := fmt.Sprintf("%q%s", lit.Val, ignoreCase)
+	start := p.pt
+	for _, want := range lit.Val {
+		cur := p.pt.rn
+		if lit.IgnoreCase {
+			cur = unicode.ToLower(cur)
+		}
+		if cur != want {
+			p.failAt(false, start.Position, val)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.Position, val)
+	return p.sliceFrom(start), true
+}
+
+func (p *Parser) parseNotCodeExpr(not *NotCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := not.Run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, !ok
+}
+
+func (p *Parser) parseNotExpr(not *NotExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExpr(not.Expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *Parser) parseOneOrMoreExpr(expr *OneOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseOneOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.Offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.Expr)
+		p.popV()
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		if p.pt.Offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *Parser) parseRecoveryExpr(recover *RecoveryExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.FailureLabel, ",") + ")"))
+	}
+
+	p.pushRecovery(recover.FailureLabel, recover.RecoverExpr)
+	val, ok := p.parseExpr(recover.Expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *Parser) parseRuleRefExpr(ref *RuleRefExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRefExpr " + ref.Name))
+	}
+
+	if ref.Name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.Pos))
+	}
+
+	rule := p.rules[ref.Name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.Name))
+		return nil, false
+	}
+	return p.parseRule(rule)
+}
+
+func (p *Parser) parseSeqExpr(seq *SeqExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseSeqExpr"))
+	}
+
+	vals := make([]interface{}, 0, len(seq.Exprs))
+
+	pt := p.pt
+	state := p.cloneState()
+	for _, expr := range seq.Exprs {
+		val, ok := p.parseExpr(expr)
+		if !ok {
+			p.restoreState(state)
+			p.restore(pt)
+			return nil, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+func (p *Parser) parseStateCodeExpr(state *StateCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseStateCodeExpr"))
+	}
+
+	err := state.Run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	return nil, true
+}
+
+func (p *Parser) parseThrowExpr(expr *ThrowExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseThrowExpr"))
+	}
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.Label]; ok {
+			if val, ok := p.parseExpr(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *Parser) parseZeroOrMoreExpr(expr *ZeroOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.Offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.Expr)
+		p.popV()
+		if !ok {
+			return vals, true
+		}
+		if p.pt.Offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *Parser) parseZeroOrOneExpr(expr *ZeroOrOneExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrOneExpr"))
+	}
+
+	p.pushV()
+	val, _ := p.parseExpr(expr.Expr)
+	p.popV()
+	// whether it matched or not, consider it a match
+	return val, true
+}
@@ -0,0 +1,330 @@
+// Package sample generates random samples of the input accepted by a PEG
+// grammar, e.g. to test the consumers of a parser with valid inputs at
+// scale.
+//
+// A sample is generated by walking the expressions of the grammar from a
+// rule: an alternative of each choice, a number of repetitions, a member of
+// each character class and the case of the literals that ignore it are
+// picked at random. Past a maximum depth of rules, the alternatives and
+// repetitions that end the sample the soonest are picked, so that the
+// samples are finite.
+//
+// The ordered choices and the greedy repetitions of PEG mean that such a
+// sample is not necessarily accepted by the grammar, and the and and not
+// predicates do not generate any input. Each sample is thus checked by
+// parsing it with the grammar with the interp package, and it is rejected
+// and generated again if it does not match the rule entirely. As in the
+// interp package, the code blocks of the grammar are not run.
+package sample
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/interp"
+	"github.com/mna/pigeon/runtime"
+)
+
+// infinite is the minimal depth of the expressions that cannot generate a
+// finite sample.
+const infinite = math.MaxInt32
+
+// Generator generates random samples of the input accepted by a grammar.
+type Generator struct {
+	// MaxDepth is the depth of rules past which the alternatives and the
+	// repetitions that end the sample the soonest are picked.
+	MaxDepth int
+
+	// MaxRepeat is the maximum number of repetitions of the expressions of
+	// the * and + operators.
+	MaxRepeat int
+
+	// MaxAttempts is the maximum number of samples that are generated and
+	// rejected before Sample fails.
+	MaxAttempts int
+
+	g        *ast.Grammar
+	rnd      *rand.Rand
+	rules    map[string]*ast.Rule
+	minDepth map[string]int
+}
+
+// New returns a generator of samples for the grammar g, which picks at
+// random with rnd. The grammar must be ready to be parsed with the interp
+// package: valid, with its parameterized rules expanded and its
+// left-recursive rules marked.
+func New(g *ast.Grammar, rnd *rand.Rand) *Generator {
+	gen := &Generator{
+		MaxDepth:    10,
+		MaxRepeat:   3,
+		MaxAttempts: 1000,
+		g:           g,
+		rnd:         rnd,
+		rules:       make(map[string]*ast.Rule, len(g.Rules)),
+		minDepth:    make(map[string]int, len(g.Rules)),
+	}
+	for _, r := range g.Rules {
+		gen.rules[r.Name.Val] = r
+		gen.minDepth[r.Name.Val] = infinite
+	}
+
+	// the minimal depth of the rules is computed until it is stable, as
+	// the rules may be recursive
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if d := gen.depth(r.Expr); d < infinite && d+1 < gen.minDepth[r.Name.Val] {
+				gen.minDepth[r.Name.Val] = d + 1
+				changed = true
+			}
+		}
+	}
+	return gen
+}
+
+// Sample returns a random sample of the input accepted by the rule. It
+// returns an error if the rule is not defined, or if it cannot generate a
+// sample that the grammar accepts in MaxAttempts attempts.
+func (gen *Generator) Sample(rule string) (string, error) {
+	r, ok := gen.rules[rule]
+	if !ok {
+		return "", fmt.Errorf("undefined rule: %s", rule)
+	}
+	if gen.minDepth[rule] == infinite {
+		return "", fmt.Errorf("rule %s: no finite sample", rule)
+	}
+
+	var err error
+	for i := 0; i < gen.MaxAttempts; i++ {
+		var buf strings.Builder
+		if !gen.expr(&buf, r.Expr, 1) {
+			continue
+		}
+		s := buf.String()
+		if err = gen.check(rule, s); err == nil {
+			return s, nil
+		}
+	}
+	msg := fmt.Sprintf("rule %s: no sample accepted in %d attempts", rule, gen.MaxAttempts)
+	if err != nil {
+		msg += ", last error: " + err.Error()
+	}
+	return "", errors.New(msg)
+}
+
+// check returns an error if the rule does not match all of s.
+func (gen *Generator) check(rule, s string) error {
+	n, err := interp.Parse(gen.g, "", []byte(s), runtime.Entrypoint(rule))
+	if err != nil {
+		return err
+	}
+	if n.End < len(s) {
+		return fmt.Errorf("rule %s: match ends at offset %d before the end of the sample", rule, n.End)
+	}
+	return nil
+}
+
+// depth returns the minimal depth of rules needed by expr to generate a
+// sample, with the minimal depth of the rules computed so far.
+func (gen *Generator) depth(expr ast.Expression) int {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return gen.depth(expr.Expr)
+	case *ast.ChoiceExpr:
+		min := infinite
+		for _, alt := range expr.Alternatives {
+			if d := gen.depth(alt); d < min {
+				min = d
+			}
+		}
+		return min
+	case *ast.LabeledExpr:
+		return gen.depth(expr.Expr)
+	case *ast.OneOrMoreExpr:
+		return gen.depth(expr.Expr)
+	case *ast.RecoveryExpr:
+		return gen.depth(expr.Expr)
+	case *ast.RuleRefExpr:
+		return gen.minDepth[expr.Name.Val]
+	case *ast.SeqExpr:
+		max := 0
+		for _, e := range expr.Exprs {
+			if d := gen.depth(e); d > max {
+				max = d
+			}
+		}
+		return max
+	case *ast.ThrowExpr:
+		return infinite
+	}
+	// the matchers, the predicates, the code blocks and the optional
+	// repetitions may generate no rule at all
+	return 0
+}
+
+// expr writes a random sample of expr to buf, at the depth of rules. It
+// returns false if it cannot generate a sample.
+func (gen *Generator) expr(buf *strings.Builder, expr ast.Expression, depth int) bool {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return gen.expr(buf, expr.Expr, depth)
+	case *ast.AnyMatcher:
+		buf.WriteRune(gen.anyRune())
+	case *ast.CharClassMatcher:
+		rn, ok := gen.charClassRune(expr)
+		if !ok {
+			return false
+		}
+		buf.WriteRune(rn)
+	case *ast.ChoiceExpr:
+		return gen.expr(buf, gen.pick(expr.Alternatives, depth), depth)
+	case *ast.LabeledExpr:
+		return gen.expr(buf, expr.Expr, depth)
+	case *ast.LitMatcher:
+		for _, rn := range expr.Val {
+			if expr.IgnoreCase {
+				rn = gen.randomCase(rn)
+			}
+			buf.WriteRune(rn)
+		}
+	case *ast.OneOrMoreExpr:
+		return gen.repeat(buf, expr.Expr, 1, gen.MaxRepeat, depth)
+	case *ast.RecoveryExpr:
+		return gen.expr(buf, expr.Expr, depth)
+	case *ast.RuleRefExpr:
+		r, ok := gen.rules[expr.Name.Val]
+		if !ok || gen.minDepth[r.Name.Val] == infinite {
+			return false
+		}
+		return gen.expr(buf, r.Expr, depth+1)
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !gen.expr(buf, e, depth) {
+				return false
+			}
+		}
+	case *ast.ThrowExpr:
+		return false
+	case *ast.ZeroOrMoreExpr:
+		return gen.repeat(buf, expr.Expr, 0, gen.MaxRepeat, depth)
+	case *ast.ZeroOrOneExpr:
+		return gen.repeat(buf, expr.Expr, 0, 1, depth)
+	}
+	// the and and not predicates generate no input, the samples that do
+	// not satisfy them are rejected by the check of the sample
+	return true
+}
+
+// pick returns a random alternative among those that can end the sample
+// within MaxDepth, or among those that end it the soonest if there are
+// none.
+func (gen *Generator) pick(alts []ast.Expression, depth int) ast.Expression {
+	var candidates []ast.Expression
+	min := infinite
+	for _, alt := range alts {
+		d := gen.depth(alt)
+		if depth+d <= gen.MaxDepth {
+			candidates = append(candidates, alt)
+			continue
+		}
+		if d < min {
+			min = d
+		}
+	}
+	if len(candidates) == 0 {
+		for _, alt := range alts {
+			if gen.depth(alt) == min {
+				candidates = append(candidates, alt)
+			}
+		}
+	}
+	return candidates[gen.rnd.Intn(len(candidates))]
+}
+
+// repeat writes between min and max random samples of expr to buf, or min
+// samples if expr cannot end the sample within MaxDepth.
+func (gen *Generator) repeat(buf *strings.Builder, expr ast.Expression, min, max, depth int) bool {
+	n := min
+	if depth+gen.depth(expr) <= gen.MaxDepth && max > min {
+		n += gen.rnd.Intn(max - min + 1)
+	}
+	for i := 0; i < n; i++ {
+		if !gen.expr(buf, expr, depth) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyRunes are the runes picked for the any matcher and the inverted
+// character classes.
+var anyRunes = []*unicode.RangeTable{
+	{R16: []unicode.Range16{{Lo: '\t', Hi: '\n', Stride: 1}, {Lo: ' ', Hi: '~', Stride: 1}}},
+	unicode.Latin,
+	unicode.Greek,
+}
+
+// anyRune returns a random rune for the any matcher.
+func (gen *Generator) anyRune() rune {
+	return gen.tableRune(anyRunes[gen.rnd.Intn(len(anyRunes))])
+}
+
+// charClassRune returns a random rune matched by the character class ch.
+// It returns false if it cannot find one.
+func (gen *Generator) charClassRune(ch *ast.CharClassMatcher) (rune, bool) {
+	if ch.Inverted {
+		for i := 0; i < 100; i++ {
+			if rn := gen.anyRune(); ch.MatchesRune(rn) {
+				return rn, true
+			}
+		}
+		return 0, false
+	}
+
+	n := len(ch.Chars) + len(ch.Ranges)/2 + len(ch.UnicodeClasses)
+	if n == 0 {
+		return 0, false
+	}
+	var rn rune
+	switch i := gen.rnd.Intn(n); {
+	case i < len(ch.Chars):
+		rn = ch.Chars[i]
+	case i < len(ch.Chars)+len(ch.Ranges)/2:
+		i = 2 * (i - len(ch.Chars))
+		lo, hi := ch.Ranges[i], ch.Ranges[i+1]
+		rn = lo + rune(gen.rnd.Intn(int(hi-lo)+1))
+	default:
+		rn = gen.tableRune(ast.UnicodeClass(ch.UnicodeClasses[i-len(ch.Chars)-len(ch.Ranges)/2]))
+	}
+	if ch.IgnoreCase {
+		rn = gen.randomCase(rn)
+	}
+	return rn, true
+}
+
+// tableRune returns a random rune of the range table rt.
+func (gen *Generator) tableRune(rt *unicode.RangeTable) rune {
+	i := gen.rnd.Intn(len(rt.R16) + len(rt.R32))
+	var lo, hi, stride uint32
+	if i < len(rt.R16) {
+		r := rt.R16[i]
+		lo, hi, stride = uint32(r.Lo), uint32(r.Hi), uint32(r.Stride)
+	} else {
+		r := rt.R32[i-len(rt.R16)]
+		lo, hi, stride = r.Lo, r.Hi, r.Stride
+	}
+	return rune(lo + stride*uint32(gen.rnd.Intn(int((hi-lo)/stride)+1)))
+}
+
+// randomCase returns the lower or upper case of rn at random.
+func (gen *Generator) randomCase(rn rune) rune {
+	if gen.rnd.Intn(2) == 0 {
+		return unicode.ToLower(rn)
+	}
+	return unicode.ToUpper(rn)
+}
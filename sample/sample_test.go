@@ -0,0 +1,158 @@
+package sample
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/internal/asttest"
+	"github.com/mna/pigeon/interp"
+	"github.com/mna/pigeon/runtime"
+)
+
+// testGrammar returns the grammar:
+//
+//	Start = List !.
+//	List = Item ( "," _ Item )* _
+//	Item = Word / Number / "(" List ")"
+//	Word = !Keyword [\pL_] [\pL\pNd_]*
+//	Keyword = ( "if"i / "do"i ) ![\pL\pNd_]
+//	Number = "0x"i [0-9a-f]i+ / [0-9]+
+//	_ "whitespace" = [ \t]*
+func testGrammar() *ast.Grammar {
+	asttest.Reset()
+	return asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("List"), asttest.Not(asttest.Any()))),
+		asttest.Rule("List", asttest.Seq(
+			asttest.Ref("Item"),
+			asttest.Star(asttest.Seq(asttest.Lit(","), asttest.Ref("_"), asttest.Ref("Item"))),
+			asttest.Ref("_"),
+		)),
+		asttest.Rule("Item", asttest.Choice(
+			asttest.Ref("Word"),
+			asttest.Ref("Number"),
+			asttest.Seq(asttest.Lit("("), asttest.Ref("List"), asttest.Lit(")")),
+		)),
+		asttest.Rule("Word", asttest.Seq(
+			asttest.Not(asttest.Ref("Keyword")),
+			asttest.Class(`[\pL_]`),
+			asttest.Star(asttest.Class(`[\pL\pNd_]`)),
+		)),
+		asttest.Rule("Keyword", asttest.Seq(
+			asttest.Choice(asttest.LitI("if"), asttest.LitI("do")),
+			asttest.Not(asttest.Class(`[\pL\pNd_]`)),
+		)),
+		asttest.Rule("Number", asttest.Choice(
+			asttest.Seq(asttest.LitI("0x"), asttest.Plus(asttest.Class("[0-9a-f]i"))),
+			asttest.Plus(asttest.Class("[0-9]")),
+		)),
+		asttest.NamedRule("_", "whitespace", asttest.Star(asttest.Class(`[ \t]`))),
+	)
+}
+
+func TestSample(t *testing.T) {
+	g := testGrammar()
+	gen := New(g, rand.New(rand.NewSource(1)))
+
+	for _, rule := range []string{"Start", "Item", "Word"} {
+		for i := 0; i < 100; i++ {
+			s, err := gen.Sample(rule)
+			if err != nil {
+				t.Fatalf("%s: %v", rule, err)
+			}
+			n, err := interp.Parse(g, "", []byte(s), runtime.Entrypoint(rule))
+			if err != nil {
+				t.Fatalf("%s: %q: %v", rule, s, err)
+			}
+			if n.End != len(s) {
+				t.Fatalf("%s: %q: want a match of the whole sample, got %d bytes", rule, s, n.End)
+			}
+			if rule == "Word" {
+				if w := strings.ToLower(s); w == "if" || w == "do" {
+					t.Fatalf("%s: want no keyword, got %q", rule, s)
+				}
+			}
+		}
+	}
+}
+
+func TestSampleMaxDepth(t *testing.T) {
+	// A = "(" A ")" / B
+	// B = "b"
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("A", asttest.Choice(asttest.Seq(asttest.Lit("("), asttest.Ref("A"), asttest.Lit(")")), asttest.Ref("B"))),
+		asttest.Rule("B", asttest.Lit("b")),
+	)
+	gen := New(g, rand.New(rand.NewSource(1)))
+	gen.MaxDepth = 4
+	for i := 0; i < 100; i++ {
+		s, err := gen.Sample("A")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s) > 5 {
+			t.Fatalf("want at most 2 nested parentheses, got %q", s)
+		}
+	}
+}
+
+func TestSampleErrors(t *testing.T) {
+	// A = "a" A
+	// B = "b"* "b"
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("A", asttest.Seq(asttest.Lit("a"), asttest.Ref("A"))),
+		asttest.Rule("B", asttest.Seq(asttest.Star(asttest.Lit("b")), asttest.Lit("b"))),
+	)
+	gen := New(g, rand.New(rand.NewSource(1)))
+	gen.MaxAttempts = 10
+
+	want := map[string]string{
+		"A": "rule A: no finite sample",
+		"B": "rule B: no sample accepted in 10 attempts, last error: 1:",
+		"C": "undefined rule: C",
+	}
+	for rule, msg := range want {
+		_, err := gen.Sample(rule)
+		if err == nil {
+			t.Errorf("%s: want error, got none", rule)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), msg) {
+			t.Errorf("%s: want error starting with %q, got %q", rule, msg, err)
+		}
+	}
+}
+
+func TestSampleRuleParams(t *testing.T) {
+	// Start = Sep<Digit, ","> !.
+	// Sep<X, S> = X ( S X )*
+	// Digit <int> = [0-9]
+	asttest.Reset()
+	g := asttest.Grammar(
+		asttest.Rule("Start", asttest.Seq(asttest.Ref("Sep", asttest.Ref("Digit"), asttest.Lit(",")), asttest.Not(asttest.Any()))),
+		asttest.ParamRule("Sep", asttest.Seq(
+			asttest.Ref("X"),
+			asttest.Star(asttest.Seq(asttest.Ref("S"), asttest.Ref("X"))),
+		), "X", "S"),
+		asttest.TypedRule("Digit", "int", asttest.Class("[0-9]")),
+	)
+	if err := ast.ExpandRuleParams(g); err != nil {
+		t.Fatal(err)
+	}
+	gen := New(g, rand.New(rand.NewSource(1)))
+
+	for i := 0; i < 100; i++ {
+		s, err := gen.Sample("Start")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range strings.Split(s, ",") {
+			if len(d) != 1 || d[0] < '0' || d[0] > '9' {
+				t.Fatalf("want a comma-separated list of digits, got %q", s)
+			}
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package altentry
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+
+	native "github.com/mna/pigeon/test/alternate_entrypoint/native"
 )
 
 func TestValidEntrypoints(t *testing.T) {
@@ -57,3 +61,30 @@ func TestInvalidEntrypoints(t *testing.T) {
 		}
 	}
 }
+
+func TestNativeEntrypoints(t *testing.T) {
+	cases := []struct {
+		in         string
+		entrypoint string
+	}{
+		{"aacc", ""},
+		{"bbbcc", "Entry2"},
+		{"cc", "Entry3"},
+		{"cc", "C"},
+		{"bbbcc", ""},
+		{"aacc", "Entry2"},
+		{"bbbcc", "Z"},
+		{"aa", "A"},
+	}
+
+	for _, c := range cases {
+		want, wantErr := Parse("", []byte(c.in), Entrypoint(c.entrypoint))
+		got, gotErr := native.Parse("", []byte(c.in), native.Entrypoint(c.entrypoint))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:%s: want %#v, got %#v", c.entrypoint, c.in, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%s:%s: want error %v, got %v", c.entrypoint, c.in, wantErr, gotErr)
+		}
+	}
+}
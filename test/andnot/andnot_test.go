@@ -1,6 +1,12 @@
 package andnot
 
-import "testing"
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	native "github.com/mna/pigeon/test/andnot/native"
+)
 
 // ABs must end in Bs, CDs must end in Ds
 var cases = map[string]string{
@@ -34,3 +40,18 @@ func TestAndNot(t *testing.T) {
 		}
 	}
 }
+
+// TestAndNotNative checks that the predicates of the native functions
+// parser match and fail like those of the standard parser.
+func TestAndNotNative(t *testing.T) {
+	for in := range cases {
+		want, wantErr := Parse("", []byte(in))
+		got, gotErr := native.Parse("", []byte(in))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %#v, got %#v", in, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%q: want error %v, got %v", in, wantErr, gotErr)
+		}
+	}
+}
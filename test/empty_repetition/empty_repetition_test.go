@@ -0,0 +1,35 @@
+package emptyrepetition
+
+import "testing"
+
+func TestEmptyRepetition(t *testing.T) {
+	cases := []struct {
+		input string
+		want  [2]int
+		err   bool
+	}{
+		{input: "", err: true},
+		{input: "c", want: [2]int{1, 1}},
+		{input: "b", want: [2]int{1, 1}},
+		{input: "aab", want: [2]int{2, 1}},
+		{input: "aabbc", want: [2]int{2, 2}},
+		{input: "abca", err: true},
+	}
+
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.input), MaxExpressions(1000))
+		if c.err {
+			if err == nil {
+				t.Errorf("%q: want error, got %v", c.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", c.input, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%q: want %v, got %v", c.input, c.want, got)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package errorpos
 
-import "testing"
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	native "github.com/mna/pigeon/test/errorpos/native"
+)
 
 var cases = map[string]string{
 	"case01 zero":           ``,
@@ -52,3 +58,18 @@ func TestErrorPos(t *testing.T) {
 		}
 	}
 }
+
+// TestErrorPosNative checks that the native functions parser reports its
+// errors at the same positions and with the same expected matchers.
+func TestErrorPosNative(t *testing.T) {
+	for in := range cases {
+		want, wantErr := Parse("", []byte(in))
+		got, gotErr := native.Parse("", []byte(in))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %#v, got %#v", in, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%q: want error %v, got %v", in, wantErr, gotErr)
+		}
+	}
+}
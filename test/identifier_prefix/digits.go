@@ -0,0 +1,1389 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -identifier-prefix=Digits -nolint
+
+// Package identifierprefix tests two parsers generated in the same
+// package, with different identifier prefixes.
+package identifierprefix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var digitsG = &digitsGrammar{
+	rules: []*digitsRule{
+		{
+			name: "Number",
+			pos:  digitsPosition{line: 7, col: 1, offset: 146},
+			expr: &digitsActionExpr{
+				pos: digitsPosition{line: 7, col: 10, offset: 155},
+				run: (*digitsParser).callonNumber1,
+				expr: &digitsSeqExpr{
+					pos: digitsPosition{line: 7, col: 10, offset: 155},
+					exprs: []interface{}{
+						&digitsOneOrMoreExpr{
+							pos: digitsPosition{line: 7, col: 10, offset: 155},
+							expr: &digitsCharClassMatcher{
+								pos:        digitsPosition{line: 7, col: 10, offset: 155},
+								val:        "[0-9]",
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&digitsNotExpr{
+							pos: digitsPosition{line: 7, col: 17, offset: 162},
+							expr: &digitsAnyMatcher{
+								line: 7, col: 18, offset: 163,
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (c *digitsCurrent) onNumber1() (interface{}, error) {
+	return strconv.Atoi(string(c.text))
+}
+
+func (p *digitsParser) callonNumber1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNumber1()
+}
+
+var (
+	// digitsErrNoRule is returned when the grammar to parse has no rule.
+	digitsErrNoRule = errors.New("grammar has no rule")
+
+	// digitsErrInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	digitsErrInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// digitsErrInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	digitsErrInvalidEncoding = errors.New("invalid encoding")
+
+	// digitsErrMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	digitsErrMaxExprCnt = errors.New("max number of expresssions parsed")
+)
+
+// DigitsOption is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type DigitsOption func(*digitsParser) DigitsOption
+
+// DigitsMaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func DigitsMaxExpressions(maxExprCnt uint64) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return DigitsMaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// DigitsEntrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func DigitsEntrypoint(ruleName string) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		if ruleName == "" {
+			p.entrypoint = digitsG.rules[0].name
+		}
+		return DigitsEntrypoint(oldEntrypoint)
+	}
+}
+
+// DigitsStatistics adds a user provided Stats struct to the parser to allow
+// the user to process the results after the parsing has finished.
+// Also the key for the "no match" counter is set.
+//
+// Example usage:
+//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
+func DigitsStatistics(stats *DigitsStats, choiceNoMatch string) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		oldStats := p.DigitsStats
+		p.DigitsStats = stats
+		oldChoiceNoMatch := p.choiceNoMatch
+		p.choiceNoMatch = choiceNoMatch
+		if p.DigitsStats.ChoiceAltCnt == nil {
+			p.DigitsStats.ChoiceAltCnt = make(map[string]map[string]int)
+		}
+		return DigitsStatistics(oldStats, oldChoiceNoMatch)
+	}
+}
+
+// DigitsDebug creates an Option to set the debug flag to b. When set to true,
+// debugging information is printed to stdout while parsing.
+//
+// The default is false.
+func DigitsDebug(b bool) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.debug
+		p.debug = b
+		return DigitsDebug(old)
+	}
+}
+
+// DigitsMemoize creates an Option to set the memoize flag to b. When set to true,
+// the parser will cache all results so each expression is evaluated only
+// once. This guarantees linear parsing time even for pathological cases,
+// at the expense of more memory and slower times for typical cases.
+//
+// The default is false.
+func DigitsMemoize(b bool) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.memoize
+		p.memoize = b
+		return DigitsMemoize(old)
+	}
+}
+
+// DigitsAllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func DigitsAllowInvalidUTF8(b bool) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return DigitsAllowInvalidUTF8(old)
+	}
+}
+
+// DigitsRecover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func DigitsRecover(b bool) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.recover
+		p.recover = b
+		return DigitsRecover(old)
+	}
+}
+
+// DigitsGlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func DigitsGlobalStore(key string, value interface{}) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.cur.globalStore[key]
+		p.cur.globalStore[key] = value
+		return DigitsGlobalStore(key, old)
+	}
+}
+
+// DigitsInitState creates an Option to set a key to a certain value in
+// the global "state" store.
+func DigitsInitState(key string, value interface{}) DigitsOption {
+	return func(p *digitsParser) DigitsOption {
+		old := p.cur.state[key]
+		p.cur.state[key] = value
+		return DigitsInitState(key, old)
+	}
+}
+
+// DigitsParseFile parses the file identified by filename.
+func DigitsParseFile(filename string, opts ...DigitsOption) (i interface{}, err error) { // nolint: deadcode
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return DigitsParseReader(filename, f, opts...)
+}
+
+// DigitsParseReader parses the data from r using filename as information in the
+// error messages.
+func DigitsParseReader(filename string, r io.Reader, opts ...DigitsOption) (interface{}, error) { // nolint: deadcode
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DigitsParse(filename, b, opts...)
+}
+
+// DigitsParse parses the data from b using filename as information in the
+// error messages.
+func DigitsParse(filename string, b []byte, opts ...DigitsOption) (interface{}, error) {
+	return digitsNewParser(filename, b, opts...).parse(digitsG)
+}
+
+// digitsPosition records a position in the text.
+type digitsPosition struct {
+	line, col, offset int
+}
+
+func (p digitsPosition) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+// digitsSavepoint stores all state required to go back to this point in the
+// parser.
+type digitsSavepoint struct {
+	digitsPosition
+	rn rune
+	w  int
+}
+
+type digitsCurrent struct {
+	pos  digitsPosition // start position of the match
+	text []byte         // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state digitsStoreDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore digitsStoreDict
+}
+
+type digitsStoreDict map[string]interface{}
+
+// the AST types...
+
+// nolint: structcheck
+type digitsGrammar struct {
+	pos   digitsPosition
+	rules []*digitsRule
+}
+
+// nolint: structcheck
+type digitsRule struct {
+	pos         digitsPosition
+	name        string
+	displayName string
+	expr        interface{}
+}
+
+// nolint: structcheck
+type digitsChoiceExpr struct {
+	pos          digitsPosition
+	alternatives []interface{}
+}
+
+// nolint: structcheck
+type digitsActionExpr struct {
+	pos  digitsPosition
+	expr interface{}
+	run  func(*digitsParser) (interface{}, error)
+}
+
+// nolint: structcheck
+type digitsRecoveryExpr struct {
+	pos          digitsPosition
+	expr         interface{}
+	recoverExpr  interface{}
+	failureLabel []string
+}
+
+// nolint: structcheck
+type digitsSeqExpr struct {
+	pos   digitsPosition
+	exprs []interface{}
+}
+
+// nolint: structcheck
+type digitsThrowExpr struct {
+	pos   digitsPosition
+	label string
+}
+
+// nolint: structcheck
+type digitsLabeledExpr struct {
+	pos   digitsPosition
+	label string
+	expr  interface{}
+}
+
+// nolint: structcheck
+type digitsExpr struct {
+	pos  digitsPosition
+	expr interface{}
+}
+
+type digitsAndExpr digitsExpr        // nolint: structcheck
+type digitsNotExpr digitsExpr        // nolint: structcheck
+type digitsZeroOrOneExpr digitsExpr  // nolint: structcheck
+type digitsZeroOrMoreExpr digitsExpr // nolint: structcheck
+type digitsOneOrMoreExpr digitsExpr  // nolint: structcheck
+
+// nolint: structcheck
+type digitsRuleRefExpr struct {
+	pos  digitsPosition
+	name string
+}
+
+// nolint: structcheck
+type digitsStateCodeExpr struct {
+	pos digitsPosition
+	run func(*digitsParser) error
+}
+
+// nolint: structcheck
+type digitsAndCodeExpr struct {
+	pos digitsPosition
+	run func(*digitsParser) (bool, error)
+}
+
+// nolint: structcheck
+type digitsNotCodeExpr struct {
+	pos digitsPosition
+	run func(*digitsParser) (bool, error)
+}
+
+// nolint: structcheck
+type digitsLitMatcher struct {
+	pos        digitsPosition
+	val        string
+	ignoreCase bool
+}
+
+// nolint: structcheck
+type digitsCharClassMatcher struct {
+	pos             digitsPosition
+	val             string
+	basicLatinChars [128]bool
+	chars           []rune
+	ranges          []rune
+	classes         []*unicode.RangeTable
+	ignoreCase      bool
+	inverted        bool
+}
+
+type digitsAnyMatcher digitsPosition // nolint: structcheck
+
+// digitsErrList cumulates the errors found by the parser.
+type digitsErrList []error
+
+func (e *digitsErrList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e digitsErrList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *digitsErrList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+func (e digitsErrList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// digitsParserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type digitsParserError struct {
+	Inner    error
+	pos      digitsPosition
+	prefix   string
+	expected []string
+}
+
+// Error returns the error message.
+func (p *digitsParserError) Error() string {
+	return p.prefix + ": " + p.Inner.Error()
+}
+
+// digitsNewParser creates a parser with the specified input source and options.
+func digitsNewParser(filename string, b []byte, opts ...DigitsOption) *digitsParser {
+	stats := DigitsStats{
+		ChoiceAltCnt: make(map[string]map[string]int),
+	}
+
+	p := &digitsParser{
+		filename: filename,
+		errs:     new(digitsErrList),
+		data:     b,
+		pt:       digitsSavepoint{digitsPosition: digitsPosition{line: 1}},
+		recover:  true,
+		cur: digitsCurrent{
+			state:       make(digitsStoreDict),
+			globalStore: make(digitsStoreDict),
+		},
+		maxFailPos:      digitsPosition{col: 1, line: 1},
+		maxFailExpected: make([]string, 0, 20),
+		DigitsStats:     &stats,
+		// start rule is rule [0] unless an alternate entrypoint is specified
+		entrypoint: digitsG.rules[0].name,
+		emptyState: make(digitsStoreDict),
+	}
+	p.setOptions(opts)
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+
+	return p
+}
+
+// setOptions applies the options to the parser.
+func (p *digitsParser) setOptions(opts []DigitsOption) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+// nolint: structcheck,deadcode
+type digitsResultTuple struct {
+	v   interface{}
+	b   bool
+	end digitsSavepoint
+}
+
+// nolint: varcheck
+const digitsChoiceNoMatch = -1
+
+// DigitsStats stores some statistics, gathered during parsing
+type DigitsStats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+// nolint: structcheck,maligned
+type digitsParser struct {
+	filename string
+	pt       digitsSavepoint
+	cur      digitsCurrent
+
+	data []byte
+	errs *digitsErrList
+
+	depth   int
+	recover bool
+	debug   bool
+
+	memoize bool
+	// memoization table for the packrat algorithm:
+	// map[offset in source] map[expression or rule] {value, match}
+	memo map[int]map[interface{}]digitsResultTuple
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*digitsRule
+	// variables stack, map of label to value
+	vstack []map[string]interface{}
+	// rule stack, allows identification of the current rule in errors
+	rstack []*digitsRule
+
+	// parse fail
+	maxFailPos            digitsPosition
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+
+	*DigitsStats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]interface{}
+
+	// emptyState contains an empty storeDict, which is used to optimize cloneState if global "state" store is not used.
+	emptyState digitsStoreDict
+}
+
+// push a variable set on the vstack.
+func (p *digitsParser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]interface{})
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *digitsParser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *digitsParser) pushRecovery(labels []string, expr interface{}) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]interface{}, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *digitsParser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *digitsParser) print(prefix, s string) string {
+	if !p.debug {
+		return s
+	}
+
+	fmt.Printf("%s %d:%d:%d: %s [%#U]\n",
+		prefix, p.pt.line, p.pt.col, p.pt.offset, s, p.pt.rn)
+	return s
+}
+
+func (p *digitsParser) in(s string) string {
+	p.depth++
+	return p.print(strings.Repeat(" ", p.depth)+">", s)
+}
+
+func (p *digitsParser) out(s string) string {
+	p.depth--
+	return p.print(strings.Repeat(" ", p.depth)+"<", s)
+}
+
+func (p *digitsParser) addErr(err error) {
+	p.addErrAt(err, p.pt.digitsPosition, []string{})
+}
+
+func (p *digitsParser) addErrAt(err error, pos digitsPosition, expected []string) {
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.line, pos.col, pos.offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.displayName != "" {
+			buf.WriteString("rule " + rule.displayName)
+		} else {
+			buf.WriteString("rule " + rule.name)
+		}
+	}
+	pe := &digitsParserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *digitsParser) failAt(fail bool, pos digitsPosition, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.offset < p.maxFailPos.offset {
+			return
+		}
+
+		if pos.offset > p.maxFailPos.offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+	}
+}
+
+// read advances the parser to the next rune.
+func (p *digitsParser) read() {
+	p.pt.offset += p.pt.w
+	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.col++
+	if rn == '\n' {
+		p.pt.line++
+		p.pt.col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(digitsErrInvalidEncoding)
+		}
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *digitsParser) restore(pt digitsSavepoint) {
+	if p.debug {
+		defer p.out(p.in("restore"))
+	}
+	if pt.offset == p.pt.offset {
+		return
+	}
+	p.pt = pt
+}
+
+// DigitsCloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+//
+// This is used in conjunction with the global state feature to create proper
+// copies of the state to allow the parser to properly restore the state in
+// the case of backtracking.
+type DigitsCloner interface {
+	Clone() interface{}
+}
+
+// clone and return parser current state.
+func (p *digitsParser) cloneState() digitsStoreDict {
+	if p.debug {
+		defer p.out(p.in("cloneState"))
+	}
+
+	if len(p.cur.state) == 0 {
+		if len(p.emptyState) > 0 {
+			p.emptyState = make(digitsStoreDict)
+		}
+		return p.emptyState
+	}
+
+	state := make(digitsStoreDict, len(p.cur.state))
+	for k, v := range p.cur.state {
+		if c, ok := v.(DigitsCloner); ok {
+			state[k] = c.Clone()
+		} else {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+// restore parser current state to the state storeDict.
+// every restoreState should applied only one time for every cloned state
+func (p *digitsParser) restoreState(state digitsStoreDict) {
+	if p.debug {
+		defer p.out(p.in("restoreState"))
+	}
+	p.cur.state = state
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *digitsParser) sliceFrom(start digitsSavepoint) []byte {
+	return p.data[start.digitsPosition.offset:p.pt.digitsPosition.offset]
+}
+
+func (p *digitsParser) getMemoized(node interface{}) (digitsResultTuple, bool) {
+	if len(p.memo) == 0 {
+		return digitsResultTuple{}, false
+	}
+	m := p.memo[p.pt.offset]
+	if len(m) == 0 {
+		return digitsResultTuple{}, false
+	}
+	res, ok := m[node]
+	return res, ok
+}
+
+func (p *digitsParser) setMemoized(pt digitsSavepoint, node interface{}, tuple digitsResultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[interface{}]digitsResultTuple)
+	}
+	m := p.memo[pt.offset]
+	if m == nil {
+		m = make(map[interface{}]digitsResultTuple)
+		p.memo[pt.offset] = m
+	}
+	m[node] = tuple
+}
+
+func (p *digitsParser) buildRulesTable(g *digitsGrammar) {
+	p.rules = make(map[string]*digitsRule, len(g.rules))
+	for _, r := range g.rules {
+		p.rules[r.name] = r
+	}
+}
+
+// nolint: gocyclo
+func (p *digitsParser) parse(g *digitsGrammar) (val interface{}, err error) {
+	if len(g.rules) == 0 {
+		p.addErr(digitsErrNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				if p.debug {
+					defer p.out(p.in("panic handler"))
+				}
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(digitsErrInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	p.read() // advance to first rune
+	val, ok = p.parseRule(startRule)
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+			for _, v := range p.maxFailExpected {
+				maxFailExpectedMap[v] = struct{}{}
+			}
+			expected := make([]string, 0, len(maxFailExpectedMap))
+			eof := false
+			if _, ok := maxFailExpectedMap["!."]; ok {
+				delete(maxFailExpectedMap, "!.")
+				eof = true
+			}
+			for k := range maxFailExpectedMap {
+				expected = append(expected, k)
+			}
+			sort.Strings(expected)
+			if eof {
+				expected = append(expected, "EOF")
+			}
+			p.addErrAt(errors.New("no match found, expected: "+digitsListJoin(expected, ", ", "or")), p.maxFailPos, expected)
+		}
+
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+func digitsListJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return fmt.Sprintf("%s %s %s", strings.Join(list[:len(list)-1], sep), lastSep, list[len(list)-1])
+	}
+}
+
+func (p *digitsParser) parseRule(rule *digitsRule) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRule " + rule.name))
+	}
+
+	if p.memoize {
+		res, ok := p.getMemoized(rule)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+	}
+
+	start := p.pt
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	val, ok := p.parseExpr(rule.expr)
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+
+	if p.memoize {
+		p.setMemoized(start, rule, digitsResultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+// nolint: gocyclo
+func (p *digitsParser) parseExpr(expr interface{}) (interface{}, bool) {
+	var pt digitsSavepoint
+
+	if p.memoize {
+		res, ok := p.getMemoized(expr)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+		pt = p.pt
+	}
+
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(digitsErrMaxExprCnt)
+	}
+
+	var val interface{}
+	var ok bool
+	switch expr := expr.(type) {
+	case *digitsActionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *digitsAndCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *digitsAndExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *digitsAnyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *digitsCharClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *digitsChoiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *digitsLabeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *digitsLitMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *digitsNotCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *digitsNotExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *digitsOneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *digitsRecoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *digitsRuleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *digitsSeqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *digitsStateCodeExpr:
+		val, ok = p.parseStateCodeExpr(expr)
+	case *digitsThrowExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *digitsZeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *digitsZeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	if p.memoize {
+		p.setMemoized(pt, expr, digitsResultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *digitsParser) parseActionExpr(act *digitsActionExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseActionExpr"))
+	}
+
+	start := p.pt
+	val, ok := p.parseExpr(act.expr)
+	if ok {
+		p.cur.pos = start.digitsPosition
+		p.cur.text = p.sliceFrom(start)
+		state := p.cloneState()
+		actVal, err := act.run(p)
+		if err != nil {
+			p.addErrAt(err, start.digitsPosition, []string{})
+		}
+		p.restoreState(state)
+
+		val = actVal
+	}
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	return val, ok
+}
+
+func (p *digitsParser) parseAndCodeExpr(and *digitsAndCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := and.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, ok
+}
+
+func (p *digitsParser) parseAndExpr(and *digitsAndExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	_, ok := p.parseExpr(and.expr)
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, ok
+}
+
+func (p *digitsParser) parseAnyMatcher(any *digitsAnyMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAnyMatcher"))
+	}
+
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.digitsPosition, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.digitsPosition, ".")
+	return p.sliceFrom(start), true
+}
+
+// nolint: gocyclo
+func (p *digitsParser) parseCharClassMatcher(chr *digitsCharClassMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseCharClassMatcher"))
+	}
+
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.digitsPosition, chr.val)
+		return nil, false
+	}
+
+	if chr.ignoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.chars {
+		if rn == cur {
+			if chr.inverted {
+				p.failAt(false, start.digitsPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.digitsPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.ranges); i += 2 {
+		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
+			if chr.inverted {
+				p.failAt(false, start.digitsPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.digitsPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.classes {
+		if unicode.Is(cl, cur) {
+			if chr.inverted {
+				p.failAt(false, start.digitsPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.digitsPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.inverted {
+		p.read()
+		p.failAt(true, start.digitsPosition, chr.val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.digitsPosition, chr.val)
+	return nil, false
+}
+
+func (p *digitsParser) incChoiceAltCnt(ch *digitsChoiceExpr, altI int) {
+	choiceIdent := fmt.Sprintf("%s %d:%d", p.rstack[len(p.rstack)-1].name, ch.pos.line, ch.pos.col)
+	m := p.ChoiceAltCnt[choiceIdent]
+	if m == nil {
+		m = make(map[string]int)
+		p.ChoiceAltCnt[choiceIdent] = m
+	}
+	// We increment altI by 1, so the keys do not start at 0
+	alt := strconv.Itoa(altI + 1)
+	if altI == digitsChoiceNoMatch {
+		alt = p.choiceNoMatch
+	}
+	m[alt]++
+}
+
+func (p *digitsParser) parseChoiceExpr(ch *digitsChoiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseChoiceExpr"))
+	}
+
+	for altI, alt := range ch.alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		state := p.cloneState()
+
+		p.pushV()
+		val, ok := p.parseExpr(alt)
+		p.popV()
+		if ok {
+			p.incChoiceAltCnt(ch, altI)
+			return val, ok
+		}
+		p.restoreState(state)
+	}
+	p.incChoiceAltCnt(ch, digitsChoiceNoMatch)
+	return nil, false
+}
+
+func (p *digitsParser) parseLabeledExpr(lab *digitsLabeledExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLabeledExpr"))
+	}
+
+	p.pushV()
+	val, ok := p.parseExpr(lab.expr)
+	p.popV()
+	if ok && lab.label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.label] = val
+	}
+	return val, ok
+}
+
+func (p *digitsParser) parseLitMatcher(lit *digitsLitMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLitMatcher"))
+	}
+
+	ignoreCase := ""
+	if lit.ignoreCase {
+		ignoreCase = "i"
+	}
+	val := fmt.Sprintf("%q%s", lit.val, ignoreCase)
+	start := p.pt
+	for _, want := range lit.val {
+		cur := p.pt.rn
+		if lit.ignoreCase {
+			cur = unicode.ToLower(cur)
+		}
+		if cur != want {
+			p.failAt(false, start.digitsPosition, val)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.digitsPosition, val)
+	return p.sliceFrom(start), true
+}
+
+func (p *digitsParser) parseNotCodeExpr(not *digitsNotCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := not.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, !ok
+}
+
+func (p *digitsParser) parseNotExpr(not *digitsNotExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExpr(not.expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *digitsParser) parseOneOrMoreExpr(expr *digitsOneOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseOneOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *digitsParser) parseRecoveryExpr(recover *digitsRecoveryExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
+	}
+
+	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
+	val, ok := p.parseExpr(recover.expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *digitsParser) parseRuleRefExpr(ref *digitsRuleRefExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRefExpr " + ref.name))
+	}
+
+	if ref.name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.pos))
+	}
+
+	rule := p.rules[ref.name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
+		return nil, false
+	}
+	return p.parseRule(rule)
+}
+
+func (p *digitsParser) parseSeqExpr(seq *digitsSeqExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseSeqExpr"))
+	}
+
+	vals := make([]interface{}, 0, len(seq.exprs))
+
+	pt := p.pt
+	state := p.cloneState()
+	for _, expr := range seq.exprs {
+		val, ok := p.parseExpr(expr)
+		if !ok {
+			p.restoreState(state)
+			p.restore(pt)
+			return nil, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+func (p *digitsParser) parseStateCodeExpr(state *digitsStateCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseStateCodeExpr"))
+	}
+
+	err := state.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	return nil, true
+}
+
+func (p *digitsParser) parseThrowExpr(expr *digitsThrowExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseThrowExpr"))
+	}
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
+			if val, ok := p.parseExpr(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *digitsParser) parseZeroOrMoreExpr(expr *digitsZeroOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *digitsParser) parseZeroOrOneExpr(expr *digitsZeroOrOneExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrOneExpr"))
+	}
+
+	p.pushV()
+	val, _ := p.parseExpr(expr.expr)
+	p.popV()
+	// whether it matched or not, consider it a match
+	return val, true
+}
@@ -0,0 +1,27 @@
+package identifierprefix
+
+import "testing"
+
+func TestIdentifierPrefix(t *testing.T) {
+	got, err := DigitsParse("", []byte("123"), DigitsMemoize(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 123 {
+		t.Errorf("digits: want 123, got %v", got)
+	}
+	if _, err := DigitsParse("", []byte("12a")); err == nil {
+		t.Errorf("digits: want error, got none")
+	}
+
+	got, err = LettersParse("", []byte("abc"), LettersRecover(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ABC" {
+		t.Errorf("letters: want %q, got %v", "ABC", got)
+	}
+	if _, err := LettersParse("", []byte("ab1")); err == nil {
+		t.Errorf("letters: want error, got none")
+	}
+}
@@ -0,0 +1,1077 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -identifier-prefix=Letters -nolint -optimize-parser
+
+package identifierprefix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func upper(b []byte) string {
+	return strings.ToUpper(string(b))
+}
+
+var lettersG = &lettersGrammar{
+	rules: []*lettersRule{
+		{
+			name: "Word",
+			pos:  lettersPosition{line: 9, col: 1, offset: 98},
+			expr: &lettersActionExpr{
+				pos: lettersPosition{line: 9, col: 8, offset: 105},
+				run: (*lettersParser).callonWord1,
+				expr: &lettersSeqExpr{
+					pos: lettersPosition{line: 9, col: 8, offset: 105},
+					exprs: []interface{}{
+						&lettersOneOrMoreExpr{
+							pos: lettersPosition{line: 9, col: 8, offset: 105},
+							expr: &lettersCharClassMatcher{
+								pos:        lettersPosition{line: 9, col: 8, offset: 105},
+								val:        "[\\pL]",
+								classes:    []*unicode.RangeTable{lettersRangeTable("L")},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&lettersNotExpr{
+							pos: lettersPosition{line: 9, col: 15, offset: 112},
+							expr: &lettersAnyMatcher{
+								line: 9, col: 16, offset: 113,
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func (c *lettersCurrent) onWord1() (interface{}, error) {
+	return upper(c.text), nil
+}
+
+func (p *lettersParser) callonWord1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onWord1()
+}
+
+var (
+	// lettersErrNoRule is returned when the grammar to parse has no rule.
+	lettersErrNoRule = errors.New("grammar has no rule")
+
+	// lettersErrInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	lettersErrInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// lettersErrInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	lettersErrInvalidEncoding = errors.New("invalid encoding")
+
+	// lettersErrMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	lettersErrMaxExprCnt = errors.New("max number of expresssions parsed")
+)
+
+// LettersOption is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type LettersOption func(*lettersParser) LettersOption
+
+// LettersMaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func LettersMaxExpressions(maxExprCnt uint64) LettersOption {
+	return func(p *lettersParser) LettersOption {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return LettersMaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// LettersEntrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func LettersEntrypoint(ruleName string) LettersOption {
+	return func(p *lettersParser) LettersOption {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		if ruleName == "" {
+			p.entrypoint = lettersG.rules[0].name
+		}
+		return LettersEntrypoint(oldEntrypoint)
+	}
+}
+
+// LettersAllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func LettersAllowInvalidUTF8(b bool) LettersOption {
+	return func(p *lettersParser) LettersOption {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return LettersAllowInvalidUTF8(old)
+	}
+}
+
+// LettersRecover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func LettersRecover(b bool) LettersOption {
+	return func(p *lettersParser) LettersOption {
+		old := p.recover
+		p.recover = b
+		return LettersRecover(old)
+	}
+}
+
+// LettersGlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func LettersGlobalStore(key string, value interface{}) LettersOption {
+	return func(p *lettersParser) LettersOption {
+		old := p.cur.globalStore[key]
+		p.cur.globalStore[key] = value
+		return LettersGlobalStore(key, old)
+	}
+}
+
+// LettersParseFile parses the file identified by filename.
+func LettersParseFile(filename string, opts ...LettersOption) (i interface{}, err error) { // nolint: deadcode
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return LettersParseReader(filename, f, opts...)
+}
+
+// LettersParseReader parses the data from r using filename as information in the
+// error messages.
+func LettersParseReader(filename string, r io.Reader, opts ...LettersOption) (interface{}, error) { // nolint: deadcode
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return LettersParse(filename, b, opts...)
+}
+
+// LettersParse parses the data from b using filename as information in the
+// error messages.
+func LettersParse(filename string, b []byte, opts ...LettersOption) (interface{}, error) {
+	return lettersNewParser(filename, b, opts...).parse(lettersG)
+}
+
+// lettersPosition records a position in the text.
+type lettersPosition struct {
+	line, col, offset int
+}
+
+func (p lettersPosition) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+// lettersSavepoint stores all state required to go back to this point in the
+// parser.
+type lettersSavepoint struct {
+	lettersPosition
+	rn rune
+	w  int
+}
+
+type lettersCurrent struct {
+	pos  lettersPosition // start position of the match
+	text []byte          // raw text of the match
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore lettersStoreDict
+}
+
+type lettersStoreDict map[string]interface{}
+
+// the AST types...
+
+// nolint: structcheck
+type lettersGrammar struct {
+	pos   lettersPosition
+	rules []*lettersRule
+}
+
+// nolint: structcheck
+type lettersRule struct {
+	pos         lettersPosition
+	name        string
+	displayName string
+	expr        interface{}
+}
+
+// nolint: structcheck
+type lettersChoiceExpr struct {
+	pos          lettersPosition
+	alternatives []interface{}
+}
+
+// nolint: structcheck
+type lettersActionExpr struct {
+	pos  lettersPosition
+	expr interface{}
+	run  func(*lettersParser) (interface{}, error)
+}
+
+// nolint: structcheck
+type lettersRecoveryExpr struct {
+	pos          lettersPosition
+	expr         interface{}
+	recoverExpr  interface{}
+	failureLabel []string
+}
+
+// nolint: structcheck
+type lettersSeqExpr struct {
+	pos   lettersPosition
+	exprs []interface{}
+}
+
+// nolint: structcheck
+type lettersThrowExpr struct {
+	pos   lettersPosition
+	label string
+}
+
+// nolint: structcheck
+type lettersLabeledExpr struct {
+	pos   lettersPosition
+	label string
+	expr  interface{}
+}
+
+// nolint: structcheck
+type lettersExpr struct {
+	pos  lettersPosition
+	expr interface{}
+}
+
+type lettersAndExpr lettersExpr        // nolint: structcheck
+type lettersNotExpr lettersExpr        // nolint: structcheck
+type lettersZeroOrOneExpr lettersExpr  // nolint: structcheck
+type lettersZeroOrMoreExpr lettersExpr // nolint: structcheck
+type lettersOneOrMoreExpr lettersExpr  // nolint: structcheck
+
+// nolint: structcheck
+type lettersRuleRefExpr struct {
+	pos  lettersPosition
+	name string
+}
+
+// nolint: structcheck
+type lettersAndCodeExpr struct {
+	pos lettersPosition
+	run func(*lettersParser) (bool, error)
+}
+
+// nolint: structcheck
+type lettersNotCodeExpr struct {
+	pos lettersPosition
+	run func(*lettersParser) (bool, error)
+}
+
+// nolint: structcheck
+type lettersLitMatcher struct {
+	pos        lettersPosition
+	val        string
+	ignoreCase bool
+}
+
+// nolint: structcheck
+type lettersCharClassMatcher struct {
+	pos             lettersPosition
+	val             string
+	basicLatinChars [128]bool
+	chars           []rune
+	ranges          []rune
+	classes         []*unicode.RangeTable
+	ignoreCase      bool
+	inverted        bool
+}
+
+type lettersAnyMatcher lettersPosition // nolint: structcheck
+
+// lettersErrList cumulates the errors found by the parser.
+type lettersErrList []error
+
+func (e *lettersErrList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e lettersErrList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *lettersErrList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+func (e lettersErrList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// lettersParserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type lettersParserError struct {
+	Inner    error
+	pos      lettersPosition
+	prefix   string
+	expected []string
+}
+
+// Error returns the error message.
+func (p *lettersParserError) Error() string {
+	return p.prefix + ": " + p.Inner.Error()
+}
+
+// lettersNewParser creates a parser with the specified input source and options.
+func lettersNewParser(filename string, b []byte, opts ...LettersOption) *lettersParser {
+	stats := LettersStats{
+		ChoiceAltCnt: make(map[string]map[string]int),
+	}
+
+	p := &lettersParser{
+		filename: filename,
+		errs:     new(lettersErrList),
+		data:     b,
+		pt:       lettersSavepoint{lettersPosition: lettersPosition{line: 1}},
+		recover:  true,
+		cur: lettersCurrent{
+			globalStore: make(lettersStoreDict),
+		},
+		maxFailPos:      lettersPosition{col: 1, line: 1},
+		maxFailExpected: make([]string, 0, 20),
+		LettersStats:    &stats,
+		// start rule is rule [0] unless an alternate entrypoint is specified
+		entrypoint: lettersG.rules[0].name,
+	}
+	p.setOptions(opts)
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+
+	return p
+}
+
+// setOptions applies the options to the parser.
+func (p *lettersParser) setOptions(opts []LettersOption) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+// nolint: structcheck,deadcode
+type lettersResultTuple struct {
+	v   interface{}
+	b   bool
+	end lettersSavepoint
+}
+
+// nolint: varcheck
+const lettersChoiceNoMatch = -1
+
+// LettersStats stores some statistics, gathered during parsing
+type LettersStats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+// nolint: structcheck,maligned
+type lettersParser struct {
+	filename string
+	pt       lettersSavepoint
+	cur      lettersCurrent
+
+	data []byte
+	errs *lettersErrList
+
+	depth   int
+	recover bool
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*lettersRule
+	// variables stack, map of label to value
+	vstack []map[string]interface{}
+	// rule stack, allows identification of the current rule in errors
+	rstack []*lettersRule
+
+	// parse fail
+	maxFailPos            lettersPosition
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+
+	*LettersStats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]interface{}
+
+	// emptyState contains an empty storeDict, which is used to optimize cloneState if global "state" store is not used.
+	emptyState lettersStoreDict
+}
+
+// push a variable set on the vstack.
+func (p *lettersParser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]interface{})
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *lettersParser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *lettersParser) pushRecovery(labels []string, expr interface{}) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]interface{}, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *lettersParser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *lettersParser) addErr(err error) {
+	p.addErrAt(err, p.pt.lettersPosition, []string{})
+}
+
+func (p *lettersParser) addErrAt(err error, pos lettersPosition, expected []string) {
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.line, pos.col, pos.offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.displayName != "" {
+			buf.WriteString("rule " + rule.displayName)
+		} else {
+			buf.WriteString("rule " + rule.name)
+		}
+	}
+	pe := &lettersParserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *lettersParser) failAt(fail bool, pos lettersPosition, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.offset < p.maxFailPos.offset {
+			return
+		}
+
+		if pos.offset > p.maxFailPos.offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+	}
+}
+
+// read advances the parser to the next rune.
+func (p *lettersParser) read() {
+	p.pt.offset += p.pt.w
+	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.col++
+	if rn == '\n' {
+		p.pt.line++
+		p.pt.col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(lettersErrInvalidEncoding)
+		}
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *lettersParser) restore(pt lettersSavepoint) {
+	if pt.offset == p.pt.offset {
+		return
+	}
+	p.pt = pt
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *lettersParser) sliceFrom(start lettersSavepoint) []byte {
+	return p.data[start.lettersPosition.offset:p.pt.lettersPosition.offset]
+}
+
+func (p *lettersParser) buildRulesTable(g *lettersGrammar) {
+	p.rules = make(map[string]*lettersRule, len(g.rules))
+	for _, r := range g.rules {
+		p.rules[r.name] = r
+	}
+}
+
+// nolint: gocyclo
+func (p *lettersParser) parse(g *lettersGrammar) (val interface{}, err error) {
+	if len(g.rules) == 0 {
+		p.addErr(lettersErrNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(lettersErrInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	p.read() // advance to first rune
+	val, ok = p.parseRule(startRule)
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+			for _, v := range p.maxFailExpected {
+				maxFailExpectedMap[v] = struct{}{}
+			}
+			expected := make([]string, 0, len(maxFailExpectedMap))
+			eof := false
+			if _, ok := maxFailExpectedMap["!."]; ok {
+				delete(maxFailExpectedMap, "!.")
+				eof = true
+			}
+			for k := range maxFailExpectedMap {
+				expected = append(expected, k)
+			}
+			sort.Strings(expected)
+			if eof {
+				expected = append(expected, "EOF")
+			}
+			p.addErrAt(errors.New("no match found, expected: "+lettersListJoin(expected, ", ", "or")), p.maxFailPos, expected)
+		}
+
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+func lettersListJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return fmt.Sprintf("%s %s %s", strings.Join(list[:len(list)-1], sep), lastSep, list[len(list)-1])
+	}
+}
+
+func (p *lettersParser) parseRule(rule *lettersRule) (interface{}, bool) {
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	val, ok := p.parseExpr(rule.expr)
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	return val, ok
+}
+
+// nolint: gocyclo
+func (p *lettersParser) parseExpr(expr interface{}) (interface{}, bool) {
+
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(lettersErrMaxExprCnt)
+	}
+
+	var val interface{}
+	var ok bool
+	switch expr := expr.(type) {
+	case *lettersActionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *lettersAndCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *lettersAndExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *lettersAnyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *lettersCharClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *lettersChoiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *lettersLabeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *lettersLitMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *lettersNotCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *lettersNotExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *lettersOneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *lettersRecoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *lettersRuleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *lettersSeqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *lettersThrowExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *lettersZeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *lettersZeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	return val, ok
+}
+
+func (p *lettersParser) parseActionExpr(act *lettersActionExpr) (interface{}, bool) {
+	start := p.pt
+	val, ok := p.parseExpr(act.expr)
+	if ok {
+		p.cur.pos = start.lettersPosition
+		p.cur.text = p.sliceFrom(start)
+		actVal, err := act.run(p)
+		if err != nil {
+			p.addErrAt(err, start.lettersPosition, []string{})
+		}
+
+		val = actVal
+	}
+	return val, ok
+}
+
+func (p *lettersParser) parseAndCodeExpr(and *lettersAndCodeExpr) (interface{}, bool) {
+
+	ok, err := and.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+
+	return nil, ok
+}
+
+func (p *lettersParser) parseAndExpr(and *lettersAndExpr) (interface{}, bool) {
+	pt := p.pt
+	p.pushV()
+	_, ok := p.parseExpr(and.expr)
+	p.popV()
+	p.restore(pt)
+
+	return nil, ok
+}
+
+func (p *lettersParser) parseAnyMatcher(any *lettersAnyMatcher) (interface{}, bool) {
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.lettersPosition, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.lettersPosition, ".")
+	return p.sliceFrom(start), true
+}
+
+// nolint: gocyclo
+func (p *lettersParser) parseCharClassMatcher(chr *lettersCharClassMatcher) (interface{}, bool) {
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.lettersPosition, chr.val)
+		return nil, false
+	}
+
+	if chr.ignoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.chars {
+		if rn == cur {
+			if chr.inverted {
+				p.failAt(false, start.lettersPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.lettersPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.ranges); i += 2 {
+		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
+			if chr.inverted {
+				p.failAt(false, start.lettersPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.lettersPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.classes {
+		if unicode.Is(cl, cur) {
+			if chr.inverted {
+				p.failAt(false, start.lettersPosition, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.lettersPosition, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.inverted {
+		p.read()
+		p.failAt(true, start.lettersPosition, chr.val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.lettersPosition, chr.val)
+	return nil, false
+}
+
+func (p *lettersParser) parseChoiceExpr(ch *lettersChoiceExpr) (interface{}, bool) {
+	for altI, alt := range ch.alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		p.pushV()
+		val, ok := p.parseExpr(alt)
+		p.popV()
+		if ok {
+			return val, ok
+		}
+	}
+	return nil, false
+}
+
+func (p *lettersParser) parseLabeledExpr(lab *lettersLabeledExpr) (interface{}, bool) {
+	p.pushV()
+	val, ok := p.parseExpr(lab.expr)
+	p.popV()
+	if ok && lab.label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.label] = val
+	}
+	return val, ok
+}
+
+func (p *lettersParser) parseLitMatcher(lit *lettersLitMatcher) (interface{}, bool) {
+	ignoreCase := ""
+	if lit.ignoreCase {
+		ignoreCase = "i"
+	}
+	val := fmt.Sprintf("%q%s", lit.val, ignoreCase)
+	start := p.pt
+	for _, want := range lit.val {
+		cur := p.pt.rn
+		if lit.ignoreCase {
+			cur = unicode.ToLower(cur)
+		}
+		if cur != want {
+			p.failAt(false, start.lettersPosition, val)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.lettersPosition, val)
+	return p.sliceFrom(start), true
+}
+
+func (p *lettersParser) parseNotCodeExpr(not *lettersNotCodeExpr) (interface{}, bool) {
+	ok, err := not.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+
+	return nil, !ok
+}
+
+func (p *lettersParser) parseNotExpr(not *lettersNotExpr) (interface{}, bool) {
+	pt := p.pt
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExpr(not.expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *lettersParser) parseOneOrMoreExpr(expr *lettersOneOrMoreExpr) (interface{}, bool) {
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *lettersParser) parseRecoveryExpr(recover *lettersRecoveryExpr) (interface{}, bool) {
+
+	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
+	val, ok := p.parseExpr(recover.expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *lettersParser) parseRuleRefExpr(ref *lettersRuleRefExpr) (interface{}, bool) {
+	if ref.name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.pos))
+	}
+
+	rule := p.rules[ref.name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
+		return nil, false
+	}
+	return p.parseRule(rule)
+}
+
+func (p *lettersParser) parseSeqExpr(seq *lettersSeqExpr) (interface{}, bool) {
+	vals := make([]interface{}, 0, len(seq.exprs))
+
+	pt := p.pt
+	for _, expr := range seq.exprs {
+		val, ok := p.parseExpr(expr)
+		if !ok {
+			p.restore(pt)
+			return nil, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+func (p *lettersParser) parseThrowExpr(expr *lettersThrowExpr) (interface{}, bool) {
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
+			if val, ok := p.parseExpr(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *lettersParser) parseZeroOrMoreExpr(expr *lettersZeroOrMoreExpr) (interface{}, bool) {
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *lettersParser) parseZeroOrOneExpr(expr *lettersZeroOrOneExpr) (interface{}, bool) {
+	p.pushV()
+	val, _ := p.parseExpr(expr.expr)
+	p.popV()
+	// whether it matched or not, consider it a match
+	return val, true
+}
+
+func lettersRangeTable(class string) *unicode.RangeTable {
+	if rt, ok := unicode.Categories[class]; ok {
+		return rt
+	}
+	if rt, ok := unicode.Properties[class]; ok {
+		return rt
+	}
+	if rt, ok := unicode.Scripts[class]; ok {
+		return rt
+	}
+
+	// cannot happen
+	panic(fmt.Sprintf("invalid Unicode class: %s", class))
+}
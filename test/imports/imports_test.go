@@ -0,0 +1,50 @@
+package imports
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	cases := []struct {
+		input string
+		want  int
+	}{
+		{"1", 1},
+		{" 1 + 2", 3},
+		{"10+20 + 30\n", 60},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.input))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", c.input, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%q: want %v, got %v", c.input, c.want, got)
+		}
+	}
+
+	if _, err := Parse("", []byte("1 +")); err == nil {
+		t.Errorf("%q: want error, got none", "1 +")
+	}
+}
+
+func TestImportsLineDirectives(t *testing.T) {
+	b, err := ioutil.ReadFile("imports.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the code blocks are mapped to the file that declares them, relative to
+	// the generated file.
+	for _, want := range []string{
+		"//line imports.peg:5:1\n",
+		"//line lexer/number.peg:5:1\n",
+		"//line lexer/number.peg:19:1\n\treturn atoi(c.text)\n",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("want generated code to contain %q", want)
+		}
+	}
+}
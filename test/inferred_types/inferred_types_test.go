@@ -0,0 +1,33 @@
+package inferredtypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferredTypes(t *testing.T) {
+	cases := []struct {
+		input string
+		want  []string
+	}{
+		{"a=x1", []string{"a=x1"}},
+		{"ab=x1;c:y2", []string{"ab=x1", "c:y2"}},
+		{"abc:z0;d=a1;ef:b2", []string{"abc:z0", "d=a1", "ef:b2"}},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.input))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", c.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: want %v, got %v", c.input, c.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "abcd=x1", "a=x", "a=x1;"} {
+		if _, err := Parse("", []byte(input)); err == nil {
+			t.Errorf("%q: want error, got none", input)
+		}
+	}
+}
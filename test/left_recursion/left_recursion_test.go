@@ -0,0 +1,109 @@
+package leftrecursion
+
+import (
+	"testing"
+
+	native "github.com/mna/pigeon/test/left_recursion/native"
+	optimized "github.com/mna/pigeon/test/left_recursion/optimized"
+	sharedruntime "github.com/mna/pigeon/test/left_recursion/runtime"
+	vm "github.com/mna/pigeon/test/left_recursion/vm"
+)
+
+func TestLeftRecursion(t *testing.T) {
+	cases := []struct {
+		input string
+		want  int
+	}{
+		{"1", 1},
+		{"1+2", 3},
+		{"10-2-3", 5},
+		{"2*3+4", 10},
+		{"2+3*4", 14},
+		{"100/10/5", 2},
+		{"2 * (3 - 1) - 1", 3},
+		{"8-(4-2)*3-1", 1},
+	}
+
+	type parser func(s string, memo bool) (interface{}, error)
+	parsers := map[string]parser{
+		"standard": func(s string, memo bool) (interface{}, error) {
+			return Parse("", []byte(s), Memoize(memo))
+		},
+		"optimized": func(s string, _ bool) (interface{}, error) {
+			return optimized.Parse("", []byte(s))
+		},
+		"native": func(s string, memo bool) (interface{}, error) {
+			return native.Parse("", []byte(s), native.Memoize(memo))
+		},
+		"vm": func(s string, memo bool) (interface{}, error) {
+			return vm.Parse("", []byte(s), vm.Memoize(memo))
+		},
+		"shared runtime": func(s string, memo bool) (interface{}, error) {
+			return sharedruntime.Parse("", []byte(s), sharedruntime.Memoize(memo))
+		},
+	}
+	for name, parse := range parsers {
+		for _, memo := range []bool{false, true} {
+			for _, c := range cases {
+				got, err := parse(c.input, memo)
+				if err != nil {
+					t.Errorf("%s: memoize=%t: %q: want no error, got %v", name, memo, c.input, err)
+					continue
+				}
+				if got != c.want {
+					t.Errorf("%s: memoize=%t: %q: want %v, got %v", name, memo, c.input, c.want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestLeftRecursionIndirect(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"a", "a"},
+		{"a()", "a()"},
+		{"a.b", "(a.b)"},
+		{"a.b()", "(a.b)()"},
+		{"a().b.c()", "((a().b).c)()"},
+		{"a()()", ""},
+		{"a.", ""},
+	}
+
+	for _, memo := range []bool{false, true} {
+		for _, c := range cases {
+			got, err := Parse("", []byte(c.input), Entrypoint("Call"), Memoize(memo))
+			if c.want == "" {
+				if err == nil {
+					t.Errorf("memoize=%t: %q: want error, got %v", memo, c.input, got)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("memoize=%t: %q: want no error, got %v", memo, c.input, err)
+				continue
+			}
+			if got != c.want {
+				t.Errorf("memoize=%t: %q: want %q, got %q", memo, c.input, c.want, got)
+			}
+		}
+	}
+
+	got, err := optimized.Parse("", []byte("a().b.c()"), optimized.Entrypoint("Call"))
+	if err != nil {
+		t.Fatalf("optimized: want no error, got %v", err)
+	}
+	if want := "((a().b).c)()"; got != want {
+		t.Errorf("optimized: want %q, got %q", want, got)
+	}
+}
+
+func TestLeftRecursionError(t *testing.T) {
+	for _, input := range []string{"", "1+", "(1", "1+*2"} {
+		if _, err := Parse("", []byte(input)); err == nil {
+			t.Errorf("%q: want error, got none", input)
+		}
+	}
+}
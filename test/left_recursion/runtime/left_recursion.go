@@ -0,0 +1,539 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -alternate-entrypoints=Call -nolint -shared-runtime -support-left-recursion
+
+package leftrecursion
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	pigeonrt "github.com/mna/pigeon/runtime"
+)
+
+var g = &pigeonrt.Grammar{
+	Rules: []*pigeonrt.Rule{
+		{
+			Name: "Start",
+			Pos:  pigeonrt.Position{Line: 7, Col: 1, Offset: 49},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 7, Col: 10, Offset: 58},
+				Run: callonStart1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 7, Col: 10, Offset: 58},
+					Exprs: []interface{}{
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 7, Col: 10, Offset: 58},
+							Label: "e",
+							Expr: &pigeonrt.RuleRefExpr{
+								Pos:  pigeonrt.Position{Line: 7, Col: 12, Offset: 60},
+								Name: "Expr",
+							},
+						},
+						&pigeonrt.NotExpr{
+							Pos: pigeonrt.Position{Line: 7, Col: 17, Offset: 65},
+							Expr: &pigeonrt.AnyMatcher{
+								Line: 7, Col: 18, Offset: 66,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "Expr",
+			Pos:    pigeonrt.Position{Line: 12, Col: 1, Offset: 151},
+			Leader: true,
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 12, Col: 9, Offset: 159},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 12, Col: 9, Offset: 159},
+						Run: callonExpr2,
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 12, Col: 9, Offset: 159},
+							Exprs: []interface{}{
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 12, Col: 9, Offset: 159},
+									Label: "a",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 12, Col: 11, Offset: 161},
+										Name: "Expr",
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 12, Col: 16, Offset: 166},
+									Name: "_",
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 12, Col: 18, Offset: 168},
+									Label: "op",
+									Expr: &pigeonrt.CharClassMatcher{
+										Pos:        pigeonrt.Position{Line: 12, Col: 21, Offset: 171},
+										Val:        "[+-]",
+										Chars:      []rune{'+', '-'},
+										IgnoreCase: false,
+										Inverted:   false,
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 12, Col: 26, Offset: 176},
+									Name: "_",
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 12, Col: 28, Offset: 178},
+									Label: "b",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 12, Col: 30, Offset: 180},
+										Name: "Term",
+									},
+								},
+							},
+						},
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 17, Col: 5, Offset: 290},
+						Name: "Term",
+					},
+				},
+			},
+		},
+		{
+			Name:   "Term",
+			Pos:    pigeonrt.Position{Line: 19, Col: 1, Offset: 296},
+			Leader: true,
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 19, Col: 9, Offset: 304},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 19, Col: 9, Offset: 304},
+						Run: callonTerm2,
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 19, Col: 9, Offset: 304},
+							Exprs: []interface{}{
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 19, Col: 9, Offset: 304},
+									Label: "a",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 19, Col: 11, Offset: 306},
+										Name: "Term",
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 19, Col: 16, Offset: 311},
+									Name: "_",
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 19, Col: 18, Offset: 313},
+									Label: "op",
+									Expr: &pigeonrt.CharClassMatcher{
+										Pos:        pigeonrt.Position{Line: 19, Col: 21, Offset: 316},
+										Val:        "[*/]",
+										Chars:      []rune{'*', '/'},
+										IgnoreCase: false,
+										Inverted:   false,
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 19, Col: 26, Offset: 321},
+									Name: "_",
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 19, Col: 28, Offset: 323},
+									Label: "b",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 19, Col: 30, Offset: 325},
+										Name: "Factor",
+									},
+								},
+							},
+						},
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 24, Col: 5, Offset: 437},
+						Name: "Factor",
+					},
+				},
+			},
+		},
+		{
+			Name: "Factor",
+			Pos:  pigeonrt.Position{Line: 26, Col: 1, Offset: 445},
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 26, Col: 11, Offset: 455},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 26, Col: 11, Offset: 455},
+						Run: callonFactor2,
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 26, Col: 11, Offset: 455},
+							Exprs: []interface{}{
+								&pigeonrt.LitMatcher{
+									Pos:        pigeonrt.Position{Line: 26, Col: 11, Offset: 455},
+									Val:        "(",
+									IgnoreCase: false,
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 26, Col: 15, Offset: 459},
+									Name: "_",
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 26, Col: 17, Offset: 461},
+									Label: "e",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 26, Col: 19, Offset: 463},
+										Name: "Expr",
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 26, Col: 24, Offset: 468},
+									Name: "_",
+								},
+								&pigeonrt.LitMatcher{
+									Pos:        pigeonrt.Position{Line: 26, Col: 26, Offset: 470},
+									Val:        ")",
+									IgnoreCase: false,
+								},
+							},
+						},
+					},
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 28, Col: 5, Offset: 496},
+						Run: callonFactor10,
+						Expr: &pigeonrt.OneOrMoreExpr{
+							Pos: pigeonrt.Position{Line: 28, Col: 5, Offset: 496},
+							Expr: &pigeonrt.CharClassMatcher{
+								Pos:        pigeonrt.Position{Line: 28, Col: 5, Offset: 496},
+								Val:        "[0-9]",
+								Ranges:     []rune{'0', '9'},
+								IgnoreCase: false,
+								Inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "_",
+			Pos:  pigeonrt.Position{Line: 32, Col: 1, Offset: 546},
+			Expr: &pigeonrt.ZeroOrMoreExpr{
+				Pos: pigeonrt.Position{Line: 32, Col: 6, Offset: 551},
+				Expr: &pigeonrt.CharClassMatcher{
+					Pos:        pigeonrt.Position{Line: 32, Col: 6, Offset: 551},
+					Val:        "[ \\t]",
+					Chars:      []rune{' ', '\t'},
+					IgnoreCase: false,
+					Inverted:   false,
+				},
+			},
+		},
+		{
+			Name: "Call",
+			Pos:  pigeonrt.Position{Line: 36, Col: 1, Offset: 671},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 36, Col: 9, Offset: 679},
+				Run: callonCall1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 36, Col: 9, Offset: 679},
+					Exprs: []interface{}{
+						&pigeonrt.LabeledExpr{
+							Pos:   pigeonrt.Position{Line: 36, Col: 9, Offset: 679},
+							Label: "p",
+							Expr: &pigeonrt.RuleRefExpr{
+								Pos:  pigeonrt.Position{Line: 36, Col: 11, Offset: 681},
+								Name: "Postfix",
+							},
+						},
+						&pigeonrt.NotExpr{
+							Pos: pigeonrt.Position{Line: 36, Col: 19, Offset: 689},
+							Expr: &pigeonrt.AnyMatcher{
+								Line: 36, Col: 20, Offset: 690,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "Postfix",
+			Pos:    pigeonrt.Position{Line: 40, Col: 1, Offset: 713},
+			Leader: true,
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 40, Col: 12, Offset: 724},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 40, Col: 12, Offset: 724},
+						Run: callonPostfix2,
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 40, Col: 12, Offset: 724},
+							Exprs: []interface{}{
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 40, Col: 12, Offset: 724},
+									Label: "p",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 40, Col: 14, Offset: 726},
+										Name: "Primary",
+									},
+								},
+								&pigeonrt.LitMatcher{
+									Pos:        pigeonrt.Position{Line: 40, Col: 22, Offset: 734},
+									Val:        "()",
+									IgnoreCase: false,
+								},
+							},
+						},
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 42, Col: 5, Offset: 777},
+						Name: "Primary",
+					},
+				},
+			},
+		},
+		{
+			Name: "Primary",
+			Pos:  pigeonrt.Position{Line: 44, Col: 1, Offset: 786},
+			Expr: &pigeonrt.ChoiceExpr{
+				Pos: pigeonrt.Position{Line: 44, Col: 12, Offset: 797},
+				Alternatives: []interface{}{
+					&pigeonrt.ActionExpr{
+						Pos: pigeonrt.Position{Line: 44, Col: 12, Offset: 797},
+						Run: callonPrimary2,
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 44, Col: 12, Offset: 797},
+							Exprs: []interface{}{
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 44, Col: 12, Offset: 797},
+									Label: "p",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 44, Col: 14, Offset: 799},
+										Name: "Postfix",
+									},
+								},
+								&pigeonrt.LitMatcher{
+									Pos:        pigeonrt.Position{Line: 44, Col: 22, Offset: 807},
+									Val:        ".",
+									IgnoreCase: false,
+								},
+								&pigeonrt.LabeledExpr{
+									Pos:   pigeonrt.Position{Line: 44, Col: 26, Offset: 811},
+									Label: "id",
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 44, Col: 29, Offset: 814},
+										Name: "Ident",
+									},
+								},
+							},
+						},
+					},
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 46, Col: 5, Offset: 883},
+						Name: "Ident",
+					},
+				},
+			},
+		},
+		{
+			Name: "Ident",
+			Pos:  pigeonrt.Position{Line: 48, Col: 1, Offset: 890},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 48, Col: 10, Offset: 899},
+				Run: callonIdent1,
+				Expr: &pigeonrt.OneOrMoreExpr{
+					Pos: pigeonrt.Position{Line: 48, Col: 10, Offset: 899},
+					Expr: &pigeonrt.CharClassMatcher{
+						Pos:        pigeonrt.Position{Line: 48, Col: 10, Offset: 899},
+						Val:        "[a-z]",
+						Ranges:     []rune{'a', 'z'},
+						IgnoreCase: false,
+						Inverted:   false,
+					},
+				},
+			},
+		},
+	},
+}
+
+func (c *current) onStart1(e interface{}) (interface{}, error) {
+	return e, nil
+}
+
+func callonStart1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onStart1(stack["e"])
+}
+
+func (c *current) onExpr2(a, op, b interface{}) (interface{}, error) {
+	if op.([]byte)[0] == '+' {
+		return a.(int) + b.(int), nil
+	}
+	return a.(int) - b.(int), nil
+}
+
+func callonExpr2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onExpr2(stack["a"], stack["op"], stack["b"])
+}
+
+func (c *current) onTerm2(a, op, b interface{}) (interface{}, error) {
+	if op.([]byte)[0] == '*' {
+		return a.(int) * b.(int), nil
+	}
+	return a.(int) / b.(int), nil
+}
+
+func callonTerm2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTerm2(stack["a"], stack["op"], stack["b"])
+}
+
+func (c *current) onFactor2(e interface{}) (interface{}, error) {
+	return e, nil
+}
+
+func callonFactor2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onFactor2(stack["e"])
+}
+
+func (c *current) onFactor10() (interface{}, error) {
+	return strconv.Atoi(string(c.text))
+}
+
+func callonFactor10(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onFactor10()
+}
+
+func (c *current) onCall1(p interface{}) (interface{}, error) {
+	return p, nil
+}
+
+func callonCall1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onCall1(stack["p"])
+}
+
+func (c *current) onPostfix2(p interface{}) (interface{}, error) {
+	return p.(string) + "()", nil
+}
+
+func callonPostfix2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onPostfix2(stack["p"])
+}
+
+func (c *current) onPrimary2(p, id interface{}) (interface{}, error) {
+	return "(" + p.(string) + "." + id.(string) + ")", nil
+}
+
+func callonPrimary2(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onPrimary2(stack["p"], stack["id"])
+}
+
+func (c *current) onIdent1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func callonIdent1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onIdent1()
+}
+
+// the parser fails to compile with an incompatible version of the runtime
+// package.
+const _ = pigeonrt.PackageIsVersion1
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option = pigeonrt.Option
+
+// Stats stores some statistics, gathered during parsing
+type Stats = pigeonrt.Stats
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+type Cloner = pigeonrt.Cloner
+
+// The options of the parser, see the runtime package for details.
+var (
+	AllowInvalidUTF8 = pigeonrt.AllowInvalidUTF8
+	Debug            = pigeonrt.Debug
+	Entrypoint       = pigeonrt.Entrypoint
+	GlobalStore      = pigeonrt.GlobalStore
+	InitState        = pigeonrt.InitState
+	MaxExpressions   = pigeonrt.MaxExpressions
+	Memoize          = pigeonrt.Memoize
+	Recover          = pigeonrt.Recover
+	Statistics       = pigeonrt.Statistics
+)
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseFile(g, filename, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseReader(g, filename, r, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return pigeonrt.Parse(g, filename, b, opts...)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict = pigeonrt.StoreDict
+
+// newCurrent returns the context of the code block run by the parser p. The
+// stores are shared with the parser, so that changes to their content are
+// seen by the parser.
+func newCurrent(p *pigeonrt.Parser) *current {
+	c := p.Current()
+	return &current{
+		pos:         position{line: c.Pos.Line, col: c.Pos.Col, offset: c.Pos.Offset},
+		text:        c.Text,
+		state:       c.State,
+		globalStore: c.GlobalStore,
+	}
+}
@@ -0,0 +1,54 @@
+package linedirectives
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLineDirectives(t *testing.T) {
+	cases := []struct {
+		input string
+		want  interface{}
+	}{
+		{"init", "line_directives.peg:19"},
+		{"pred", "line_directives.peg:34"},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.input))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", c.input, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%q: want %v, got %v", c.input, c.want, got)
+		}
+	}
+
+	// the code after a code block is mapped back to the generated file
+	got, err := Parse("", []byte("action"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls, ok := got.([]string)
+	if !ok || len(calls) != 2 {
+		t.Fatalf("action: want 2 calls, got %#v", got)
+	}
+	if want := "line_directives.peg:30"; calls[0] != want {
+		t.Errorf("action: want %v, got %v", want, calls[0])
+	}
+	const prefix = "line_directives.go:"
+	if !strings.HasPrefix(calls[1], prefix) {
+		t.Fatalf("action: want %v prefix, got %v", prefix, calls[1])
+	}
+	b, err := ioutil.ReadFile("line_directives.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	line, err := strconv.Atoi(strings.TrimPrefix(calls[1], prefix))
+	if err != nil || line < 1 || line > len(lines) || !strings.Contains(lines[line-1], "p.cur.onAction") {
+		t.Errorf("action: want the call of the code block at %v", calls[1])
+	}
+}
@@ -1,6 +1,11 @@
 package maxexprcnt
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+
+	native "github.com/mna/pigeon/test/max_expr_cnt/native"
+)
 
 func TestMaxExprCnt(t *testing.T) {
 	_, err := Parse("", []byte("infinite parse"), MaxExpressions(5))
@@ -30,3 +35,15 @@ func TestMaxExprCnt(t *testing.T) {
 		t.Fatalf("expected to find errMaxExprCnt %v in error list %v", errMaxExprCnt, errs)
 	}
 }
+
+// TestMaxExprCntNative checks that the native functions parser counts the
+// expressions like the standard parser.
+func TestMaxExprCntNative(t *testing.T) {
+	for _, max := range []uint64{1, 2, 5, 20} {
+		_, wantErr := Parse("", []byte("infinite parse"), MaxExpressions(max))
+		_, gotErr := native.Parse("", []byte("infinite parse"), native.MaxExpressions(max))
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%d: want error %v, got %v", max, wantErr, gotErr)
+		}
+	}
+}
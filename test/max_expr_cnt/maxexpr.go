@@ -1,4 +1,5 @@
 // Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -nolint
 
 package maxexprcnt
 
@@ -20,11 +21,24 @@ import (
 var g = &grammar{
 	rules: []*rule{
 		{
-			name: "infinite_rule",
-			pos:  position{line: 6, col: 1, offset: 53},
-			expr: &ruleRefExpr{
-				pos:  position{line: 6, col: 17, offset: 69},
-				name: "infinite_rule",
+			name: "many_exprs_rule",
+			pos:  position{line: 7, col: 1, offset: 168},
+			expr: &seqExpr{
+				pos: position{line: 7, col: 19, offset: 186},
+				exprs: []interface{}{
+					&zeroOrMoreExpr{
+						pos: position{line: 7, col: 19, offset: 186},
+						expr: &anyMatcher{
+							line: 7, col: 19, offset: 186,
+						},
+					},
+					&notExpr{
+						pos: position{line: 7, col: 22, offset: 189},
+						expr: &anyMatcher{
+							line: 7, col: 23, offset: 190,
+						},
+					},
+				},
 			},
 		},
 	},
@@ -1215,6 +1229,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
@@ -1225,6 +1240,14 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 			}
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
@@ -1315,12 +1338,21 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 	var vals []interface{}
 
 	for {
+		start := p.pt.offset
 		p.pushV()
 		val, ok := p.parseExpr(expr.expr)
 		p.popV()
 		if !ok {
 			return vals, true
 		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
 		vals = append(vals, val)
 	}
 }
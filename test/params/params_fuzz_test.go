@@ -0,0 +1,70 @@
+// Code generated by pigeon; DO NOT EDIT.
+
+package params
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fuzzCorpus is the directory of the example inputs that seed the corpus
+// of the fuzz tests.
+const fuzzCorpus = "testdata"
+
+// fuzzMaxExpressions is the maximum number of expressions parsed for an
+// input.
+const fuzzMaxExpressions = 100000
+
+// FuzzParse fuzzes the parser from the first rule of the grammar.
+func FuzzParse(f *testing.F) {
+	fuzzSeed(f)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzParse(t, b)
+	})
+}
+
+// fuzzSeed adds the empty input and the files of fuzzCorpus to the
+// corpus of f.
+func fuzzSeed(f *testing.F) {
+	f.Add([]byte{})
+	fis, err := ioutil.ReadDir(fuzzCorpus)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return
+		}
+		f.Fatal(err)
+	}
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(fuzzCorpus, fi.Name()))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
+
+// fuzzParse parses b with the options opts. The parser does not recover
+// from panics, so that they fail the test with their stack trace, as does
+// the panic raised when more than fuzzMaxExpressions expressions are
+// parsed. The errors must have a position.
+func fuzzParse(t *testing.T, b []byte, opts ...Option) {
+	opts = append(opts, Recover(false), MaxExpressions(fuzzMaxExpressions))
+	_, err := Parse("fuzz", b, opts...)
+	if err == nil {
+		return
+	}
+	list, ok := err.(errList)
+	if !ok {
+		t.Fatalf("%q: want an error of type errList, got %T: %v", b, err, err)
+	}
+	for _, err := range list {
+		if pe, ok := err.(*parserError); !ok || pe.pos.line == 0 {
+			t.Fatalf("%q: error without position: %v", b, err)
+		}
+	}
+}
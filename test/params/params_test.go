@@ -0,0 +1,55 @@
+package params
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	native "github.com/mna/pigeon/test/params/native"
+)
+
+func TestParams(t *testing.T) {
+	cases := []struct {
+		input string
+		want  interface{}
+	}{
+		{"1", []interface{}{[]interface{}{"1"}}},
+		{"[]", []interface{}{[]interface{}{}}},
+		{" [1, 2 ,3] ; 4;[5]\n", []interface{}{
+			[]interface{}{"1", "2", "3"},
+			[]interface{}{"4"},
+			[]interface{}{"5"},
+		}},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.input))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", c.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: want %#v, got %#v", c.input, c.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "[1;2]", "1,2", "[1,]"} {
+		if _, err := Parse("", []byte(input)); err == nil {
+			t.Errorf("%q: want error, got none", input)
+		}
+	}
+}
+
+// TestParamsNative checks that the instances of the parameterized rules
+// parse the same in the native functions parser.
+func TestParamsNative(t *testing.T) {
+	for _, input := range []string{"1", "[]", " [1, 2 ,3] ; 4;[5]\n", "", "[1;2]", "1,2", "[1,]"} {
+		want, wantErr := Parse("", []byte(input))
+		got, gotErr := native.Parse("", []byte(input))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %#v, got %#v", input, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%q: want error %v, got %v", input, wantErr, gotErr)
+		}
+	}
+}
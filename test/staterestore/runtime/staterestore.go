@@ -0,0 +1,425 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -alternate-entrypoints=TestAnd,TestNot -nolint -shared-runtime
+
+package staterestore
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	pigeonrt "github.com/mna/pigeon/runtime"
+)
+
+func main() {
+	in := os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	got, err := ParseReader("", in)
+	fmt.Println(got, err)
+}
+
+var g = &pigeonrt.Grammar{
+	Rules: []*pigeonrt.Rule{
+		{
+			Name: "TestExpr",
+			Pos:  pigeonrt.Position{Line: 19, Col: 1, Offset: 286},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 19, Col: 13, Offset: 298},
+				Run: callonTestExpr1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 19, Col: 13, Offset: 298},
+					Exprs: []interface{}{
+						&pigeonrt.StateCodeExpr{
+							Pos: pigeonrt.Position{Line: 19, Col: 13, Offset: 298},
+							Run: callonTestExpr3,
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 19, Col: 49, Offset: 334},
+							Name: "Expr",
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 19, Col: 54, Offset: 339},
+							Name: "_",
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 19, Col: 56, Offset: 341},
+							Name: "EOF",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "TestAnd",
+			Pos:  pigeonrt.Position{Line: 24, Col: 1, Offset: 385},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 24, Col: 12, Offset: 396},
+				Run: callonTestAnd1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 24, Col: 12, Offset: 396},
+					Exprs: []interface{}{
+						&pigeonrt.StateCodeExpr{
+							Pos: pigeonrt.Position{Line: 24, Col: 12, Offset: 396},
+							Run: callonTestAnd3,
+						},
+						&pigeonrt.AndExpr{
+							Pos: pigeonrt.Position{Line: 24, Col: 48, Offset: 432},
+							Expr: &pigeonrt.RuleRefExpr{
+								Pos:  pigeonrt.Position{Line: 24, Col: 49, Offset: 433},
+								Name: "EOL",
+							},
+						},
+						&pigeonrt.AnyMatcher{
+							Line: 24, Col: 53, Offset: 437,
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 24, Col: 55, Offset: 439},
+							Name: "EOF",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "TestNot",
+			Pos:  pigeonrt.Position{Line: 29, Col: 1, Offset: 483},
+			Expr: &pigeonrt.ActionExpr{
+				Pos: pigeonrt.Position{Line: 29, Col: 12, Offset: 494},
+				Run: callonTestNot1,
+				Expr: &pigeonrt.SeqExpr{
+					Pos: pigeonrt.Position{Line: 29, Col: 12, Offset: 494},
+					Exprs: []interface{}{
+						&pigeonrt.StateCodeExpr{
+							Pos: pigeonrt.Position{Line: 29, Col: 12, Offset: 494},
+							Run: callonTestNot3,
+						},
+						&pigeonrt.ChoiceExpr{
+							Pos: pigeonrt.Position{Line: 29, Col: 50, Offset: 532},
+							Alternatives: []interface{}{
+								&pigeonrt.NotExpr{
+									Pos: pigeonrt.Position{Line: 29, Col: 50, Offset: 532},
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 29, Col: 51, Offset: 533},
+										Name: "EOL",
+									},
+								},
+								&pigeonrt.RuleRefExpr{
+									Pos:  pigeonrt.Position{Line: 29, Col: 57, Offset: 539},
+									Name: "EOL",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Z_",
+			Pos:  pigeonrt.Position{Line: 34, Col: 1, Offset: 585},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 34, Col: 7, Offset: 591},
+				Exprs: []interface{}{
+					&pigeonrt.RuleRefExpr{
+						Pos:  pigeonrt.Position{Line: 34, Col: 7, Offset: 591},
+						Name: "_",
+					},
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 34, Col: 9, Offset: 593},
+						Val:        "Z",
+						IgnoreCase: false,
+					},
+				},
+			},
+		},
+		{
+			Name: "Expr",
+			Pos:  pigeonrt.Position{Line: 35, Col: 1, Offset: 597},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 35, Col: 9, Offset: 605},
+				Exprs: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 35, Col: 9, Offset: 605},
+						Val:        "f",
+						IgnoreCase: false,
+					},
+					&pigeonrt.ZeroOrOneExpr{
+						Pos: pigeonrt.Position{Line: 35, Col: 13, Offset: 609},
+						Expr: &pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 35, Col: 13, Offset: 609},
+							Name: "Z_",
+						},
+					},
+					&pigeonrt.ZeroOrMoreExpr{
+						Pos: pigeonrt.Position{Line: 35, Col: 17, Offset: 613},
+						Expr: &pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 35, Col: 17, Offset: 613},
+							Name: "Z_",
+						},
+					},
+					&pigeonrt.ZeroOrOneExpr{
+						Pos: pigeonrt.Position{Line: 35, Col: 21, Offset: 617},
+						Expr: &pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 35, Col: 21, Offset: 617},
+							Name: "Z_",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "EOL",
+			Pos:  pigeonrt.Position{Line: 36, Col: 1, Offset: 621},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 36, Col: 8, Offset: 628},
+				Exprs: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 36, Col: 8, Offset: 628},
+						Val:        "\n",
+						IgnoreCase: false,
+					},
+					&pigeonrt.StateCodeExpr{
+						Pos: pigeonrt.Position{Line: 37, Col: 3, Offset: 635},
+						Run: callonEOL3,
+					},
+				},
+			},
+		},
+		{
+			Name: "Comment",
+			Pos:  pigeonrt.Position{Line: 44, Col: 1, Offset: 725},
+			Expr: &pigeonrt.SeqExpr{
+				Pos: pigeonrt.Position{Line: 44, Col: 12, Offset: 736},
+				Exprs: []interface{}{
+					&pigeonrt.LitMatcher{
+						Pos:        pigeonrt.Position{Line: 44, Col: 12, Offset: 736},
+						Val:        "#",
+						IgnoreCase: false,
+					},
+					&pigeonrt.ZeroOrMoreExpr{
+						Pos: pigeonrt.Position{Line: 44, Col: 16, Offset: 740},
+						Expr: &pigeonrt.SeqExpr{
+							Pos: pigeonrt.Position{Line: 44, Col: 18, Offset: 742},
+							Exprs: []interface{}{
+								&pigeonrt.NotExpr{
+									Pos: pigeonrt.Position{Line: 44, Col: 18, Offset: 742},
+									Expr: &pigeonrt.RuleRefExpr{
+										Pos:  pigeonrt.Position{Line: 44, Col: 19, Offset: 743},
+										Name: "EOL",
+									},
+								},
+								&pigeonrt.AnyMatcher{
+									Line: 44, Col: 23, Offset: 747,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "_",
+			Pos:  pigeonrt.Position{Line: 45, Col: 1, Offset: 752},
+			Expr: &pigeonrt.ZeroOrMoreExpr{
+				Pos: pigeonrt.Position{Line: 45, Col: 6, Offset: 757},
+				Expr: &pigeonrt.ChoiceExpr{
+					Pos: pigeonrt.Position{Line: 45, Col: 8, Offset: 759},
+					Alternatives: []interface{}{
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 45, Col: 8, Offset: 759},
+							Name: "EOL",
+						},
+						&pigeonrt.RuleRefExpr{
+							Pos:  pigeonrt.Position{Line: 45, Col: 14, Offset: 765},
+							Name: "Comment",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "EOF",
+			Pos:  pigeonrt.Position{Line: 46, Col: 1, Offset: 776},
+			Expr: &pigeonrt.NotExpr{
+				Pos: pigeonrt.Position{Line: 46, Col: 8, Offset: 783},
+				Expr: &pigeonrt.AnyMatcher{
+					Line: 46, Col: 9, Offset: 784,
+				},
+			},
+		},
+	},
+}
+
+func (c *current) onTestExpr3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func callonTestExpr3(p *pigeonrt.Parser) error {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestExpr3()
+}
+
+func (c *current) onTestExpr1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func callonTestExpr1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestExpr1()
+}
+
+func (c *current) onTestAnd3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func callonTestAnd3(p *pigeonrt.Parser) error {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestAnd3()
+}
+
+func (c *current) onTestAnd1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func callonTestAnd1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestAnd1()
+}
+
+func (c *current) onTestNot3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func callonTestNot3(p *pigeonrt.Parser) error {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestNot3()
+}
+
+func (c *current) onTestNot1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func callonTestNot1(p *pigeonrt.Parser) (interface{}, error) {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onTestNot1()
+}
+
+func (c *current) onEOL3() error {
+	cnt := c.state["cnt"].(int)
+	cnt++
+	c.state["cnt"] = cnt
+	return nil
+
+}
+
+func callonEOL3(p *pigeonrt.Parser) error {
+	stack := p.Labels()
+	_ = stack
+	return newCurrent(p).onEOL3()
+}
+
+// the parser fails to compile with an incompatible version of the runtime
+// package.
+const _ = pigeonrt.PackageIsVersion1
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option = pigeonrt.Option
+
+// Stats stores some statistics, gathered during parsing
+type Stats = pigeonrt.Stats
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+type Cloner = pigeonrt.Cloner
+
+// The options of the parser, see the runtime package for details.
+var (
+	AllowInvalidUTF8 = pigeonrt.AllowInvalidUTF8
+	Debug            = pigeonrt.Debug
+	Entrypoint       = pigeonrt.Entrypoint
+	GlobalStore      = pigeonrt.GlobalStore
+	InitState        = pigeonrt.InitState
+	MaxExpressions   = pigeonrt.MaxExpressions
+	Memoize          = pigeonrt.Memoize
+	Recover          = pigeonrt.Recover
+	Statistics       = pigeonrt.Statistics
+)
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseFile(g, filename, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return pigeonrt.ParseReader(g, filename, r, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return pigeonrt.Parse(g, filename, b, opts...)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict = pigeonrt.StoreDict
+
+// newCurrent returns the context of the code block run by the parser p. The
+// stores are shared with the parser, so that changes to their content are
+// seen by the parser.
+func newCurrent(p *pigeonrt.Parser) *current {
+	c := p.Current()
+	return &current{
+		pos:         position{line: c.Pos.Line, col: c.Pos.Col, offset: c.Pos.Offset},
+		text:        c.Text,
+		state:       c.State,
+		globalStore: c.GlobalStore,
+	}
+}
@@ -0,0 +1,2008 @@
+// Code generated by pigeon; DO NOT EDIT.
+// pigeon options: -alternate-entrypoints=TestAnd,TestNot -nolint -vm
+
+package staterestore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func main() {
+	in := os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	got, err := ParseReader("", in)
+	fmt.Println(got, err)
+}
+
+var g = &grammar{
+	rules: []*rule{
+		{
+			name: "TestExpr",
+			pos:  position{line: 19, col: 1, offset: 286},
+			ix:   0,
+		},
+		{
+			name: "TestAnd",
+			pos:  position{line: 24, col: 1, offset: 385},
+			ix:   6,
+		},
+		{
+			name: "TestNot",
+			pos:  position{line: 29, col: 1, offset: 483},
+			ix:   13,
+		},
+		{
+			name: "Z_",
+			pos:  position{line: 34, col: 1, offset: 585},
+			ix:   20,
+		},
+		{
+			name: "Expr",
+			pos:  position{line: 35, col: 1, offset: 597},
+			ix:   23,
+		},
+		{
+			name: "EOL",
+			pos:  position{line: 36, col: 1, offset: 621},
+			ix:   31,
+		},
+		{
+			name: "Comment",
+			pos:  position{line: 44, col: 1, offset: 725},
+			ix:   34,
+		},
+		{
+			name: "_",
+			pos:  position{line: 45, col: 1, offset: 752},
+			ix:   41,
+		},
+		{
+			name: "EOF",
+			pos:  position{line: 46, col: 1, offset: 776},
+			ix:   45,
+		},
+	},
+	prog: []instr{
+		// 0
+		{op: opAction, pos: position{line: 19, col: 13, offset: 298}, args: []int{1}, val: (*parser).callonTestExpr1},
+		// 1
+		{op: opSeq, pos: position{line: 19, col: 13, offset: 298}, args: []int{2, 3, 4, 5}},
+		// 2
+		{op: opStateCode, pos: position{line: 19, col: 13, offset: 298}, val: (*parser).callonTestExpr3},
+		// 3
+		{op: opRuleRef, pos: position{line: 19, col: 49, offset: 334}, val: "Expr"},
+		// 4
+		{op: opRuleRef, pos: position{line: 19, col: 54, offset: 339}, val: "_"},
+		// 5
+		{op: opRuleRef, pos: position{line: 19, col: 56, offset: 341}, val: "EOF"},
+		// 6
+		{op: opAction, pos: position{line: 24, col: 12, offset: 396}, args: []int{7}, val: (*parser).callonTestAnd1},
+		// 7
+		{op: opSeq, pos: position{line: 24, col: 12, offset: 396}, args: []int{8, 9, 11, 12}},
+		// 8
+		{op: opStateCode, pos: position{line: 24, col: 12, offset: 396}, val: (*parser).callonTestAnd3},
+		// 9
+		{op: opAnd, pos: position{line: 24, col: 48, offset: 432}, args: []int{10}},
+		// 10
+		{op: opRuleRef, pos: position{line: 24, col: 49, offset: 433}, val: "EOL"},
+		// 11
+		{op: opAny, pos: position{line: 24, col: 53, offset: 437}, val: &anyMatcher{
+			line: 24, col: 53, offset: 437,
+		}},
+		// 12
+		{op: opRuleRef, pos: position{line: 24, col: 55, offset: 439}, val: "EOF"},
+		// 13
+		{op: opAction, pos: position{line: 29, col: 12, offset: 494}, args: []int{14}, val: (*parser).callonTestNot1},
+		// 14
+		{op: opSeq, pos: position{line: 29, col: 12, offset: 494}, args: []int{15, 16}},
+		// 15
+		{op: opStateCode, pos: position{line: 29, col: 12, offset: 494}, val: (*parser).callonTestNot3},
+		// 16
+		{op: opChoice, pos: position{line: 29, col: 50, offset: 532}, args: []int{17, 19}, val: &choiceExpr{pos: position{line: 29, col: 50, offset: 532}}},
+		// 17
+		{op: opNot, pos: position{line: 29, col: 50, offset: 532}, args: []int{18}},
+		// 18
+		{op: opRuleRef, pos: position{line: 29, col: 51, offset: 533}, val: "EOL"},
+		// 19
+		{op: opRuleRef, pos: position{line: 29, col: 57, offset: 539}, val: "EOL"},
+		// 20
+		{op: opSeq, pos: position{line: 34, col: 7, offset: 591}, args: []int{21, 22}},
+		// 21
+		{op: opRuleRef, pos: position{line: 34, col: 7, offset: 591}, val: "_"},
+		// 22
+		{op: opLit, pos: position{line: 34, col: 9, offset: 593}, val: &litMatcher{
+			pos:        position{line: 34, col: 9, offset: 593},
+			val:        "Z",
+			ignoreCase: false,
+		}},
+		// 23
+		{op: opSeq, pos: position{line: 35, col: 9, offset: 605}, args: []int{24, 25, 27, 29}},
+		// 24
+		{op: opLit, pos: position{line: 35, col: 9, offset: 605}, val: &litMatcher{
+			pos:        position{line: 35, col: 9, offset: 605},
+			val:        "f",
+			ignoreCase: false,
+		}},
+		// 25
+		{op: opZeroOrOne, pos: position{line: 35, col: 13, offset: 609}, args: []int{26}},
+		// 26
+		{op: opRuleRef, pos: position{line: 35, col: 13, offset: 609}, val: "Z_"},
+		// 27
+		{op: opZeroOrMore, pos: position{line: 35, col: 17, offset: 613}, args: []int{28}},
+		// 28
+		{op: opRuleRef, pos: position{line: 35, col: 17, offset: 613}, val: "Z_"},
+		// 29
+		{op: opZeroOrOne, pos: position{line: 35, col: 21, offset: 617}, args: []int{30}},
+		// 30
+		{op: opRuleRef, pos: position{line: 35, col: 21, offset: 617}, val: "Z_"},
+		// 31
+		{op: opSeq, pos: position{line: 36, col: 8, offset: 628}, args: []int{32, 33}},
+		// 32
+		{op: opLit, pos: position{line: 36, col: 8, offset: 628}, val: &litMatcher{
+			pos:        position{line: 36, col: 8, offset: 628},
+			val:        "\n",
+			ignoreCase: false,
+		}},
+		// 33
+		{op: opStateCode, pos: position{line: 37, col: 3, offset: 635}, val: (*parser).callonEOL3},
+		// 34
+		{op: opSeq, pos: position{line: 44, col: 12, offset: 736}, args: []int{35, 36}},
+		// 35
+		{op: opLit, pos: position{line: 44, col: 12, offset: 736}, val: &litMatcher{
+			pos:        position{line: 44, col: 12, offset: 736},
+			val:        "#",
+			ignoreCase: false,
+		}},
+		// 36
+		{op: opZeroOrMore, pos: position{line: 44, col: 16, offset: 740}, args: []int{37}},
+		// 37
+		{op: opSeq, pos: position{line: 44, col: 18, offset: 742}, args: []int{38, 40}},
+		// 38
+		{op: opNot, pos: position{line: 44, col: 18, offset: 742}, args: []int{39}},
+		// 39
+		{op: opRuleRef, pos: position{line: 44, col: 19, offset: 743}, val: "EOL"},
+		// 40
+		{op: opAny, pos: position{line: 44, col: 23, offset: 747}, val: &anyMatcher{
+			line: 44, col: 23, offset: 747,
+		}},
+		// 41
+		{op: opZeroOrMore, pos: position{line: 45, col: 6, offset: 757}, args: []int{42}},
+		// 42
+		{op: opChoice, pos: position{line: 45, col: 8, offset: 759}, args: []int{43, 44}, val: &choiceExpr{pos: position{line: 45, col: 8, offset: 759}}},
+		// 43
+		{op: opRuleRef, pos: position{line: 45, col: 8, offset: 759}, val: "EOL"},
+		// 44
+		{op: opRuleRef, pos: position{line: 45, col: 14, offset: 765}, val: "Comment"},
+		// 45
+		{op: opNot, pos: position{line: 46, col: 8, offset: 783}, args: []int{46}},
+		// 46
+		{op: opAny, pos: position{line: 46, col: 9, offset: 784}, val: &anyMatcher{
+			line: 46, col: 9, offset: 784,
+		}},
+	},
+}
+
+func (c *current) onTestExpr3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func (p *parser) callonTestExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestExpr3()
+}
+
+func (c *current) onTestExpr1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func (p *parser) callonTestExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestExpr1()
+}
+
+func (c *current) onTestAnd3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func (p *parser) callonTestAnd3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestAnd3()
+}
+
+func (c *current) onTestAnd1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func (p *parser) callonTestAnd1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestAnd1()
+}
+
+func (c *current) onTestNot3() error {
+	c.state["cnt"] = 0
+	return nil
+}
+
+func (p *parser) callonTestNot3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestNot3()
+}
+
+func (c *current) onTestNot1() (interface{}, error) {
+	return c.state["cnt"], nil
+
+}
+
+func (p *parser) callonTestNot1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTestNot1()
+}
+
+func (c *current) onEOL3() error {
+	cnt := c.state["cnt"].(int)
+	cnt++
+	c.state["cnt"] = cnt
+	return nil
+
+}
+
+func (p *parser) callonEOL3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOL3()
+}
+
+var (
+	// errNoRule is returned when the grammar to parse has no rule.
+	errNoRule = errors.New("grammar has no rule")
+
+	// errInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	errInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// errInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	errInvalidEncoding = errors.New("invalid encoding")
+
+	// errMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	errMaxExprCnt = errors.New("max number of expresssions parsed")
+)
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option func(*parser) Option
+
+// MaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func MaxExpressions(maxExprCnt uint64) Option {
+	return func(p *parser) Option {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return MaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// Entrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func Entrypoint(ruleName string) Option {
+	return func(p *parser) Option {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		if ruleName == "" {
+			p.entrypoint = g.rules[0].name
+		}
+		return Entrypoint(oldEntrypoint)
+	}
+}
+
+// Statistics adds a user provided Stats struct to the parser to allow
+// the user to process the results after the parsing has finished.
+// Also the key for the "no match" counter is set.
+//
+// Example usage:
+//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
+func Statistics(stats *Stats, choiceNoMatch string) Option {
+	return func(p *parser) Option {
+		oldStats := p.Stats
+		p.Stats = stats
+		oldChoiceNoMatch := p.choiceNoMatch
+		p.choiceNoMatch = choiceNoMatch
+		if p.Stats.ChoiceAltCnt == nil {
+			p.Stats.ChoiceAltCnt = make(map[string]map[string]int)
+		}
+		return Statistics(oldStats, oldChoiceNoMatch)
+	}
+}
+
+// Debug creates an Option to set the debug flag to b. When set to true,
+// debugging information is printed to stdout while parsing.
+//
+// The default is false.
+func Debug(b bool) Option {
+	return func(p *parser) Option {
+		old := p.debug
+		p.debug = b
+		return Debug(old)
+	}
+}
+
+// Memoize creates an Option to set the memoize flag to b. When set to true,
+// the parser will cache all results so each expression is evaluated only
+// once. This guarantees linear parsing time even for pathological cases,
+// at the expense of more memory and slower times for typical cases.
+//
+// The default is false.
+func Memoize(b bool) Option {
+	return func(p *parser) Option {
+		old := p.memoize
+		p.memoize = b
+		return Memoize(old)
+	}
+}
+
+// AllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func AllowInvalidUTF8(b bool) Option {
+	return func(p *parser) Option {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return AllowInvalidUTF8(old)
+	}
+}
+
+// Recover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func Recover(b bool) Option {
+	return func(p *parser) Option {
+		old := p.recover
+		p.recover = b
+		return Recover(old)
+	}
+}
+
+// GlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func GlobalStore(key string, value interface{}) Option {
+	return func(p *parser) Option {
+		old := p.cur.globalStore[key]
+		p.cur.globalStore[key] = value
+		return GlobalStore(key, old)
+	}
+}
+
+// InitState creates an Option to set a key to a certain value in
+// the global "state" store.
+func InitState(key string, value interface{}) Option {
+	return func(p *parser) Option {
+		old := p.cur.state[key]
+		p.cur.state[key] = value
+		return InitState(key, old)
+	}
+}
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (i interface{}, err error) { // nolint: deadcode
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return ParseReader(filename, f, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) { // nolint: deadcode
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(filename, b, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return newParser(filename, b, opts...).parse(g)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d [%d]", p.line, p.col, p.offset)
+}
+
+// savepoint stores all state required to go back to this point in the
+// parser.
+type savepoint struct {
+	position
+	rn rune
+	w  int
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict map[string]interface{}
+
+// the AST types...
+
+// nolint: structcheck
+type grammar struct {
+	pos   position
+	rules []*rule
+	prog  []instr
+}
+
+// nolint: structcheck
+type rule struct {
+	pos         position
+	name        string
+	displayName string
+	expr        interface{}
+	ix          int
+}
+
+// nolint: structcheck
+type choiceExpr struct {
+	pos          position
+	alternatives []interface{}
+}
+
+// nolint: structcheck
+type actionExpr struct {
+	pos  position
+	expr interface{}
+	run  func(*parser) (interface{}, error)
+}
+
+// nolint: structcheck
+type recoveryExpr struct {
+	pos          position
+	expr         interface{}
+	recoverExpr  interface{}
+	failureLabel []string
+}
+
+// nolint: structcheck
+type seqExpr struct {
+	pos   position
+	exprs []interface{}
+}
+
+// nolint: structcheck
+type throwExpr struct {
+	pos   position
+	label string
+}
+
+// nolint: structcheck
+type labeledExpr struct {
+	pos   position
+	label string
+	expr  interface{}
+}
+
+// nolint: structcheck
+type expr struct {
+	pos  position
+	expr interface{}
+}
+
+type andExpr expr        // nolint: structcheck
+type notExpr expr        // nolint: structcheck
+type zeroOrOneExpr expr  // nolint: structcheck
+type zeroOrMoreExpr expr // nolint: structcheck
+type oneOrMoreExpr expr  // nolint: structcheck
+
+// nolint: structcheck
+type ruleRefExpr struct {
+	pos  position
+	name string
+}
+
+// nolint: structcheck
+type stateCodeExpr struct {
+	pos position
+	run func(*parser) error
+}
+
+// nolint: structcheck
+type andCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+// nolint: structcheck
+type notCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+// nolint: structcheck
+type litMatcher struct {
+	pos        position
+	val        string
+	ignoreCase bool
+}
+
+// nolint: structcheck
+type charClassMatcher struct {
+	pos             position
+	val             string
+	basicLatinChars [128]bool
+	chars           []rune
+	ranges          []rune
+	classes         []*unicode.RangeTable
+	ignoreCase      bool
+	inverted        bool
+}
+
+type anyMatcher position // nolint: structcheck
+
+// errList cumulates the errors found by the parser.
+type errList []error
+
+func (e *errList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e errList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *errList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+func (e errList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// parserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type parserError struct {
+	Inner    error
+	pos      position
+	prefix   string
+	expected []string
+}
+
+// Error returns the error message.
+func (p *parserError) Error() string {
+	return p.prefix + ": " + p.Inner.Error()
+}
+
+// newParser creates a parser with the specified input source and options.
+func newParser(filename string, b []byte, opts ...Option) *parser {
+	stats := Stats{
+		ChoiceAltCnt: make(map[string]map[string]int),
+	}
+
+	p := &parser{
+		filename: filename,
+		errs:     new(errList),
+		data:     b,
+		pt:       savepoint{position: position{line: 1}},
+		recover:  true,
+		cur: current{
+			state:       make(storeDict),
+			globalStore: make(storeDict),
+		},
+		maxFailPos:      position{col: 1, line: 1},
+		maxFailExpected: make([]string, 0, 20),
+		Stats:           &stats,
+		// start rule is rule [0] unless an alternate entrypoint is specified
+		entrypoint: g.rules[0].name,
+		emptyState: make(storeDict),
+	}
+	p.setOptions(opts)
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+
+	return p
+}
+
+// setOptions applies the options to the parser.
+func (p *parser) setOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+// nolint: structcheck,deadcode
+type resultTuple struct {
+	v   interface{}
+	b   bool
+	end savepoint
+}
+
+// nolint: varcheck
+const choiceNoMatch = -1
+
+// Stats stores some statistics, gathered during parsing
+type Stats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+// nolint: structcheck,maligned
+type parser struct {
+	filename string
+	pt       savepoint
+	cur      current
+
+	data []byte
+	errs *errList
+
+	depth   int
+	recover bool
+	debug   bool
+
+	memoize bool
+	// memoization table for the packrat algorithm:
+	// map[offset in source] map[expression or rule] {value, match}
+	memo map[int]map[interface{}]resultTuple
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*rule
+	// variables stack, map of label to value
+	vstack []map[string]interface{}
+	// rule stack, allows identification of the current rule in errors
+	rstack []*rule
+
+	// parse fail
+	maxFailPos            position
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+
+	*Stats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]interface{}
+
+	// emptyState contains an empty storeDict, which is used to optimize cloneState if global "state" store is not used.
+	emptyState storeDict
+}
+
+// push a variable set on the vstack.
+func (p *parser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]interface{})
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *parser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *parser) pushRecovery(labels []string, expr interface{}) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]interface{}, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *parser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *parser) print(prefix, s string) string {
+	if !p.debug {
+		return s
+	}
+
+	fmt.Printf("%s %d:%d:%d: %s [%#U]\n",
+		prefix, p.pt.line, p.pt.col, p.pt.offset, s, p.pt.rn)
+	return s
+}
+
+func (p *parser) in(s string) string {
+	p.depth++
+	return p.print(strings.Repeat(" ", p.depth)+">", s)
+}
+
+func (p *parser) out(s string) string {
+	p.depth--
+	return p.print(strings.Repeat(" ", p.depth)+"<", s)
+}
+
+func (p *parser) addErr(err error) {
+	p.addErrAt(err, p.pt.position, []string{})
+}
+
+func (p *parser) addErrAt(err error, pos position, expected []string) {
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.line, pos.col, pos.offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.displayName != "" {
+			buf.WriteString("rule " + rule.displayName)
+		} else {
+			buf.WriteString("rule " + rule.name)
+		}
+	}
+	pe := &parserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *parser) failAt(fail bool, pos position, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.offset < p.maxFailPos.offset {
+			return
+		}
+
+		if pos.offset > p.maxFailPos.offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+	}
+}
+
+// read advances the parser to the next rune.
+func (p *parser) read() {
+	p.pt.offset += p.pt.w
+	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.col++
+	if rn == '\n' {
+		p.pt.line++
+		p.pt.col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(errInvalidEncoding)
+		}
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *parser) restore(pt savepoint) {
+	if p.debug {
+		defer p.out(p.in("restore"))
+	}
+	if pt.offset == p.pt.offset {
+		return
+	}
+	p.pt = pt
+}
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+//
+// This is used in conjunction with the global state feature to create proper
+// copies of the state to allow the parser to properly restore the state in
+// the case of backtracking.
+type Cloner interface {
+	Clone() interface{}
+}
+
+// clone and return parser current state.
+func (p *parser) cloneState() storeDict {
+	if p.debug {
+		defer p.out(p.in("cloneState"))
+	}
+
+	if len(p.cur.state) == 0 {
+		if len(p.emptyState) > 0 {
+			p.emptyState = make(storeDict)
+		}
+		return p.emptyState
+	}
+
+	state := make(storeDict, len(p.cur.state))
+	for k, v := range p.cur.state {
+		if c, ok := v.(Cloner); ok {
+			state[k] = c.Clone()
+		} else {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+// restore parser current state to the state storeDict.
+// every restoreState should applied only one time for every cloned state
+func (p *parser) restoreState(state storeDict) {
+	if p.debug {
+		defer p.out(p.in("restoreState"))
+	}
+	p.cur.state = state
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *parser) sliceFrom(start savepoint) []byte {
+	return p.data[start.position.offset:p.pt.position.offset]
+}
+
+func (p *parser) getMemoized(node interface{}) (resultTuple, bool) {
+	if len(p.memo) == 0 {
+		return resultTuple{}, false
+	}
+	m := p.memo[p.pt.offset]
+	if len(m) == 0 {
+		return resultTuple{}, false
+	}
+	res, ok := m[node]
+	return res, ok
+}
+
+func (p *parser) setMemoized(pt savepoint, node interface{}, tuple resultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[interface{}]resultTuple)
+	}
+	m := p.memo[pt.offset]
+	if m == nil {
+		m = make(map[interface{}]resultTuple)
+		p.memo[pt.offset] = m
+	}
+	m[node] = tuple
+}
+
+func (p *parser) buildRulesTable(g *grammar) {
+	p.rules = make(map[string]*rule, len(g.rules))
+	for _, r := range g.rules {
+		p.rules[r.name] = r
+	}
+}
+
+// nolint: gocyclo
+func (p *parser) parse(g *grammar) (val interface{}, err error) {
+	if len(g.rules) == 0 {
+		p.addErr(errNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				if p.debug {
+					defer p.out(p.in("panic handler"))
+				}
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(errInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	p.read() // advance to first rune
+	val, ok = p.runVM(g, startRule)
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+			for _, v := range p.maxFailExpected {
+				maxFailExpectedMap[v] = struct{}{}
+			}
+			expected := make([]string, 0, len(maxFailExpectedMap))
+			eof := false
+			if _, ok := maxFailExpectedMap["!."]; ok {
+				delete(maxFailExpectedMap, "!.")
+				eof = true
+			}
+			for k := range maxFailExpectedMap {
+				expected = append(expected, k)
+			}
+			sort.Strings(expected)
+			if eof {
+				expected = append(expected, "EOF")
+			}
+			p.addErrAt(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.maxFailPos, expected)
+		}
+
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+func listJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return fmt.Sprintf("%s %s %s", strings.Join(list[:len(list)-1], sep), lastSep, list[len(list)-1])
+	}
+}
+
+func (p *parser) parseRule(rule *rule) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRule " + rule.name))
+	}
+
+	if p.memoize {
+		res, ok := p.getMemoized(rule)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+	}
+
+	start := p.pt
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	val, ok := p.parseExpr(rule.expr)
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+
+	if p.memoize {
+		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+// opcode is the operation of an instruction of the program run by the
+// virtual machine.
+type opcode uint8
+
+// the opcodes, one per kind of expression.
+const (
+	opAction opcode = iota
+	opAndCode
+	opAnd
+	opAny
+	opCharClass
+	opChoice
+	opLabeled
+	opLit
+	opNotCode
+	opNot
+	opOneOrMore
+	opRecovery
+	opRuleRef
+	opSeq
+	opStateCode
+	opThrow
+	opZeroOrMore
+	opZeroOrOne
+)
+
+var opNames = [...]string{
+	opAction:     "parseActionExpr",
+	opAndCode:    "parseAndCodeExpr",
+	opAnd:        "parseAndExpr",
+	opChoice:     "parseChoiceExpr",
+	opLabeled:    "parseLabeledExpr",
+	opNotCode:    "parseNotCodeExpr",
+	opNot:        "parseNotExpr",
+	opOneOrMore:  "parseOneOrMoreExpr",
+	opRecovery:   "parseRecoveryExpr",
+	opRuleRef:    "parseRuleRefExpr",
+	opSeq:        "parseSeqExpr",
+	opStateCode:  "parseStateCodeExpr",
+	opThrow:      "parseThrowExpr",
+	opZeroOrMore: "parseZeroOrMoreExpr",
+	opZeroOrOne:  "parseZeroOrOneExpr",
+}
+
+// instr is an instruction of the program run by the virtual machine. Each
+// instruction parses an expression of the grammar, args are the indices of
+// the instructions of its sub-expressions and val is its operand: the
+// matcher of a terminal, the function of a code block, the label(s) or the
+// name of the referenced rule.
+// nolint: structcheck
+type instr struct {
+	op   opcode
+	pos  position
+	args []int
+	val  interface{}
+}
+
+// vmFrame is the state of an instruction or of a rule being run by the
+// virtual machine.
+// nolint: structcheck
+type vmFrame struct {
+	ix     int
+	rule   *rule
+	step   int
+	n      int
+	pt     savepoint
+	state  storeDict
+	vals   []interface{}
+	memoPt savepoint
+	dbg    string
+}
+
+// vm runs the program of the grammar with an explicit stack of frames
+// instead of recursive calls, so that deeply nested input does not exhaust
+// the goroutine stack. The result of the last frame that returned is in
+// val and ok.
+type vm struct {
+	prog  []instr
+	stack []vmFrame
+	val   interface{}
+	ok    bool
+}
+
+func (m *vm) callExpr(ix int) {
+	m.stack = append(m.stack, vmFrame{ix: ix})
+}
+
+func (m *vm) callRule(rule *rule) {
+	m.stack = append(m.stack, vmFrame{rule: rule})
+}
+
+// runVM parses the rule with the virtual machine.
+func (p *parser) runVM(g *grammar, rule *rule) (interface{}, bool) {
+	m := &vm{prog: g.prog}
+	m.callRule(rule)
+	for len(m.stack) > 0 {
+		f := &m.stack[len(m.stack)-1]
+		if f.rule != nil {
+			p.stepRule(m, f)
+			continue
+		}
+		p.stepExpr(m, f)
+	}
+	return m.val, m.ok
+}
+
+// vmReturn pops the frame at the top of the stack, with the result val
+// and ok.
+func (p *parser) vmReturn(m *vm, val interface{}, ok bool) {
+	if f := &m.stack[len(m.stack)-1]; f.dbg != "" {
+		p.out(f.dbg)
+	}
+	m.stack[len(m.stack)-1] = vmFrame{}
+	m.stack = m.stack[:len(m.stack)-1]
+	m.val, m.ok = val, ok
+}
+
+// stepRule runs the frame f of a rule until it returns or calls the
+// instruction of the rule's expression, the same way as parseRule.
+func (p *parser) stepRule(m *vm, f *vmFrame) {
+	rule := f.rule
+	if f.step == 0 {
+		if p.debug {
+			f.dbg = p.in("parseRule " + rule.name)
+		}
+
+		if p.memoize {
+			res, ok := p.getMemoized(rule)
+			if ok {
+				p.restore(res.end)
+				p.vmReturn(m, res.v, res.b)
+				return
+			}
+		}
+
+		f.pt = p.pt
+		p.rstack = append(p.rstack, rule)
+		p.pushV()
+		f.step = 1
+		m.callExpr(rule.ix)
+		return
+	}
+
+	val, ok := m.val, m.ok
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(f.pt)))
+	}
+
+	if p.memoize {
+		p.setMemoized(f.pt, rule, resultTuple{val, ok, p.pt})
+	}
+	p.vmReturn(m, val, ok)
+}
+
+// stepExpr runs the frame f of an instruction until it returns or calls
+// another instruction or rule. Each step mirrors the corresponding parse
+// method of the expression, the result of the called frame being in m.val
+// and m.ok when the frame is resumed.
+// nolint: gocyclo
+func (p *parser) stepExpr(m *vm, f *vmFrame) {
+	in := &m.prog[f.ix]
+	if f.step == 0 {
+		if p.memoize {
+			res, ok := p.getMemoized(in)
+			if ok {
+				p.restore(res.end)
+				p.vmReturn(m, res.v, res.b)
+				return
+			}
+			f.memoPt = p.pt
+		}
+
+		p.ExprCnt++
+		if p.ExprCnt > p.maxExprCnt {
+			panic(errMaxExprCnt)
+		}
+		if p.debug && opNames[in.op] != "" {
+			name := opNames[in.op]
+			if in.op == opRuleRef {
+				name += " " + in.val.(string)
+			}
+			f.dbg = p.in(name)
+		}
+	}
+
+	var val interface{}
+	var ok bool
+	step := f.step
+	f.step++
+
+	switch in.op {
+	case opAction:
+		if step == 0 {
+			f.pt = p.pt
+			m.callExpr(in.args[0])
+			return
+		}
+		val, ok = m.val, m.ok
+		if ok {
+			p.cur.pos = f.pt.position
+			p.cur.text = p.sliceFrom(f.pt)
+			state := p.cloneState()
+			actVal, err := in.val.(func(*parser) (interface{}, error))(p)
+			if err != nil {
+				p.addErrAt(err, f.pt.position, []string{})
+			}
+			p.restoreState(state)
+
+			val = actVal
+		}
+		if ok && p.debug {
+			p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(f.pt)))
+		}
+
+	case opAndCode, opNotCode:
+		state := p.cloneState()
+		var err error
+		ok, err = in.val.(func(*parser) (bool, error))(p)
+		if err != nil {
+			p.addErr(err)
+		}
+		p.restoreState(state)
+		if in.op == opNotCode {
+			ok = !ok
+		}
+
+	case opAnd, opNot:
+		if step == 0 {
+			f.pt = p.pt
+			f.state = p.cloneState()
+			p.pushV()
+			if in.op == opNot {
+				p.maxFailInvertExpected = !p.maxFailInvertExpected
+			}
+			m.callExpr(in.args[0])
+			return
+		}
+		ok = m.ok
+		if in.op == opNot {
+			p.maxFailInvertExpected = !p.maxFailInvertExpected
+			ok = !ok
+		}
+		p.popV()
+		p.restoreState(f.state)
+		p.restore(f.pt)
+
+	case opAny:
+		val, ok = p.parseAnyMatcher(in.val.(*anyMatcher))
+
+	case opCharClass:
+		val, ok = p.parseCharClassMatcher(in.val.(*charClassMatcher))
+
+	case opChoice:
+		if step > 0 {
+			p.popV()
+			if m.ok {
+				p.incChoiceAltCnt(in.val.(*choiceExpr), step-1)
+				val, ok = m.val, m.ok
+				break
+			}
+			p.restoreState(f.state)
+		}
+		if step < len(in.args) {
+			f.state = p.cloneState()
+			p.pushV()
+			m.callExpr(in.args[step])
+			return
+		}
+		p.incChoiceAltCnt(in.val.(*choiceExpr), choiceNoMatch)
+
+	case opLabeled:
+		if step == 0 {
+			p.pushV()
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popV()
+		val, ok = m.val, m.ok
+		if label := in.val.(string); ok && label != "" {
+			p.vstack[len(p.vstack)-1][label] = val
+		}
+
+	case opLit:
+		val, ok = p.parseLitMatcher(in.val.(*litMatcher))
+
+	case opOneOrMore, opZeroOrMore:
+		if step > 0 {
+			p.popV()
+			if !m.ok {
+				if len(f.vals) == 0 && in.op == opOneOrMore {
+					// did not match once, no match
+					break
+				}
+				val, ok = f.vals, true
+				break
+			}
+			if p.pt.offset == f.n {
+				// matched without consuming any input, repeating would never
+				// end. Keep the value only if it is the first match.
+				if len(f.vals) == 0 {
+					f.vals = append(f.vals, m.val)
+				}
+				val, ok = f.vals, true
+				break
+			}
+			f.vals = append(f.vals, m.val)
+		}
+		f.n = p.pt.offset
+		p.pushV()
+		m.callExpr(in.args[0])
+		return
+
+	case opRecovery:
+		if step == 0 {
+			p.pushRecovery(in.val.([]string), in.args[1])
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popRecovery()
+		val, ok = m.val, m.ok
+
+	case opRuleRef:
+		if step == 0 {
+			name := in.val.(string)
+			if name == "" {
+				panic(fmt.Sprintf("%s: invalid rule: missing name", in.pos))
+			}
+
+			rule := p.rules[name]
+			if rule == nil {
+				p.addErr(fmt.Errorf("undefined rule: %s", name))
+				break
+			}
+			m.callRule(rule)
+			return
+		}
+		val, ok = m.val, m.ok
+
+	case opSeq:
+		if step == 0 {
+			f.vals = make([]interface{}, 0, len(in.args))
+			f.pt = p.pt
+			f.state = p.cloneState()
+		} else {
+			if !m.ok {
+				p.restoreState(f.state)
+				p.restore(f.pt)
+				break
+			}
+			f.vals = append(f.vals, m.val)
+		}
+		if step < len(in.args) {
+			m.callExpr(in.args[step])
+			return
+		}
+		val, ok = f.vals, true
+
+	case opStateCode:
+		err := in.val.(func(*parser) error)(p)
+		if err != nil {
+			p.addErr(err)
+		}
+		ok = true
+
+	case opThrow:
+		if step == 0 {
+			f.n = len(p.recoveryStack)
+		} else if m.ok {
+			val, ok = m.val, m.ok
+			break
+		}
+		label := in.val.(string)
+		for f.n--; f.n >= 0; f.n-- {
+			if recoverExpr, found := p.recoveryStack[f.n][label]; found {
+				m.callExpr(recoverExpr.(int))
+				return
+			}
+		}
+
+	case opZeroOrOne:
+		if step == 0 {
+			p.pushV()
+			m.callExpr(in.args[0])
+			return
+		}
+		p.popV()
+		val, ok = m.val, true
+
+	default:
+		panic(fmt.Sprintf("unknown opcode %d", in.op))
+	}
+
+	if p.memoize {
+		p.setMemoized(f.memoPt, in, resultTuple{val, ok, p.pt})
+	}
+	p.vmReturn(m, val, ok)
+}
+
+// nolint: gocyclo
+func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
+	var pt savepoint
+
+	if p.memoize {
+		res, ok := p.getMemoized(expr)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+		pt = p.pt
+	}
+
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(errMaxExprCnt)
+	}
+
+	var val interface{}
+	var ok bool
+	switch expr := expr.(type) {
+	case *actionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *andCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *andExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *anyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *charClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *choiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *labeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *litMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *notCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *notExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *oneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *recoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *ruleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *seqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *stateCodeExpr:
+		val, ok = p.parseStateCodeExpr(expr)
+	case *throwExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *zeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *zeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	if p.memoize {
+		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseActionExpr"))
+	}
+
+	start := p.pt
+	val, ok := p.parseExpr(act.expr)
+	if ok {
+		p.cur.pos = start.position
+		p.cur.text = p.sliceFrom(start)
+		state := p.cloneState()
+		actVal, err := act.run(p)
+		if err != nil {
+			p.addErrAt(err, start.position, []string{})
+		}
+		p.restoreState(state)
+
+		val = actVal
+	}
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	return val, ok
+}
+
+func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := and.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, ok
+}
+
+func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	_, ok := p.parseExpr(and.expr)
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, ok
+}
+
+func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAnyMatcher"))
+	}
+
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.position, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.position, ".")
+	return p.sliceFrom(start), true
+}
+
+// nolint: gocyclo
+func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseCharClassMatcher"))
+	}
+
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.position, chr.val)
+		return nil, false
+	}
+
+	if chr.ignoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.chars {
+		if rn == cur {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.ranges); i += 2 {
+		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.classes {
+		if unicode.Is(cl, cur) {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.inverted {
+		p.read()
+		p.failAt(true, start.position, chr.val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.position, chr.val)
+	return nil, false
+}
+
+func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
+	choiceIdent := fmt.Sprintf("%s %d:%d", p.rstack[len(p.rstack)-1].name, ch.pos.line, ch.pos.col)
+	m := p.ChoiceAltCnt[choiceIdent]
+	if m == nil {
+		m = make(map[string]int)
+		p.ChoiceAltCnt[choiceIdent] = m
+	}
+	// We increment altI by 1, so the keys do not start at 0
+	alt := strconv.Itoa(altI + 1)
+	if altI == choiceNoMatch {
+		alt = p.choiceNoMatch
+	}
+	m[alt]++
+}
+
+func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseChoiceExpr"))
+	}
+
+	for altI, alt := range ch.alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		state := p.cloneState()
+
+		p.pushV()
+		val, ok := p.parseExpr(alt)
+		p.popV()
+		if ok {
+			p.incChoiceAltCnt(ch, altI)
+			return val, ok
+		}
+		p.restoreState(state)
+	}
+	p.incChoiceAltCnt(ch, choiceNoMatch)
+	return nil, false
+}
+
+func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLabeledExpr"))
+	}
+
+	p.pushV()
+	val, ok := p.parseExpr(lab.expr)
+	p.popV()
+	if ok && lab.label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.label] = val
+	}
+	return val, ok
+}
+
+func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLitMatcher"))
+	}
+
+	ignoreCase := ""
+	if lit.ignoreCase {
+		ignoreCase = "i"
+	}
+	val := fmt.Sprintf("%q%s", lit.val, ignoreCase)
+	start := p.pt
+	for _, want := range lit.val {
+		cur := p.pt.rn
+		if lit.ignoreCase {
+			cur = unicode.ToLower(cur)
+		}
+		if cur != want {
+			p.failAt(false, start.position, val)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.position, val)
+	return p.sliceFrom(start), true
+}
+
+func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := not.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, !ok
+}
+
+func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExpr(not.expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseOneOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
+	}
+
+	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
+	val, ok := p.parseExpr(recover.expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRefExpr " + ref.name))
+	}
+
+	if ref.name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.pos))
+	}
+
+	rule := p.rules[ref.name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
+		return nil, false
+	}
+	return p.parseRule(rule)
+}
+
+func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseSeqExpr"))
+	}
+
+	vals := make([]interface{}, 0, len(seq.exprs))
+
+	pt := p.pt
+	state := p.cloneState()
+	for _, expr := range seq.exprs {
+		val, ok := p.parseExpr(expr)
+		if !ok {
+			p.restoreState(state)
+			p.restore(pt)
+			return nil, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseStateCodeExpr"))
+	}
+
+	err := state.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	return nil, true
+}
+
+func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseThrowExpr"))
+	}
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
+			if val, ok := p.parseExpr(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		start := p.pt.offset
+		p.pushV()
+		val, ok := p.parseExpr(expr.expr)
+		p.popV()
+		if !ok {
+			return vals, true
+		}
+		if p.pt.offset == start {
+			// matched without consuming any input, repeating would never
+			// end. Keep the value only if it is the first match.
+			if len(vals) == 0 {
+				vals = append(vals, val)
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrOneExpr"))
+	}
+
+	p.pushV()
+	val, _ := p.parseExpr(expr.expr)
+	p.popV()
+	// whether it matched or not, consider it a match
+	return val, true
+}
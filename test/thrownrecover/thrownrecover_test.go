@@ -1,99 +1,103 @@
 package thrownrecover
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
+
+	native "github.com/mna/pigeon/test/thrownrecover/native"
 )
 
-func TestThrowAndRecover(t *testing.T) {
-	cases := []struct {
-		input    string
-		captures interface{}
-		errors   []string
-	}{
-		// Case 01: Recover multiple labels
-		{
-			input:    "case01:123",
-			captures: "123",
+var cases = []struct {
+	input    string
+	captures interface{}
+	errors   []string
+}{
+	// Case 01: Recover multiple labels
+	{
+		input:    "case01:123",
+		captures: "123",
+	},
+	{
+		input:    "case01:1a3",
+		captures: "1?3",
+		errors: []string{
+			"1:9 (8): rule ErrNonNumber: expecting a number",
 		},
-		{
-			input:    "case01:1a3",
-			captures: "1?3",
-			errors: []string{
-				"1:9 (8): rule ErrNonNumber: expecting a number",
-			},
-		},
-		{
-			input:    "case01:11+3",
-			captures: "11?3",
-			errors: []string{
-				"1:10 (9): rule ErrNonNumber: expecting a number",
-			},
+	},
+	{
+		input:    "case01:11+3",
+		captures: "11?3",
+		errors: []string{
+			"1:10 (9): rule ErrNonNumber: expecting a number",
 		},
+	},
 
-		// Case 02: Throw a undefined label
-		{
-			input:    "case02:",
-			captures: nil,
-			errors: []string{
-				"1:8 (7): rule case02: Throwed undefined label",
-			},
+	// Case 02: Throw a undefined label
+	{
+		input:    "case02:",
+		captures: nil,
+		errors: []string{
+			"1:8 (7): rule case02: Throwed undefined label",
 		},
+	},
 
-		// Case 03: Nested Recover
-		{
-			input:    "case03:123",
-			captures: "123",
-		},
-		{
-			input:    "case03:1a3",
-			captures: "1<3",
-			errors: []string{
-				"1:9 (8): rule ErrAlphaInner03: expecting a number, got lower case char",
-			},
+	// Case 03: Nested Recover
+	{
+		input:    "case03:123",
+		captures: "123",
+	},
+	{
+		input:    "case03:1a3",
+		captures: "1<3",
+		errors: []string{
+			"1:9 (8): rule ErrAlphaInner03: expecting a number, got lower case char",
 		},
-		{
-			input:    "case03:11A3",
-			captures: "11>3",
-			errors: []string{
-				"1:10 (9): rule ErrAlphaOuter03: expecting a number, got upper case char",
-			},
+	},
+	{
+		input:    "case03:11A3",
+		captures: "11>3",
+		errors: []string{
+			"1:10 (9): rule ErrAlphaOuter03: expecting a number, got upper case char",
 		},
-		{
-			input:    "case03:111+3",
-			captures: "111?3",
-			errors: []string{
-				"1:11 (10): rule ErrOtherOuter03: expecting a number, got a non-char",
-			},
+	},
+	{
+		input:    "case03:111+3",
+		captures: "111?3",
+		errors: []string{
+			"1:11 (10): rule ErrOtherOuter03: expecting a number, got a non-char",
 		},
+	},
 
-		// Case 04: Nested Recover, which fails in inner recover
-		{
-			input:    "case04:123",
-			captures: "123",
+	// Case 04: Nested Recover, which fails in inner recover
+	{
+		input:    "case04:123",
+		captures: "123",
+	},
+	{
+		input:    "case04:1a3",
+		captures: "1x3",
+		errors: []string{
+			"1:9 (8): rule ErrAlphaOuter04: expecting a number, got a char",
 		},
-		{
-			input:    "case04:1a3",
-			captures: "1x3",
-			errors: []string{
-				"1:9 (8): rule ErrAlphaOuter04: expecting a number, got a char",
-			},
+	},
+	{
+		input:    "case04:11A3",
+		captures: "11x3",
+		errors: []string{
+			"1:10 (9): rule ErrAlphaOuter04: expecting a number, got a char",
 		},
-		{
-			input:    "case04:11A3",
-			captures: "11x3",
-			errors: []string{
-				"1:10 (9): rule ErrAlphaOuter04: expecting a number, got a char",
-			},
+	},
+	{
+		input:    "case04:111+3",
+		captures: "111?3",
+		errors: []string{
+			"1:11 (10): rule ErrOtherOuter04: expecting a number, got a non-char",
 		},
-		{
-			input:    "case04:111+3",
-			captures: "111?3",
-			errors: []string{
-				"1:11 (10): rule ErrOtherOuter04: expecting a number, got a non-char",
-			},
-		},
-	}
+	},
+}
+
+func TestThrowAndRecover(t *testing.T) {
 	for _, test := range cases {
 		got, err := Parse("", []byte(test.input))
 		if test.errors == nil && err != nil {
@@ -128,3 +132,17 @@ func TestThrowAndRecover(t *testing.T) {
 		}
 	}
 }
+
+func TestThrowAndRecoverNative(t *testing.T) {
+	for _, test := range cases {
+		in := test.input
+		want, wantErr := Parse("", []byte(in))
+		got, gotErr := native.Parse("", []byte(in))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %#v, got %#v", in, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%q: want error %v, got %v", in, wantErr, gotErr)
+		}
+	}
+}
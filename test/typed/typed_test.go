@@ -0,0 +1,116 @@
+package typed
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	native "github.com/mna/pigeon/test/typed/native"
+	optimizedgrammar "github.com/mna/pigeon/test/typed/optimized-grammar"
+)
+
+func TestTyped(t *testing.T) {
+	cases := []struct {
+		input string
+		want  int
+	}{
+		{"1", 1},
+		{"1+2", 3},
+		{"2*3+4", 10},
+		{"2+3*4", 14},
+		{"2 * (3 + 1)", 8},
+	}
+
+	parsers := map[string]func(string) (int, error){
+		"standard": func(s string) (int, error) {
+			return ParseTyped("", []byte(s))
+		},
+		"optimized-grammar": func(s string) (int, error) {
+			return optimizedgrammar.ParseTyped("", []byte(s))
+		},
+	}
+	for name, parse := range parsers {
+		for _, c := range cases {
+			got, err := parse(c.input)
+			if err != nil {
+				t.Errorf("%s: %q: want no error, got %v", name, c.input, err)
+				continue
+			}
+			if got != c.want {
+				t.Errorf("%s: %q: want %d, got %d", name, c.input, c.want, got)
+			}
+		}
+	}
+}
+
+func TestTypedEntrypoint(t *testing.T) {
+	got, err := Parse("", []byte("a, bc,d"), Entrypoint("Idents"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "bc", "d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// the typed entry point returns an error if the result is not of the
+	// type of the first rule.
+	if _, err := ParseTyped("file", []byte("a"), Entrypoint("Idents")); err == nil {
+		t.Errorf("want error, got none")
+	} else if want := "file: result is []string, not int"; err.Error() != want {
+		t.Errorf("want error %q, got %q", want, err)
+	}
+
+	if _, err := ParseTyped("file", []byte("1+")); err == nil {
+		t.Errorf("want error, got none")
+	}
+}
+
+func TestTypedRecover(t *testing.T) {
+	cases := []struct {
+		input string
+		want  int
+	}{
+		{"5", 5},
+		{"x", -1},
+		{"xxx", -3},
+	}
+	for _, c := range cases {
+		for _, recover := range []bool{true, false} {
+			got, err := ParseTyped("", []byte(c.input), Entrypoint("Recover"), Recover(recover))
+			if err != nil {
+				t.Errorf("%q: recover %t: want no error, got %v", c.input, recover, err)
+				continue
+			}
+			if got != c.want {
+				t.Errorf("%q: recover %t: want %d, got %d", c.input, recover, c.want, got)
+			}
+		}
+	}
+}
+
+// TestTypedNative compares the results and errors of the native functions
+// parser with those of the standard parser, from each entrypoint.
+func TestTypedNative(t *testing.T) {
+	cases := []struct {
+		input, entrypoint string
+	}{
+		{"2 * (3 + 1)", ""},
+		{"1+", ""},
+		{"a, bc,d", "Idents"},
+		{"a,", "Idents"},
+		{"5", "Recover"},
+		{"xx", "Recover"},
+		{"y", "Recover"},
+	}
+	for _, c := range cases {
+		want, wantErr := Parse("", []byte(c.input), Entrypoint(c.entrypoint))
+		got, gotErr := native.Parse("", []byte(c.input), native.Entrypoint(c.entrypoint))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: %q: want %#v, got %#v", c.entrypoint, c.input, want, got)
+		}
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%s: %q: want error %v, got %v", c.entrypoint, c.input, wantErr, gotErr)
+		}
+	}
+}